@@ -0,0 +1,2948 @@
+// Service definition for running the TMS engine over gRPC (see cmd/grpcserver).
+//
+// The messages mirror the JSON contract in README.md field for field, under the same
+// names, so that a document reads the same either way. Where the JSON takes one of two
+// shapes, the message has a field for each: route_ref for a route given as the ID of a
+// route library entry, and Vehicle.ref for a vehicle given as the name of a vehicle
+// library entry. Kinematics and dwell models are open to registration by name, so their
+// parameters are carried as a Struct holding the JSON object, "model" key and all.
+//
+// Regenerate the Go bindings after changing this file with
+//
+//   protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. api/tms/v1/tms.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: api/tms/v1/tms.proto
+
+package tmsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SimulationInput is the input to a run.
+type SimulationInput struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SimulationMeta *SimulationMeta        `protobuf:"bytes,1,opt,name=simulation_meta,json=simulationMeta,proto3" json:"simulation_meta,omitempty"`
+	GraphData      *GraphData             `protobuf:"bytes,2,opt,name=graph_data,json=graphData,proto3" json:"graph_data,omitempty"`
+	ServiceList    []*Service             `protobuf:"bytes,3,rep,name=service_list,json=serviceList,proto3" json:"service_list,omitempty"`
+	Events         []*SimEvent            `protobuf:"bytes,4,rep,name=events,proto3" json:"events,omitempty"`
+	Tsrs           []*TSR                 `protobuf:"bytes,5,rep,name=tsrs,proto3" json:"tsrs,omitempty"`
+	VehicleLibrary map[string]*Vehicle    `protobuf:"bytes,6,rep,name=vehicle_library,json=vehicleLibrary,proto3" json:"vehicle_library,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	RouteLibrary   map[string]*Route      `protobuf:"bytes,7,rep,name=route_library,json=routeLibrary,proto3" json:"route_library,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Frequencies    []*FrequencySpec       `protobuf:"bytes,8,rep,name=frequencies,proto3" json:"frequencies,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SimulationInput) Reset() {
+	*x = SimulationInput{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimulationInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimulationInput) ProtoMessage() {}
+
+func (x *SimulationInput) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimulationInput.ProtoReflect.Descriptor instead.
+func (*SimulationInput) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SimulationInput) GetSimulationMeta() *SimulationMeta {
+	if x != nil {
+		return x.SimulationMeta
+	}
+	return nil
+}
+
+func (x *SimulationInput) GetGraphData() *GraphData {
+	if x != nil {
+		return x.GraphData
+	}
+	return nil
+}
+
+func (x *SimulationInput) GetServiceList() []*Service {
+	if x != nil {
+		return x.ServiceList
+	}
+	return nil
+}
+
+func (x *SimulationInput) GetEvents() []*SimEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *SimulationInput) GetTsrs() []*TSR {
+	if x != nil {
+		return x.Tsrs
+	}
+	return nil
+}
+
+func (x *SimulationInput) GetVehicleLibrary() map[string]*Vehicle {
+	if x != nil {
+		return x.VehicleLibrary
+	}
+	return nil
+}
+
+func (x *SimulationInput) GetRouteLibrary() map[string]*Route {
+	if x != nil {
+		return x.RouteLibrary
+	}
+	return nil
+}
+
+func (x *SimulationInput) GetFrequencies() []*FrequencySpec {
+	if x != nil {
+		return x.Frequencies
+	}
+	return nil
+}
+
+type SimulationMeta struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	SimulationId         string                 `protobuf:"bytes,1,opt,name=simulation_id,json=simulationId,proto3" json:"simulation_id,omitempty"`
+	RunTime              float64                `protobuf:"fixed64,2,opt,name=run_time,json=runTime,proto3" json:"run_time,omitempty"`    // seconds
+	TimeStep             float64                `protobuf:"fixed64,3,opt,name=time_step,json=timeStep,proto3" json:"time_step,omitempty"` // seconds
+	Adhesion             *float64               `protobuf:"fixed64,4,opt,name=adhesion,proto3,oneof" json:"adhesion,omitempty"`
+	MinHeadway           float64                `protobuf:"fixed64,5,opt,name=min_headway,json=minHeadway,proto3" json:"min_headway,omitempty"`       // seconds
+	SafetyMargin         float64                `protobuf:"fixed64,6,opt,name=safety_margin,json=safetyMargin,proto3" json:"safety_margin,omitempty"` // metres
+	SignallingMode       string                 `protobuf:"bytes,7,opt,name=signalling_mode,json=signallingMode,proto3" json:"signalling_mode,omitempty"`
+	BlockLength          float64                `protobuf:"fixed64,8,opt,name=block_length,json=blockLength,proto3" json:"block_length,omitempty"` // metres
+	Congestion           bool                   `protobuf:"varint,9,opt,name=congestion,proto3" json:"congestion,omitempty"`
+	ConflictHorizon      float64                `protobuf:"fixed64,10,opt,name=conflict_horizon,json=conflictHorizon,proto3" json:"conflict_horizon,omitempty"` // metres
+	Regulation           bool                   `protobuf:"varint,11,opt,name=regulation,proto3" json:"regulation,omitempty"`
+	RegulationThreshold  float64                `protobuf:"fixed64,12,opt,name=regulation_threshold,json=regulationThreshold,proto3" json:"regulation_threshold,omitempty"` // seconds
+	ReportSeparations    bool                   `protobuf:"varint,13,opt,name=report_separations,json=reportSeparations,proto3" json:"report_separations,omitempty"`
+	MinSeparation        float64                `protobuf:"fixed64,14,opt,name=min_separation,json=minSeparation,proto3" json:"min_separation,omitempty"` // metres
+	SubSteps             int32                  `protobuf:"varint,15,opt,name=sub_steps,json=subSteps,proto3" json:"sub_steps,omitempty"`
+	LogMode              string                 `protobuf:"bytes,16,opt,name=log_mode,json=logMode,proto3" json:"log_mode,omitempty"`
+	LogVelocityThreshold float64                `protobuf:"fixed64,17,opt,name=log_velocity_threshold,json=logVelocityThreshold,proto3" json:"log_velocity_threshold,omitempty"`
+	LogInterval          float64                `protobuf:"fixed64,18,opt,name=log_interval,json=logInterval,proto3" json:"log_interval,omitempty"` // seconds
+	OutputPrecision      *int32                 `protobuf:"varint,19,opt,name=output_precision,json=outputPrecision,proto3,oneof" json:"output_precision,omitempty"`
+	LogCoordinates       bool                   `protobuf:"varint,20,opt,name=log_coordinates,json=logCoordinates,proto3" json:"log_coordinates,omitempty"`
+	SpeedUnit            string                 `protobuf:"bytes,21,opt,name=speed_unit,json=speedUnit,proto3" json:"speed_unit,omitempty"`
+	Seed                 int64                  `protobuf:"varint,22,opt,name=seed,proto3" json:"seed,omitempty"`
+	DwellJitter          *Jitter                `protobuf:"bytes,23,opt,name=dwell_jitter,json=dwellJitter,proto3" json:"dwell_jitter,omitempty"`
+	DepartureJitter      *Jitter                `protobuf:"bytes,24,opt,name=departure_jitter,json=departureJitter,proto3" json:"departure_jitter,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *SimulationMeta) Reset() {
+	*x = SimulationMeta{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimulationMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimulationMeta) ProtoMessage() {}
+
+func (x *SimulationMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimulationMeta.ProtoReflect.Descriptor instead.
+func (*SimulationMeta) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SimulationMeta) GetSimulationId() string {
+	if x != nil {
+		return x.SimulationId
+	}
+	return ""
+}
+
+func (x *SimulationMeta) GetRunTime() float64 {
+	if x != nil {
+		return x.RunTime
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetTimeStep() float64 {
+	if x != nil {
+		return x.TimeStep
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetAdhesion() float64 {
+	if x != nil && x.Adhesion != nil {
+		return *x.Adhesion
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetMinHeadway() float64 {
+	if x != nil {
+		return x.MinHeadway
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetSafetyMargin() float64 {
+	if x != nil {
+		return x.SafetyMargin
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetSignallingMode() string {
+	if x != nil {
+		return x.SignallingMode
+	}
+	return ""
+}
+
+func (x *SimulationMeta) GetBlockLength() float64 {
+	if x != nil {
+		return x.BlockLength
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetCongestion() bool {
+	if x != nil {
+		return x.Congestion
+	}
+	return false
+}
+
+func (x *SimulationMeta) GetConflictHorizon() float64 {
+	if x != nil {
+		return x.ConflictHorizon
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetRegulation() bool {
+	if x != nil {
+		return x.Regulation
+	}
+	return false
+}
+
+func (x *SimulationMeta) GetRegulationThreshold() float64 {
+	if x != nil {
+		return x.RegulationThreshold
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetReportSeparations() bool {
+	if x != nil {
+		return x.ReportSeparations
+	}
+	return false
+}
+
+func (x *SimulationMeta) GetMinSeparation() float64 {
+	if x != nil {
+		return x.MinSeparation
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetSubSteps() int32 {
+	if x != nil {
+		return x.SubSteps
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetLogMode() string {
+	if x != nil {
+		return x.LogMode
+	}
+	return ""
+}
+
+func (x *SimulationMeta) GetLogVelocityThreshold() float64 {
+	if x != nil {
+		return x.LogVelocityThreshold
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetLogInterval() float64 {
+	if x != nil {
+		return x.LogInterval
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetOutputPrecision() int32 {
+	if x != nil && x.OutputPrecision != nil {
+		return *x.OutputPrecision
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetLogCoordinates() bool {
+	if x != nil {
+		return x.LogCoordinates
+	}
+	return false
+}
+
+func (x *SimulationMeta) GetSpeedUnit() string {
+	if x != nil {
+		return x.SpeedUnit
+	}
+	return ""
+}
+
+func (x *SimulationMeta) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *SimulationMeta) GetDwellJitter() *Jitter {
+	if x != nil {
+		return x.DwellJitter
+	}
+	return nil
+}
+
+func (x *SimulationMeta) GetDepartureJitter() *Jitter {
+	if x != nil {
+		return x.DepartureJitter
+	}
+	return nil
+}
+
+type Jitter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Distribution  string                 `protobuf:"bytes,1,opt,name=distribution,proto3" json:"distribution,omitempty"`
+	Spread        float64                `protobuf:"fixed64,2,opt,name=spread,proto3" json:"spread,omitempty"` // seconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Jitter) Reset() {
+	*x = Jitter{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Jitter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Jitter) ProtoMessage() {}
+
+func (x *Jitter) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Jitter.ProtoReflect.Descriptor instead.
+func (*Jitter) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Jitter) GetDistribution() string {
+	if x != nil {
+		return x.Distribution
+	}
+	return ""
+}
+
+func (x *Jitter) GetSpread() float64 {
+	if x != nil {
+		return x.Spread
+	}
+	return 0
+}
+
+type GraphData struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Nodes           []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Edges           []*Edge                `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`
+	Blocks          []*Block               `protobuf:"bytes,3,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	MaxLateralAccel float64                `protobuf:"fixed64,4,opt,name=max_lateral_accel,json=maxLateralAccel,proto3" json:"max_lateral_accel,omitempty"` // m/s²
+	LengthUnit      string                 `protobuf:"bytes,5,opt,name=length_unit,json=lengthUnit,proto3" json:"length_unit,omitempty"`
+	MinLengthRatio  float64                `protobuf:"fixed64,6,opt,name=min_length_ratio,json=minLengthRatio,proto3" json:"min_length_ratio,omitempty"`
+	AutoLengths     bool                   `protobuf:"varint,7,opt,name=auto_lengths,json=autoLengths,proto3" json:"auto_lengths,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GraphData) Reset() {
+	*x = GraphData{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GraphData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GraphData) ProtoMessage() {}
+
+func (x *GraphData) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GraphData.ProtoReflect.Descriptor instead.
+func (*GraphData) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GraphData) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *GraphData) GetEdges() []*Edge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+func (x *GraphData) GetBlocks() []*Block {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *GraphData) GetMaxLateralAccel() float64 {
+	if x != nil {
+		return x.MaxLateralAccel
+	}
+	return 0
+}
+
+func (x *GraphData) GetLengthUnit() string {
+	if x != nil {
+		return x.LengthUnit
+	}
+	return ""
+}
+
+func (x *GraphData) GetMinLengthRatio() float64 {
+	if x != nil {
+		return x.MinLengthRatio
+	}
+	return 0
+}
+
+func (x *GraphData) GetAutoLengths() bool {
+	if x != nil {
+		return x.AutoLengths
+	}
+	return false
+}
+
+type Coordinate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             float64                `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"` // metres
+	Y             float64                `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"` // metres
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Coordinate) Reset() {
+	*x = Coordinate{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Coordinate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Coordinate) ProtoMessage() {}
+
+func (x *Coordinate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Coordinate.ProtoReflect.Descriptor instead.
+func (*Coordinate) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Coordinate) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Coordinate) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+type Node struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Loc           *Coordinate            `protobuf:"bytes,2,opt,name=loc,proto3" json:"loc,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Junction      bool                   `protobuf:"varint,4,opt,name=junction,proto3" json:"junction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Node) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *Node) GetLoc() *Coordinate {
+	if x != nil {
+		return x.Loc
+	}
+	return nil
+}
+
+func (x *Node) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Node) GetJunction() bool {
+	if x != nil {
+		return x.Junction
+	}
+	return false
+}
+
+type Edge struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EdgeId        string                 `protobuf:"bytes,1,opt,name=edge_id,json=edgeId,proto3" json:"edge_id,omitempty"`
+	U             string                 `protobuf:"bytes,2,opt,name=u,proto3" json:"u,omitempty"`
+	V             string                 `protobuf:"bytes,3,opt,name=v,proto3" json:"v,omitempty"`
+	Length        float64                `protobuf:"fixed64,4,opt,name=length,proto3" json:"length,omitempty"` // metres
+	SpeedLimit    *float64               `protobuf:"fixed64,5,opt,name=speed_limit,json=speedLimit,proto3,oneof" json:"speed_limit,omitempty"`
+	Gradient      float64                `protobuf:"fixed64,6,opt,name=gradient,proto3" json:"gradient,omitempty"` // per mille
+	Adhesion      *float64               `protobuf:"fixed64,7,opt,name=adhesion,proto3,oneof" json:"adhesion,omitempty"`
+	Sections      int32                  `protobuf:"varint,8,opt,name=sections,proto3" json:"sections,omitempty"`
+	Capacity      int32                  `protobuf:"varint,9,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	Bidirectional bool                   `protobuf:"varint,10,opt,name=bidirectional,proto3" json:"bidirectional,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Edge) Reset() {
+	*x = Edge{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Edge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Edge) ProtoMessage() {}
+
+func (x *Edge) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Edge.ProtoReflect.Descriptor instead.
+func (*Edge) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Edge) GetEdgeId() string {
+	if x != nil {
+		return x.EdgeId
+	}
+	return ""
+}
+
+func (x *Edge) GetU() string {
+	if x != nil {
+		return x.U
+	}
+	return ""
+}
+
+func (x *Edge) GetV() string {
+	if x != nil {
+		return x.V
+	}
+	return ""
+}
+
+func (x *Edge) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *Edge) GetSpeedLimit() float64 {
+	if x != nil && x.SpeedLimit != nil {
+		return *x.SpeedLimit
+	}
+	return 0
+}
+
+func (x *Edge) GetGradient() float64 {
+	if x != nil {
+		return x.Gradient
+	}
+	return 0
+}
+
+func (x *Edge) GetAdhesion() float64 {
+	if x != nil && x.Adhesion != nil {
+		return *x.Adhesion
+	}
+	return 0
+}
+
+func (x *Edge) GetSections() int32 {
+	if x != nil {
+		return x.Sections
+	}
+	return 0
+}
+
+func (x *Edge) GetCapacity() int32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *Edge) GetBidirectional() bool {
+	if x != nil {
+		return x.Bidirectional
+	}
+	return false
+}
+
+type Block struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlockId       string                 `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	Edges         []string               `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Block) Reset() {
+	*x = Block{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Block) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Block) ProtoMessage() {}
+
+func (x *Block) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Block.ProtoReflect.Descriptor instead.
+func (*Block) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Block) GetBlockId() string {
+	if x != nil {
+		return x.BlockId
+	}
+	return ""
+}
+
+func (x *Block) GetEdges() []string {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+type Position struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Edge              string                 `protobuf:"bytes,1,opt,name=edge,proto3" json:"edge,omitempty"`
+	DistanceAlongEdge float64                `protobuf:"fixed64,2,opt,name=distance_along_edge,json=distanceAlongEdge,proto3" json:"distance_along_edge,omitempty"` // metres
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Position) GetEdge() string {
+	if x != nil {
+		return x.Edge
+	}
+	return ""
+}
+
+func (x *Position) GetDistanceAlongEdge() float64 {
+	if x != nil {
+		return x.DistanceAlongEdge
+	}
+	return 0
+}
+
+type Segment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Edge          string                 `protobuf:"bytes,1,opt,name=edge,proto3" json:"edge,omitempty"`
+	Start         float64                `protobuf:"fixed64,2,opt,name=start,proto3" json:"start,omitempty"` // metres along edge
+	End           float64                `protobuf:"fixed64,3,opt,name=end,proto3" json:"end,omitempty"`     // metres along edge
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Segment) Reset() {
+	*x = Segment{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Segment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Segment) ProtoMessage() {}
+
+func (x *Segment) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Segment.ProtoReflect.Descriptor instead.
+func (*Segment) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Segment) GetEdge() string {
+	if x != nil {
+		return x.Edge
+	}
+	return ""
+}
+
+func (x *Segment) GetStart() float64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Segment) GetEnd() float64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type Vehicle struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ref names a vehicle_library entry, in place of the other fields.
+	Ref              string  `protobuf:"bytes,1,opt,name=ref,proto3" json:"ref,omitempty"`
+	Name             string  `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Length           float64 `protobuf:"fixed64,3,opt,name=length,proto3" json:"length,omitempty"`                                               // metres
+	MassEmpty        float64 `protobuf:"fixed64,4,opt,name=mass_empty,json=massEmpty,proto3" json:"mass_empty,omitempty"`                        // kg
+	MassPerPassenger float64 `protobuf:"fixed64,5,opt,name=mass_per_passenger,json=massPerPassenger,proto3" json:"mass_per_passenger,omitempty"` // kg
+	ReactionTime     float64 `protobuf:"fixed64,6,opt,name=reaction_time,json=reactionTime,proto3" json:"reaction_time,omitempty"`               // seconds
+	CoastSpeed       float64 `protobuf:"fixed64,7,opt,name=coast_speed,json=coastSpeed,proto3" json:"coast_speed,omitempty"`
+	ResumeSpeed      float64 `protobuf:"fixed64,8,opt,name=resume_speed,json=resumeSpeed,proto3" json:"resume_speed,omitempty"`
+	CreepSpeed       float64 `protobuf:"fixed64,9,opt,name=creep_speed,json=creepSpeed,proto3" json:"creep_speed,omitempty"`
+	CreepDistance    float64 `protobuf:"fixed64,10,opt,name=creep_distance,json=creepDistance,proto3" json:"creep_distance,omitempty"` // metres
+	// kinematics is the kinematics model, named by its "model" key.
+	Kinematics    *structpb.Struct `protobuf:"bytes,11,opt,name=kinematics,proto3" json:"kinematics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Vehicle) Reset() {
+	*x = Vehicle{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Vehicle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vehicle) ProtoMessage() {}
+
+func (x *Vehicle) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vehicle.ProtoReflect.Descriptor instead.
+func (*Vehicle) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Vehicle) GetRef() string {
+	if x != nil {
+		return x.Ref
+	}
+	return ""
+}
+
+func (x *Vehicle) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Vehicle) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *Vehicle) GetMassEmpty() float64 {
+	if x != nil {
+		return x.MassEmpty
+	}
+	return 0
+}
+
+func (x *Vehicle) GetMassPerPassenger() float64 {
+	if x != nil {
+		return x.MassPerPassenger
+	}
+	return 0
+}
+
+func (x *Vehicle) GetReactionTime() float64 {
+	if x != nil {
+		return x.ReactionTime
+	}
+	return 0
+}
+
+func (x *Vehicle) GetCoastSpeed() float64 {
+	if x != nil {
+		return x.CoastSpeed
+	}
+	return 0
+}
+
+func (x *Vehicle) GetResumeSpeed() float64 {
+	if x != nil {
+		return x.ResumeSpeed
+	}
+	return 0
+}
+
+func (x *Vehicle) GetCreepSpeed() float64 {
+	if x != nil {
+		return x.CreepSpeed
+	}
+	return 0
+}
+
+func (x *Vehicle) GetCreepDistance() float64 {
+	if x != nil {
+		return x.CreepDistance
+	}
+	return 0
+}
+
+func (x *Vehicle) GetKinematics() *structpb.Struct {
+	if x != nil {
+		return x.Kinematics
+	}
+	return nil
+}
+
+type UnitRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Unit          *Vehicle               `protobuf:"bytes,1,opt,name=unit,proto3" json:"unit,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnitRef) Reset() {
+	*x = UnitRef{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnitRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnitRef) ProtoMessage() {}
+
+func (x *UnitRef) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnitRef.ProtoReflect.Descriptor instead.
+func (*UnitRef) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UnitRef) GetUnit() *Vehicle {
+	if x != nil {
+		return x.Unit
+	}
+	return nil
+}
+
+func (x *UnitRef) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type Service struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ServiceId       string                 `protobuf:"bytes,1,opt,name=service_id,json=serviceId,proto3" json:"service_id,omitempty"`
+	InitialPosition string                 `protobuf:"bytes,2,opt,name=initial_position,json=initialPosition,proto3" json:"initial_position,omitempty"`
+	StartPosition   *Position              `protobuf:"bytes,3,opt,name=start_position,json=startPosition,proto3" json:"start_position,omitempty"`
+	Route           []*RouteStop           `protobuf:"bytes,4,rep,name=route,proto3" json:"route,omitempty"`
+	// route_ref names a route_library entry, in place of route.
+	RouteRef          string     `protobuf:"bytes,5,opt,name=route_ref,json=routeRef,proto3" json:"route_ref,omitempty"`
+	Vehicle           *Vehicle   `protobuf:"bytes,6,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+	Units             []*UnitRef `protobuf:"bytes,7,rep,name=units,proto3" json:"units,omitempty"`
+	DepartureDelay    float64    `protobuf:"fixed64,8,opt,name=departure_delay,json=departureDelay,proto3" json:"departure_delay,omitempty"` // seconds
+	InitialPassengers int32      `protobuf:"varint,9,opt,name=initial_passengers,json=initialPassengers,proto3" json:"initial_passengers,omitempty"`
+	Priority          int32      `protobuf:"varint,10,opt,name=priority,proto3" json:"priority,omitempty"`
+	Loop              bool       `protobuf:"varint,11,opt,name=loop,proto3" json:"loop,omitempty"`
+	Shuttle           bool       `protobuf:"varint,12,opt,name=shuttle,proto3" json:"shuttle,omitempty"`
+	MaxSpeed          float64    `protobuf:"fixed64,13,opt,name=max_speed,json=maxSpeed,proto3" json:"max_speed,omitempty"`
+	// dwell_model is the dwell model, named by its "model" key.
+	DwellModel      *structpb.Struct `protobuf:"bytes,14,opt,name=dwell_model,json=dwellModel,proto3" json:"dwell_model,omitempty"`
+	InitialVelocity float64          `protobuf:"fixed64,15,opt,name=initial_velocity,json=initialVelocity,proto3" json:"initial_velocity,omitempty"`
+	InitialState    string           `protobuf:"bytes,16,opt,name=initial_state,json=initialState,proto3" json:"initial_state,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Service) Reset() {
+	*x = Service{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Service) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Service) ProtoMessage() {}
+
+func (x *Service) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Service.ProtoReflect.Descriptor instead.
+func (*Service) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Service) GetServiceId() string {
+	if x != nil {
+		return x.ServiceId
+	}
+	return ""
+}
+
+func (x *Service) GetInitialPosition() string {
+	if x != nil {
+		return x.InitialPosition
+	}
+	return ""
+}
+
+func (x *Service) GetStartPosition() *Position {
+	if x != nil {
+		return x.StartPosition
+	}
+	return nil
+}
+
+func (x *Service) GetRoute() []*RouteStop {
+	if x != nil {
+		return x.Route
+	}
+	return nil
+}
+
+func (x *Service) GetRouteRef() string {
+	if x != nil {
+		return x.RouteRef
+	}
+	return ""
+}
+
+func (x *Service) GetVehicle() *Vehicle {
+	if x != nil {
+		return x.Vehicle
+	}
+	return nil
+}
+
+func (x *Service) GetUnits() []*UnitRef {
+	if x != nil {
+		return x.Units
+	}
+	return nil
+}
+
+func (x *Service) GetDepartureDelay() float64 {
+	if x != nil {
+		return x.DepartureDelay
+	}
+	return 0
+}
+
+func (x *Service) GetInitialPassengers() int32 {
+	if x != nil {
+		return x.InitialPassengers
+	}
+	return 0
+}
+
+func (x *Service) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Service) GetLoop() bool {
+	if x != nil {
+		return x.Loop
+	}
+	return false
+}
+
+func (x *Service) GetShuttle() bool {
+	if x != nil {
+		return x.Shuttle
+	}
+	return false
+}
+
+func (x *Service) GetMaxSpeed() float64 {
+	if x != nil {
+		return x.MaxSpeed
+	}
+	return 0
+}
+
+func (x *Service) GetDwellModel() *structpb.Struct {
+	if x != nil {
+		return x.DwellModel
+	}
+	return nil
+}
+
+func (x *Service) GetInitialVelocity() float64 {
+	if x != nil {
+		return x.InitialVelocity
+	}
+	return 0
+}
+
+func (x *Service) GetInitialState() string {
+	if x != nil {
+		return x.InitialState
+	}
+	return ""
+}
+
+type RouteStop struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	NodeId             string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	MinDwell           float64                `protobuf:"fixed64,2,opt,name=min_dwell,json=minDwell,proto3" json:"min_dwell,omitempty"`                                     // seconds
+	ScheduledArrival   *float64               `protobuf:"fixed64,3,opt,name=scheduled_arrival,json=scheduledArrival,proto3,oneof" json:"scheduled_arrival,omitempty"`       // seconds
+	ScheduledDeparture *float64               `protobuf:"fixed64,4,opt,name=scheduled_departure,json=scheduledDeparture,proto3,oneof" json:"scheduled_departure,omitempty"` // seconds
+	Boarders           int32                  `protobuf:"varint,5,opt,name=boarders,proto3" json:"boarders,omitempty"`
+	Alighters          int32                  `protobuf:"varint,6,opt,name=alighters,proto3" json:"alighters,omitempty"`
+	DoorFlowRate       float64                `protobuf:"fixed64,7,opt,name=door_flow_rate,json=doorFlowRate,proto3" json:"door_flow_rate,omitempty"` // passengers per second
+	Skip               bool                   `protobuf:"varint,8,opt,name=skip,proto3" json:"skip,omitempty"`
+	RequestStop        bool                   `protobuf:"varint,9,opt,name=request_stop,json=requestStop,proto3" json:"request_stop,omitempty"`
+	Couple             string                 `protobuf:"bytes,10,opt,name=couple,proto3" json:"couple,omitempty"`
+	Join               string                 `protobuf:"bytes,11,opt,name=join,proto3" json:"join,omitempty"`
+	Divide             *Division              `protobuf:"bytes,12,opt,name=divide,proto3" json:"divide,omitempty"`
+	Reverse            bool                   `protobuf:"varint,13,opt,name=reverse,proto3" json:"reverse,omitempty"`
+	SetBack            float64                `protobuf:"fixed64,14,opt,name=set_back,json=setBack,proto3" json:"set_back,omitempty"` // metres
+	DwellModel         *structpb.Struct       `protobuf:"bytes,15,opt,name=dwell_model,json=dwellModel,proto3" json:"dwell_model,omitempty"`
+	Via                []string               `protobuf:"bytes,16,rep,name=via,proto3" json:"via,omitempty"`
+	Track              []string               `protobuf:"bytes,17,rep,name=track,proto3" json:"track,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RouteStop) Reset() {
+	*x = RouteStop{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RouteStop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteStop) ProtoMessage() {}
+
+func (x *RouteStop) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteStop.ProtoReflect.Descriptor instead.
+func (*RouteStop) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RouteStop) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *RouteStop) GetMinDwell() float64 {
+	if x != nil {
+		return x.MinDwell
+	}
+	return 0
+}
+
+func (x *RouteStop) GetScheduledArrival() float64 {
+	if x != nil && x.ScheduledArrival != nil {
+		return *x.ScheduledArrival
+	}
+	return 0
+}
+
+func (x *RouteStop) GetScheduledDeparture() float64 {
+	if x != nil && x.ScheduledDeparture != nil {
+		return *x.ScheduledDeparture
+	}
+	return 0
+}
+
+func (x *RouteStop) GetBoarders() int32 {
+	if x != nil {
+		return x.Boarders
+	}
+	return 0
+}
+
+func (x *RouteStop) GetAlighters() int32 {
+	if x != nil {
+		return x.Alighters
+	}
+	return 0
+}
+
+func (x *RouteStop) GetDoorFlowRate() float64 {
+	if x != nil {
+		return x.DoorFlowRate
+	}
+	return 0
+}
+
+func (x *RouteStop) GetSkip() bool {
+	if x != nil {
+		return x.Skip
+	}
+	return false
+}
+
+func (x *RouteStop) GetRequestStop() bool {
+	if x != nil {
+		return x.RequestStop
+	}
+	return false
+}
+
+func (x *RouteStop) GetCouple() string {
+	if x != nil {
+		return x.Couple
+	}
+	return ""
+}
+
+func (x *RouteStop) GetJoin() string {
+	if x != nil {
+		return x.Join
+	}
+	return ""
+}
+
+func (x *RouteStop) GetDivide() *Division {
+	if x != nil {
+		return x.Divide
+	}
+	return nil
+}
+
+func (x *RouteStop) GetReverse() bool {
+	if x != nil {
+		return x.Reverse
+	}
+	return false
+}
+
+func (x *RouteStop) GetSetBack() float64 {
+	if x != nil {
+		return x.SetBack
+	}
+	return 0
+}
+
+func (x *RouteStop) GetDwellModel() *structpb.Struct {
+	if x != nil {
+		return x.DwellModel
+	}
+	return nil
+}
+
+func (x *RouteStop) GetVia() []string {
+	if x != nil {
+		return x.Via
+	}
+	return nil
+}
+
+func (x *RouteStop) GetTrack() []string {
+	if x != nil {
+		return x.Track
+	}
+	return nil
+}
+
+type Division struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServiceId     string                 `protobuf:"bytes,1,opt,name=service_id,json=serviceId,proto3" json:"service_id,omitempty"`
+	Units         int32                  `protobuf:"varint,2,opt,name=units,proto3" json:"units,omitempty"`
+	Route         []*RouteStop           `protobuf:"bytes,3,rep,name=route,proto3" json:"route,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Division) Reset() {
+	*x = Division{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Division) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Division) ProtoMessage() {}
+
+func (x *Division) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Division.ProtoReflect.Descriptor instead.
+func (*Division) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Division) GetServiceId() string {
+	if x != nil {
+		return x.ServiceId
+	}
+	return ""
+}
+
+func (x *Division) GetUnits() int32 {
+	if x != nil {
+		return x.Units
+	}
+	return 0
+}
+
+func (x *Division) GetRoute() []*RouteStop {
+	if x != nil {
+		return x.Route
+	}
+	return nil
+}
+
+// Route is a route_library entry: its stops, in order.
+type Route struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stops         []*RouteStop           `protobuf:"bytes,1,rep,name=stops,proto3" json:"stops,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Route) Reset() {
+	*x = Route{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Route) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Route) ProtoMessage() {}
+
+func (x *Route) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Route.ProtoReflect.Descriptor instead.
+func (*Route) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Route) GetStops() []*RouteStop {
+	if x != nil {
+		return x.Stops
+	}
+	return nil
+}
+
+type SimEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Time          float64                `protobuf:"fixed64,2,opt,name=time,proto3" json:"time,omitempty"`         // seconds
+	Duration      float64                `protobuf:"fixed64,3,opt,name=duration,proto3" json:"duration,omitempty"` // seconds
+	ServiceId     string                 `protobuf:"bytes,4,opt,name=service_id,json=serviceId,proto3" json:"service_id,omitempty"`
+	EdgeId        string                 `protobuf:"bytes,5,opt,name=edge_id,json=edgeId,proto3" json:"edge_id,omitempty"`
+	SpeedLimit    float64                `protobuf:"fixed64,6,opt,name=speed_limit,json=speedLimit,proto3" json:"speed_limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimEvent) Reset() {
+	*x = SimEvent{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimEvent) ProtoMessage() {}
+
+func (x *SimEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimEvent.ProtoReflect.Descriptor instead.
+func (*SimEvent) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SimEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SimEvent) GetTime() float64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *SimEvent) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *SimEvent) GetServiceId() string {
+	if x != nil {
+		return x.ServiceId
+	}
+	return ""
+}
+
+func (x *SimEvent) GetEdgeId() string {
+	if x != nil {
+		return x.EdgeId
+	}
+	return ""
+}
+
+func (x *SimEvent) GetSpeedLimit() float64 {
+	if x != nil {
+		return x.SpeedLimit
+	}
+	return 0
+}
+
+type TSR struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EdgeId        string                 `protobuf:"bytes,1,opt,name=edge_id,json=edgeId,proto3" json:"edge_id,omitempty"`
+	Segment       *Segment               `protobuf:"bytes,2,opt,name=segment,proto3" json:"segment,omitempty"`
+	SpeedLimit    float64                `protobuf:"fixed64,3,opt,name=speed_limit,json=speedLimit,proto3" json:"speed_limit,omitempty"`
+	StartTime     float64                `protobuf:"fixed64,4,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // seconds
+	EndTime       float64                `protobuf:"fixed64,5,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // seconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TSR) Reset() {
+	*x = TSR{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TSR) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TSR) ProtoMessage() {}
+
+func (x *TSR) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TSR.ProtoReflect.Descriptor instead.
+func (*TSR) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TSR) GetEdgeId() string {
+	if x != nil {
+		return x.EdgeId
+	}
+	return ""
+}
+
+func (x *TSR) GetSegment() *Segment {
+	if x != nil {
+		return x.Segment
+	}
+	return nil
+}
+
+func (x *TSR) GetSpeedLimit() float64 {
+	if x != nil {
+		return x.SpeedLimit
+	}
+	return 0
+}
+
+func (x *TSR) GetStartTime() float64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *TSR) GetEndTime() float64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+type FrequencySpec struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Route           string                 `protobuf:"bytes,2,opt,name=route,proto3" json:"route,omitempty"`
+	InitialPosition string                 `protobuf:"bytes,3,opt,name=initial_position,json=initialPosition,proto3" json:"initial_position,omitempty"`
+	Vehicle         *Vehicle               `protobuf:"bytes,4,opt,name=vehicle,proto3" json:"vehicle,omitempty"`
+	Priority        int32                  `protobuf:"varint,5,opt,name=priority,proto3" json:"priority,omitempty"`
+	Start           float64                `protobuf:"fixed64,6,opt,name=start,proto3" json:"start,omitempty"`     // seconds
+	End             float64                `protobuf:"fixed64,7,opt,name=end,proto3" json:"end,omitempty"`         // seconds
+	Headway         float64                `protobuf:"fixed64,8,opt,name=headway,proto3" json:"headway,omitempty"` // seconds
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *FrequencySpec) Reset() {
+	*x = FrequencySpec{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FrequencySpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FrequencySpec) ProtoMessage() {}
+
+func (x *FrequencySpec) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FrequencySpec.ProtoReflect.Descriptor instead.
+func (*FrequencySpec) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FrequencySpec) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FrequencySpec) GetRoute() string {
+	if x != nil {
+		return x.Route
+	}
+	return ""
+}
+
+func (x *FrequencySpec) GetInitialPosition() string {
+	if x != nil {
+		return x.InitialPosition
+	}
+	return ""
+}
+
+func (x *FrequencySpec) GetVehicle() *Vehicle {
+	if x != nil {
+		return x.Vehicle
+	}
+	return nil
+}
+
+func (x *FrequencySpec) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *FrequencySpec) GetStart() float64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *FrequencySpec) GetEnd() float64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *FrequencySpec) GetHeadway() float64 {
+	if x != nil {
+		return x.Headway
+	}
+	return 0
+}
+
+// SimulationLog is the complete output of a run.
+type SimulationLog struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SimulationMeta *SimulationMeta        `protobuf:"bytes,1,opt,name=simulation_meta,json=simulationMeta,proto3" json:"simulation_meta,omitempty"`
+	Output         []*SimulationLogRow    `protobuf:"bytes,2,rep,name=output,proto3" json:"output,omitempty"`
+	TractionEnergy float64                `protobuf:"fixed64,3,opt,name=traction_energy,json=tractionEnergy,proto3" json:"traction_energy,omitempty"` // J
+	RegenEnergy    float64                `protobuf:"fixed64,4,opt,name=regen_energy,json=regenEnergy,proto3" json:"regen_energy,omitempty"`          // J
+	Summary        *SimulationSummary     `protobuf:"bytes,5,opt,name=summary,proto3" json:"summary,omitempty"`
+	Journeys       []*Journey             `protobuf:"bytes,6,rep,name=journeys,proto3" json:"journeys,omitempty"`
+	Warnings       []string               `protobuf:"bytes,7,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SimulationLog) Reset() {
+	*x = SimulationLog{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimulationLog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimulationLog) ProtoMessage() {}
+
+func (x *SimulationLog) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimulationLog.ProtoReflect.Descriptor instead.
+func (*SimulationLog) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SimulationLog) GetSimulationMeta() *SimulationMeta {
+	if x != nil {
+		return x.SimulationMeta
+	}
+	return nil
+}
+
+func (x *SimulationLog) GetOutput() []*SimulationLogRow {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *SimulationLog) GetTractionEnergy() float64 {
+	if x != nil {
+		return x.TractionEnergy
+	}
+	return 0
+}
+
+func (x *SimulationLog) GetRegenEnergy() float64 {
+	if x != nil {
+		return x.RegenEnergy
+	}
+	return 0
+}
+
+func (x *SimulationLog) GetSummary() *SimulationSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *SimulationLog) GetJourneys() []*Journey {
+	if x != nil {
+		return x.Journeys
+	}
+	return nil
+}
+
+func (x *SimulationLog) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+// SimulationLogRow is the state of the services logged at one timestep.
+type SimulationLogRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     float64                `protobuf:"fixed64,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // seconds
+	ServiceLogs   []*ServiceLog          `protobuf:"bytes,2,rep,name=service_logs,json=serviceLogs,proto3" json:"service_logs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimulationLogRow) Reset() {
+	*x = SimulationLogRow{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimulationLogRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimulationLogRow) ProtoMessage() {}
+
+func (x *SimulationLogRow) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimulationLogRow.ProtoReflect.Descriptor instead.
+func (*SimulationLogRow) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SimulationLogRow) GetTimestamp() float64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *SimulationLogRow) GetServiceLogs() []*ServiceLog {
+	if x != nil {
+		return x.ServiceLogs
+	}
+	return nil
+}
+
+type ServiceLog struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ServiceId       string                 `protobuf:"bytes,1,opt,name=service_id,json=serviceId,proto3" json:"service_id,omitempty"`
+	CurrentPosition *Position              `protobuf:"bytes,2,opt,name=current_position,json=currentPosition,proto3" json:"current_position,omitempty"`
+	State           string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	Velocity        float64                `protobuf:"fixed64,4,opt,name=velocity,proto3" json:"velocity,omitempty"`                                   // negative while setting back
+	RemainingDwell  float64                `protobuf:"fixed64,5,opt,name=remaining_dwell,json=remainingDwell,proto3" json:"remaining_dwell,omitempty"` // seconds
+	NextStop        string                 `protobuf:"bytes,6,opt,name=next_stop,json=nextStop,proto3" json:"next_stop,omitempty"`
+	TractionEnergy  float64                `protobuf:"fixed64,7,opt,name=traction_energy,json=tractionEnergy,proto3" json:"traction_energy,omitempty"` // J
+	RegenEnergy     float64                `protobuf:"fixed64,8,opt,name=regen_energy,json=regenEnergy,proto3" json:"regen_energy,omitempty"`          // J
+	Odometer        float64                `protobuf:"fixed64,9,opt,name=odometer,proto3" json:"odometer,omitempty"`                                   // metres
+	Passengers      int32                  `protobuf:"varint,10,opt,name=passengers,proto3" json:"passengers,omitempty"`
+	Delay           *float64               `protobuf:"fixed64,11,opt,name=delay,proto3,oneof" json:"delay,omitempty"` // seconds
+	Reversed        bool                   `protobuf:"varint,12,opt,name=reversed,proto3" json:"reversed,omitempty"`
+	SettingBack     float64                `protobuf:"fixed64,13,opt,name=setting_back,json=settingBack,proto3" json:"setting_back,omitempty"`       // metres
+	ArrivalTime     *float64               `protobuf:"fixed64,14,opt,name=arrival_time,json=arrivalTime,proto3,oneof" json:"arrival_time,omitempty"` // seconds
+	Coordinate      *Coordinate            `protobuf:"bytes,15,opt,name=coordinate,proto3" json:"coordinate,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ServiceLog) Reset() {
+	*x = ServiceLog{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceLog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceLog) ProtoMessage() {}
+
+func (x *ServiceLog) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceLog.ProtoReflect.Descriptor instead.
+func (*ServiceLog) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ServiceLog) GetServiceId() string {
+	if x != nil {
+		return x.ServiceId
+	}
+	return ""
+}
+
+func (x *ServiceLog) GetCurrentPosition() *Position {
+	if x != nil {
+		return x.CurrentPosition
+	}
+	return nil
+}
+
+func (x *ServiceLog) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ServiceLog) GetVelocity() float64 {
+	if x != nil {
+		return x.Velocity
+	}
+	return 0
+}
+
+func (x *ServiceLog) GetRemainingDwell() float64 {
+	if x != nil {
+		return x.RemainingDwell
+	}
+	return 0
+}
+
+func (x *ServiceLog) GetNextStop() string {
+	if x != nil {
+		return x.NextStop
+	}
+	return ""
+}
+
+func (x *ServiceLog) GetTractionEnergy() float64 {
+	if x != nil {
+		return x.TractionEnergy
+	}
+	return 0
+}
+
+func (x *ServiceLog) GetRegenEnergy() float64 {
+	if x != nil {
+		return x.RegenEnergy
+	}
+	return 0
+}
+
+func (x *ServiceLog) GetOdometer() float64 {
+	if x != nil {
+		return x.Odometer
+	}
+	return 0
+}
+
+func (x *ServiceLog) GetPassengers() int32 {
+	if x != nil {
+		return x.Passengers
+	}
+	return 0
+}
+
+func (x *ServiceLog) GetDelay() float64 {
+	if x != nil && x.Delay != nil {
+		return *x.Delay
+	}
+	return 0
+}
+
+func (x *ServiceLog) GetReversed() bool {
+	if x != nil {
+		return x.Reversed
+	}
+	return false
+}
+
+func (x *ServiceLog) GetSettingBack() float64 {
+	if x != nil {
+		return x.SettingBack
+	}
+	return 0
+}
+
+func (x *ServiceLog) GetArrivalTime() float64 {
+	if x != nil && x.ArrivalTime != nil {
+		return *x.ArrivalTime
+	}
+	return 0
+}
+
+func (x *ServiceLog) GetCoordinate() *Coordinate {
+	if x != nil {
+		return x.Coordinate
+	}
+	return nil
+}
+
+type SimulationSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Services      []*ServiceSummary      `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	TotalDistance float64                `protobuf:"fixed64,2,opt,name=total_distance,json=totalDistance,proto3" json:"total_distance,omitempty"` // metres
+	Separations   []*Separation          `protobuf:"bytes,3,rep,name=separations,proto3" json:"separations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimulationSummary) Reset() {
+	*x = SimulationSummary{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimulationSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimulationSummary) ProtoMessage() {}
+
+func (x *SimulationSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimulationSummary.ProtoReflect.Descriptor instead.
+func (*SimulationSummary) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SimulationSummary) GetServices() []*ServiceSummary {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+func (x *SimulationSummary) GetTotalDistance() float64 {
+	if x != nil {
+		return x.TotalDistance
+	}
+	return 0
+}
+
+func (x *SimulationSummary) GetSeparations() []*Separation {
+	if x != nil {
+		return x.Separations
+	}
+	return nil
+}
+
+type ServiceSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServiceId     string                 `protobuf:"bytes,1,opt,name=service_id,json=serviceId,proto3" json:"service_id,omitempty"`
+	Distance      float64                `protobuf:"fixed64,2,opt,name=distance,proto3" json:"distance,omitempty"`                          // metres
+	RunningTime   float64                `protobuf:"fixed64,3,opt,name=running_time,json=runningTime,proto3" json:"running_time,omitempty"` // seconds
+	AverageSpeed  float64                `protobuf:"fixed64,4,opt,name=average_speed,json=averageSpeed,proto3" json:"average_speed,omitempty"`
+	MaxSpeed      float64                `protobuf:"fixed64,5,opt,name=max_speed,json=maxSpeed,proto3" json:"max_speed,omitempty"`
+	DwellTime     float64                `protobuf:"fixed64,6,opt,name=dwell_time,json=dwellTime,proto3" json:"dwell_time,omitempty"` // seconds
+	StopsServed   int32                  `protobuf:"varint,7,opt,name=stops_served,json=stopsServed,proto3" json:"stops_served,omitempty"`
+	Delay         *float64               `protobuf:"fixed64,8,opt,name=delay,proto3,oneof" json:"delay,omitempty"` // seconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceSummary) Reset() {
+	*x = ServiceSummary{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceSummary) ProtoMessage() {}
+
+func (x *ServiceSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceSummary.ProtoReflect.Descriptor instead.
+func (*ServiceSummary) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ServiceSummary) GetServiceId() string {
+	if x != nil {
+		return x.ServiceId
+	}
+	return ""
+}
+
+func (x *ServiceSummary) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *ServiceSummary) GetRunningTime() float64 {
+	if x != nil {
+		return x.RunningTime
+	}
+	return 0
+}
+
+func (x *ServiceSummary) GetAverageSpeed() float64 {
+	if x != nil {
+		return x.AverageSpeed
+	}
+	return 0
+}
+
+func (x *ServiceSummary) GetMaxSpeed() float64 {
+	if x != nil {
+		return x.MaxSpeed
+	}
+	return 0
+}
+
+func (x *ServiceSummary) GetDwellTime() float64 {
+	if x != nil {
+		return x.DwellTime
+	}
+	return 0
+}
+
+func (x *ServiceSummary) GetStopsServed() int32 {
+	if x != nil {
+		return x.StopsServed
+	}
+	return 0
+}
+
+func (x *ServiceSummary) GetDelay() float64 {
+	if x != nil && x.Delay != nil {
+		return *x.Delay
+	}
+	return 0
+}
+
+type Separation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Services      []string               `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	Distance      float64                `protobuf:"fixed64,2,opt,name=distance,proto3" json:"distance,omitempty"` // metres
+	Time          float64                `protobuf:"fixed64,3,opt,name=time,proto3" json:"time,omitempty"`         // seconds
+	BelowMinimum  bool                   `protobuf:"varint,4,opt,name=below_minimum,json=belowMinimum,proto3" json:"below_minimum,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Separation) Reset() {
+	*x = Separation{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Separation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Separation) ProtoMessage() {}
+
+func (x *Separation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Separation.ProtoReflect.Descriptor instead.
+func (*Separation) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *Separation) GetServices() []string {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+func (x *Separation) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *Separation) GetTime() float64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *Separation) GetBelowMinimum() bool {
+	if x != nil {
+		return x.BelowMinimum
+	}
+	return false
+}
+
+type Journey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServiceId     string                 `protobuf:"bytes,1,opt,name=service_id,json=serviceId,proto3" json:"service_id,omitempty"`
+	Stops         []*StopEvent           `protobuf:"bytes,2,rep,name=stops,proto3" json:"stops,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Journey) Reset() {
+	*x = Journey{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Journey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Journey) ProtoMessage() {}
+
+func (x *Journey) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Journey.ProtoReflect.Descriptor instead.
+func (*Journey) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Journey) GetServiceId() string {
+	if x != nil {
+		return x.ServiceId
+	}
+	return ""
+}
+
+func (x *Journey) GetStops() []*StopEvent {
+	if x != nil {
+		return x.Stops
+	}
+	return nil
+}
+
+type StopEvent struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	NodeId             string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Arrival            *float64               `protobuf:"fixed64,2,opt,name=arrival,proto3,oneof" json:"arrival,omitempty"`                                                 // seconds
+	Departure          *float64               `protobuf:"fixed64,3,opt,name=departure,proto3,oneof" json:"departure,omitempty"`                                             // seconds
+	ScheduledArrival   *float64               `protobuf:"fixed64,4,opt,name=scheduled_arrival,json=scheduledArrival,proto3,oneof" json:"scheduled_arrival,omitempty"`       // seconds
+	ScheduledDeparture *float64               `protobuf:"fixed64,5,opt,name=scheduled_departure,json=scheduledDeparture,proto3,oneof" json:"scheduled_departure,omitempty"` // seconds
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *StopEvent) Reset() {
+	*x = StopEvent{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopEvent) ProtoMessage() {}
+
+func (x *StopEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopEvent.ProtoReflect.Descriptor instead.
+func (*StopEvent) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *StopEvent) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *StopEvent) GetArrival() float64 {
+	if x != nil && x.Arrival != nil {
+		return *x.Arrival
+	}
+	return 0
+}
+
+func (x *StopEvent) GetDeparture() float64 {
+	if x != nil && x.Departure != nil {
+		return *x.Departure
+	}
+	return 0
+}
+
+func (x *StopEvent) GetScheduledArrival() float64 {
+	if x != nil && x.ScheduledArrival != nil {
+		return *x.ScheduledArrival
+	}
+	return 0
+}
+
+func (x *StopEvent) GetScheduledDeparture() float64 {
+	if x != nil && x.ScheduledDeparture != nil {
+		return *x.ScheduledDeparture
+	}
+	return 0
+}
+
+// ErrorInfo describes why a run failed. It is attached to the error status of a failed
+// RPC as a detail.
+type ErrorInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	ServiceId     string                 `protobuf:"bytes,2,opt,name=service_id,json=serviceId,proto3" json:"service_id,omitempty"`
+	ServiceIds    []string               `protobuf:"bytes,3,rep,name=service_ids,json=serviceIds,proto3" json:"service_ids,omitempty"`
+	EdgeId        string                 `protobuf:"bytes,4,opt,name=edge_id,json=edgeId,proto3" json:"edge_id,omitempty"`
+	Time          *float64               `protobuf:"fixed64,5,opt,name=time,proto3,oneof" json:"time,omitempty"` // seconds
+	Message       string                 `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorInfo) Reset() {
+	*x = ErrorInfo{}
+	mi := &file_api_tms_v1_tms_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorInfo) ProtoMessage() {}
+
+func (x *ErrorInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tms_v1_tms_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorInfo.ProtoReflect.Descriptor instead.
+func (*ErrorInfo) Descriptor() ([]byte, []int) {
+	return file_api_tms_v1_tms_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ErrorInfo) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ErrorInfo) GetServiceId() string {
+	if x != nil {
+		return x.ServiceId
+	}
+	return ""
+}
+
+func (x *ErrorInfo) GetServiceIds() []string {
+	if x != nil {
+		return x.ServiceIds
+	}
+	return nil
+}
+
+func (x *ErrorInfo) GetEdgeId() string {
+	if x != nil {
+		return x.EdgeId
+	}
+	return ""
+}
+
+func (x *ErrorInfo) GetTime() float64 {
+	if x != nil && x.Time != nil {
+		return *x.Time
+	}
+	return 0
+}
+
+func (x *ErrorInfo) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_api_tms_v1_tms_proto protoreflect.FileDescriptor
+
+const file_api_tms_v1_tms_proto_rawDesc = "" +
+	"\n" +
+	"\x14api/tms/v1/tms.proto\x12\x06tms.v1\x1a\x1cgoogle/protobuf/struct.proto\"\x86\x05\n" +
+	"\x0fSimulationInput\x12?\n" +
+	"\x0fsimulation_meta\x18\x01 \x01(\v2\x16.tms.v1.SimulationMetaR\x0esimulationMeta\x120\n" +
+	"\n" +
+	"graph_data\x18\x02 \x01(\v2\x11.tms.v1.GraphDataR\tgraphData\x122\n" +
+	"\fservice_list\x18\x03 \x03(\v2\x0f.tms.v1.ServiceR\vserviceList\x12(\n" +
+	"\x06events\x18\x04 \x03(\v2\x10.tms.v1.SimEventR\x06events\x12\x1f\n" +
+	"\x04tsrs\x18\x05 \x03(\v2\v.tms.v1.TSRR\x04tsrs\x12T\n" +
+	"\x0fvehicle_library\x18\x06 \x03(\v2+.tms.v1.SimulationInput.VehicleLibraryEntryR\x0evehicleLibrary\x12N\n" +
+	"\rroute_library\x18\a \x03(\v2).tms.v1.SimulationInput.RouteLibraryEntryR\frouteLibrary\x127\n" +
+	"\vfrequencies\x18\b \x03(\v2\x15.tms.v1.FrequencySpecR\vfrequencies\x1aR\n" +
+	"\x13VehicleLibraryEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12%\n" +
+	"\x05value\x18\x02 \x01(\v2\x0f.tms.v1.VehicleR\x05value:\x028\x01\x1aN\n" +
+	"\x11RouteLibraryEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12#\n" +
+	"\x05value\x18\x02 \x01(\v2\r.tms.v1.RouteR\x05value:\x028\x01\"\xc1\a\n" +
+	"\x0eSimulationMeta\x12#\n" +
+	"\rsimulation_id\x18\x01 \x01(\tR\fsimulationId\x12\x19\n" +
+	"\brun_time\x18\x02 \x01(\x01R\arunTime\x12\x1b\n" +
+	"\ttime_step\x18\x03 \x01(\x01R\btimeStep\x12\x1f\n" +
+	"\badhesion\x18\x04 \x01(\x01H\x00R\badhesion\x88\x01\x01\x12\x1f\n" +
+	"\vmin_headway\x18\x05 \x01(\x01R\n" +
+	"minHeadway\x12#\n" +
+	"\rsafety_margin\x18\x06 \x01(\x01R\fsafetyMargin\x12'\n" +
+	"\x0fsignalling_mode\x18\a \x01(\tR\x0esignallingMode\x12!\n" +
+	"\fblock_length\x18\b \x01(\x01R\vblockLength\x12\x1e\n" +
+	"\n" +
+	"congestion\x18\t \x01(\bR\n" +
+	"congestion\x12)\n" +
+	"\x10conflict_horizon\x18\n" +
+	" \x01(\x01R\x0fconflictHorizon\x12\x1e\n" +
+	"\n" +
+	"regulation\x18\v \x01(\bR\n" +
+	"regulation\x121\n" +
+	"\x14regulation_threshold\x18\f \x01(\x01R\x13regulationThreshold\x12-\n" +
+	"\x12report_separations\x18\r \x01(\bR\x11reportSeparations\x12%\n" +
+	"\x0emin_separation\x18\x0e \x01(\x01R\rminSeparation\x12\x1b\n" +
+	"\tsub_steps\x18\x0f \x01(\x05R\bsubSteps\x12\x19\n" +
+	"\blog_mode\x18\x10 \x01(\tR\alogMode\x124\n" +
+	"\x16log_velocity_threshold\x18\x11 \x01(\x01R\x14logVelocityThreshold\x12!\n" +
+	"\flog_interval\x18\x12 \x01(\x01R\vlogInterval\x12.\n" +
+	"\x10output_precision\x18\x13 \x01(\x05H\x01R\x0foutputPrecision\x88\x01\x01\x12'\n" +
+	"\x0flog_coordinates\x18\x14 \x01(\bR\x0elogCoordinates\x12\x1d\n" +
+	"\n" +
+	"speed_unit\x18\x15 \x01(\tR\tspeedUnit\x12\x12\n" +
+	"\x04seed\x18\x16 \x01(\x03R\x04seed\x121\n" +
+	"\fdwell_jitter\x18\x17 \x01(\v2\x0e.tms.v1.JitterR\vdwellJitter\x129\n" +
+	"\x10departure_jitter\x18\x18 \x01(\v2\x0e.tms.v1.JitterR\x0fdepartureJitterB\v\n" +
+	"\t_adhesionB\x13\n" +
+	"\x11_output_precision\"D\n" +
+	"\x06Jitter\x12\"\n" +
+	"\fdistribution\x18\x01 \x01(\tR\fdistribution\x12\x16\n" +
+	"\x06spread\x18\x02 \x01(\x01R\x06spread\"\x94\x02\n" +
+	"\tGraphData\x12\"\n" +
+	"\x05nodes\x18\x01 \x03(\v2\f.tms.v1.NodeR\x05nodes\x12\"\n" +
+	"\x05edges\x18\x02 \x03(\v2\f.tms.v1.EdgeR\x05edges\x12%\n" +
+	"\x06blocks\x18\x03 \x03(\v2\r.tms.v1.BlockR\x06blocks\x12*\n" +
+	"\x11max_lateral_accel\x18\x04 \x01(\x01R\x0fmaxLateralAccel\x12\x1f\n" +
+	"\vlength_unit\x18\x05 \x01(\tR\n" +
+	"lengthUnit\x12(\n" +
+	"\x10min_length_ratio\x18\x06 \x01(\x01R\x0eminLengthRatio\x12!\n" +
+	"\fauto_lengths\x18\a \x01(\bR\vautoLengths\"(\n" +
+	"\n" +
+	"Coordinate\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x01R\x01y\"u\n" +
+	"\x04Node\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12$\n" +
+	"\x03loc\x18\x02 \x01(\v2\x12.tms.v1.CoordinateR\x03loc\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1a\n" +
+	"\bjunction\x18\x04 \x01(\bR\bjunction\"\xb1\x02\n" +
+	"\x04Edge\x12\x17\n" +
+	"\aedge_id\x18\x01 \x01(\tR\x06edgeId\x12\f\n" +
+	"\x01u\x18\x02 \x01(\tR\x01u\x12\f\n" +
+	"\x01v\x18\x03 \x01(\tR\x01v\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x01R\x06length\x12$\n" +
+	"\vspeed_limit\x18\x05 \x01(\x01H\x00R\n" +
+	"speedLimit\x88\x01\x01\x12\x1a\n" +
+	"\bgradient\x18\x06 \x01(\x01R\bgradient\x12\x1f\n" +
+	"\badhesion\x18\a \x01(\x01H\x01R\badhesion\x88\x01\x01\x12\x1a\n" +
+	"\bsections\x18\b \x01(\x05R\bsections\x12\x1a\n" +
+	"\bcapacity\x18\t \x01(\x05R\bcapacity\x12$\n" +
+	"\rbidirectional\x18\n" +
+	" \x01(\bR\rbidirectionalB\x0e\n" +
+	"\f_speed_limitB\v\n" +
+	"\t_adhesion\"8\n" +
+	"\x05Block\x12\x19\n" +
+	"\bblock_id\x18\x01 \x01(\tR\ablockId\x12\x14\n" +
+	"\x05edges\x18\x02 \x03(\tR\x05edges\"N\n" +
+	"\bPosition\x12\x12\n" +
+	"\x04edge\x18\x01 \x01(\tR\x04edge\x12.\n" +
+	"\x13distance_along_edge\x18\x02 \x01(\x01R\x11distanceAlongEdge\"E\n" +
+	"\aSegment\x12\x12\n" +
+	"\x04edge\x18\x01 \x01(\tR\x04edge\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x01R\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x01R\x03end\"\xfe\x02\n" +
+	"\aVehicle\x12\x10\n" +
+	"\x03ref\x18\x01 \x01(\tR\x03ref\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x01R\x06length\x12\x1d\n" +
+	"\n" +
+	"mass_empty\x18\x04 \x01(\x01R\tmassEmpty\x12,\n" +
+	"\x12mass_per_passenger\x18\x05 \x01(\x01R\x10massPerPassenger\x12#\n" +
+	"\rreaction_time\x18\x06 \x01(\x01R\freactionTime\x12\x1f\n" +
+	"\vcoast_speed\x18\a \x01(\x01R\n" +
+	"coastSpeed\x12!\n" +
+	"\fresume_speed\x18\b \x01(\x01R\vresumeSpeed\x12\x1f\n" +
+	"\vcreep_speed\x18\t \x01(\x01R\n" +
+	"creepSpeed\x12%\n" +
+	"\x0ecreep_distance\x18\n" +
+	" \x01(\x01R\rcreepDistance\x127\n" +
+	"\n" +
+	"kinematics\x18\v \x01(\v2\x17.google.protobuf.StructR\n" +
+	"kinematics\"D\n" +
+	"\aUnitRef\x12#\n" +
+	"\x04unit\x18\x01 \x01(\v2\x0f.tms.v1.VehicleR\x04unit\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"\xed\x04\n" +
+	"\aService\x12\x1d\n" +
+	"\n" +
+	"service_id\x18\x01 \x01(\tR\tserviceId\x12)\n" +
+	"\x10initial_position\x18\x02 \x01(\tR\x0finitialPosition\x127\n" +
+	"\x0estart_position\x18\x03 \x01(\v2\x10.tms.v1.PositionR\rstartPosition\x12'\n" +
+	"\x05route\x18\x04 \x03(\v2\x11.tms.v1.RouteStopR\x05route\x12\x1b\n" +
+	"\troute_ref\x18\x05 \x01(\tR\brouteRef\x12)\n" +
+	"\avehicle\x18\x06 \x01(\v2\x0f.tms.v1.VehicleR\avehicle\x12%\n" +
+	"\x05units\x18\a \x03(\v2\x0f.tms.v1.UnitRefR\x05units\x12'\n" +
+	"\x0fdeparture_delay\x18\b \x01(\x01R\x0edepartureDelay\x12-\n" +
+	"\x12initial_passengers\x18\t \x01(\x05R\x11initialPassengers\x12\x1a\n" +
+	"\bpriority\x18\n" +
+	" \x01(\x05R\bpriority\x12\x12\n" +
+	"\x04loop\x18\v \x01(\bR\x04loop\x12\x18\n" +
+	"\ashuttle\x18\f \x01(\bR\ashuttle\x12\x1b\n" +
+	"\tmax_speed\x18\r \x01(\x01R\bmaxSpeed\x128\n" +
+	"\vdwell_model\x18\x0e \x01(\v2\x17.google.protobuf.StructR\n" +
+	"dwellModel\x12)\n" +
+	"\x10initial_velocity\x18\x0f \x01(\x01R\x0finitialVelocity\x12#\n" +
+	"\rinitial_state\x18\x10 \x01(\tR\finitialState\"\xdb\x04\n" +
+	"\tRouteStop\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x1b\n" +
+	"\tmin_dwell\x18\x02 \x01(\x01R\bminDwell\x120\n" +
+	"\x11scheduled_arrival\x18\x03 \x01(\x01H\x00R\x10scheduledArrival\x88\x01\x01\x124\n" +
+	"\x13scheduled_departure\x18\x04 \x01(\x01H\x01R\x12scheduledDeparture\x88\x01\x01\x12\x1a\n" +
+	"\bboarders\x18\x05 \x01(\x05R\bboarders\x12\x1c\n" +
+	"\talighters\x18\x06 \x01(\x05R\talighters\x12$\n" +
+	"\x0edoor_flow_rate\x18\a \x01(\x01R\fdoorFlowRate\x12\x12\n" +
+	"\x04skip\x18\b \x01(\bR\x04skip\x12!\n" +
+	"\frequest_stop\x18\t \x01(\bR\vrequestStop\x12\x16\n" +
+	"\x06couple\x18\n" +
+	" \x01(\tR\x06couple\x12\x12\n" +
+	"\x04join\x18\v \x01(\tR\x04join\x12(\n" +
+	"\x06divide\x18\f \x01(\v2\x10.tms.v1.DivisionR\x06divide\x12\x18\n" +
+	"\areverse\x18\r \x01(\bR\areverse\x12\x19\n" +
+	"\bset_back\x18\x0e \x01(\x01R\asetBack\x128\n" +
+	"\vdwell_model\x18\x0f \x01(\v2\x17.google.protobuf.StructR\n" +
+	"dwellModel\x12\x10\n" +
+	"\x03via\x18\x10 \x03(\tR\x03via\x12\x14\n" +
+	"\x05track\x18\x11 \x03(\tR\x05trackB\x14\n" +
+	"\x12_scheduled_arrivalB\x16\n" +
+	"\x14_scheduled_departure\"h\n" +
+	"\bDivision\x12\x1d\n" +
+	"\n" +
+	"service_id\x18\x01 \x01(\tR\tserviceId\x12\x14\n" +
+	"\x05units\x18\x02 \x01(\x05R\x05units\x12'\n" +
+	"\x05route\x18\x03 \x03(\v2\x11.tms.v1.RouteStopR\x05route\"0\n" +
+	"\x05Route\x12'\n" +
+	"\x05stops\x18\x01 \x03(\v2\x11.tms.v1.RouteStopR\x05stops\"\xa7\x01\n" +
+	"\bSimEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\x01R\x04time\x12\x1a\n" +
+	"\bduration\x18\x03 \x01(\x01R\bduration\x12\x1d\n" +
+	"\n" +
+	"service_id\x18\x04 \x01(\tR\tserviceId\x12\x17\n" +
+	"\aedge_id\x18\x05 \x01(\tR\x06edgeId\x12\x1f\n" +
+	"\vspeed_limit\x18\x06 \x01(\x01R\n" +
+	"speedLimit\"\xa4\x01\n" +
+	"\x03TSR\x12\x17\n" +
+	"\aedge_id\x18\x01 \x01(\tR\x06edgeId\x12)\n" +
+	"\asegment\x18\x02 \x01(\v2\x0f.tms.v1.SegmentR\asegment\x12\x1f\n" +
+	"\vspeed_limit\x18\x03 \x01(\x01R\n" +
+	"speedLimit\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x04 \x01(\x01R\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x05 \x01(\x01R\aendTime\"\xe9\x01\n" +
+	"\rFrequencySpec\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05route\x18\x02 \x01(\tR\x05route\x12)\n" +
+	"\x10initial_position\x18\x03 \x01(\tR\x0finitialPosition\x12)\n" +
+	"\avehicle\x18\x04 \x01(\v2\x0f.tms.v1.VehicleR\avehicle\x12\x1a\n" +
+	"\bpriority\x18\x05 \x01(\x05R\bpriority\x12\x14\n" +
+	"\x05start\x18\x06 \x01(\x01R\x05start\x12\x10\n" +
+	"\x03end\x18\a \x01(\x01R\x03end\x12\x18\n" +
+	"\aheadway\x18\b \x01(\x01R\aheadway\"\xcc\x02\n" +
+	"\rSimulationLog\x12?\n" +
+	"\x0fsimulation_meta\x18\x01 \x01(\v2\x16.tms.v1.SimulationMetaR\x0esimulationMeta\x120\n" +
+	"\x06output\x18\x02 \x03(\v2\x18.tms.v1.SimulationLogRowR\x06output\x12'\n" +
+	"\x0ftraction_energy\x18\x03 \x01(\x01R\x0etractionEnergy\x12!\n" +
+	"\fregen_energy\x18\x04 \x01(\x01R\vregenEnergy\x123\n" +
+	"\asummary\x18\x05 \x01(\v2\x19.tms.v1.SimulationSummaryR\asummary\x12+\n" +
+	"\bjourneys\x18\x06 \x03(\v2\x0f.tms.v1.JourneyR\bjourneys\x12\x1a\n" +
+	"\bwarnings\x18\a \x03(\tR\bwarnings\"g\n" +
+	"\x10SimulationLogRow\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x01R\ttimestamp\x125\n" +
+	"\fservice_logs\x18\x02 \x03(\v2\x12.tms.v1.ServiceLogR\vserviceLogs\"\xb9\x04\n" +
+	"\n" +
+	"ServiceLog\x12\x1d\n" +
+	"\n" +
+	"service_id\x18\x01 \x01(\tR\tserviceId\x12;\n" +
+	"\x10current_position\x18\x02 \x01(\v2\x10.tms.v1.PositionR\x0fcurrentPosition\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x1a\n" +
+	"\bvelocity\x18\x04 \x01(\x01R\bvelocity\x12'\n" +
+	"\x0fremaining_dwell\x18\x05 \x01(\x01R\x0eremainingDwell\x12\x1b\n" +
+	"\tnext_stop\x18\x06 \x01(\tR\bnextStop\x12'\n" +
+	"\x0ftraction_energy\x18\a \x01(\x01R\x0etractionEnergy\x12!\n" +
+	"\fregen_energy\x18\b \x01(\x01R\vregenEnergy\x12\x1a\n" +
+	"\bodometer\x18\t \x01(\x01R\bodometer\x12\x1e\n" +
+	"\n" +
+	"passengers\x18\n" +
+	" \x01(\x05R\n" +
+	"passengers\x12\x19\n" +
+	"\x05delay\x18\v \x01(\x01H\x00R\x05delay\x88\x01\x01\x12\x1a\n" +
+	"\breversed\x18\f \x01(\bR\breversed\x12!\n" +
+	"\fsetting_back\x18\r \x01(\x01R\vsettingBack\x12&\n" +
+	"\farrival_time\x18\x0e \x01(\x01H\x01R\varrivalTime\x88\x01\x01\x122\n" +
+	"\n" +
+	"coordinate\x18\x0f \x01(\v2\x12.tms.v1.CoordinateR\n" +
+	"coordinateB\b\n" +
+	"\x06_delayB\x0f\n" +
+	"\r_arrival_time\"\xa4\x01\n" +
+	"\x11SimulationSummary\x122\n" +
+	"\bservices\x18\x01 \x03(\v2\x16.tms.v1.ServiceSummaryR\bservices\x12%\n" +
+	"\x0etotal_distance\x18\x02 \x01(\x01R\rtotalDistance\x124\n" +
+	"\vseparations\x18\x03 \x03(\v2\x12.tms.v1.SeparationR\vseparations\"\x97\x02\n" +
+	"\x0eServiceSummary\x12\x1d\n" +
+	"\n" +
+	"service_id\x18\x01 \x01(\tR\tserviceId\x12\x1a\n" +
+	"\bdistance\x18\x02 \x01(\x01R\bdistance\x12!\n" +
+	"\frunning_time\x18\x03 \x01(\x01R\vrunningTime\x12#\n" +
+	"\raverage_speed\x18\x04 \x01(\x01R\faverageSpeed\x12\x1b\n" +
+	"\tmax_speed\x18\x05 \x01(\x01R\bmaxSpeed\x12\x1d\n" +
+	"\n" +
+	"dwell_time\x18\x06 \x01(\x01R\tdwellTime\x12!\n" +
+	"\fstops_served\x18\a \x01(\x05R\vstopsServed\x12\x19\n" +
+	"\x05delay\x18\b \x01(\x01H\x00R\x05delay\x88\x01\x01B\b\n" +
+	"\x06_delay\"}\n" +
+	"\n" +
+	"Separation\x12\x1a\n" +
+	"\bservices\x18\x01 \x03(\tR\bservices\x12\x1a\n" +
+	"\bdistance\x18\x02 \x01(\x01R\bdistance\x12\x12\n" +
+	"\x04time\x18\x03 \x01(\x01R\x04time\x12#\n" +
+	"\rbelow_minimum\x18\x04 \x01(\bR\fbelowMinimum\"Q\n" +
+	"\aJourney\x12\x1d\n" +
+	"\n" +
+	"service_id\x18\x01 \x01(\tR\tserviceId\x12'\n" +
+	"\x05stops\x18\x02 \x03(\v2\x11.tms.v1.StopEventR\x05stops\"\x96\x02\n" +
+	"\tStopEvent\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x1d\n" +
+	"\aarrival\x18\x02 \x01(\x01H\x00R\aarrival\x88\x01\x01\x12!\n" +
+	"\tdeparture\x18\x03 \x01(\x01H\x01R\tdeparture\x88\x01\x01\x120\n" +
+	"\x11scheduled_arrival\x18\x04 \x01(\x01H\x02R\x10scheduledArrival\x88\x01\x01\x124\n" +
+	"\x13scheduled_departure\x18\x05 \x01(\x01H\x03R\x12scheduledDeparture\x88\x01\x01B\n" +
+	"\n" +
+	"\b_arrivalB\f\n" +
+	"\n" +
+	"_departureB\x14\n" +
+	"\x12_scheduled_arrivalB\x16\n" +
+	"\x14_scheduled_departure\"\xb4\x01\n" +
+	"\tErrorInfo\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1d\n" +
+	"\n" +
+	"service_id\x18\x02 \x01(\tR\tserviceId\x12\x1f\n" +
+	"\vservice_ids\x18\x03 \x03(\tR\n" +
+	"serviceIds\x12\x17\n" +
+	"\aedge_id\x18\x04 \x01(\tR\x06edgeId\x12\x17\n" +
+	"\x04time\x18\x05 \x01(\x01H\x00R\x04time\x88\x01\x01\x12\x18\n" +
+	"\amessage\x18\x06 \x01(\tR\amessageB\a\n" +
+	"\x05_time2\x99\x01\n" +
+	"\n" +
+	"Simulation\x12?\n" +
+	"\rRunSimulation\x12\x17.tms.v1.SimulationInput\x1a\x15.tms.v1.SimulationLog\x12J\n" +
+	"\x13RunSimulationStream\x12\x17.tms.v1.SimulationInput\x1a\x18.tms.v1.SimulationLogRow0\x01B/Z-github.com/cxd309/tms-engine/api/tms/v1;tmsv1b\x06proto3"
+
+var (
+	file_api_tms_v1_tms_proto_rawDescOnce sync.Once
+	file_api_tms_v1_tms_proto_rawDescData []byte
+)
+
+func file_api_tms_v1_tms_proto_rawDescGZIP() []byte {
+	file_api_tms_v1_tms_proto_rawDescOnce.Do(func() {
+		file_api_tms_v1_tms_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_tms_v1_tms_proto_rawDesc), len(file_api_tms_v1_tms_proto_rawDesc)))
+	})
+	return file_api_tms_v1_tms_proto_rawDescData
+}
+
+var file_api_tms_v1_tms_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
+var file_api_tms_v1_tms_proto_goTypes = []any{
+	(*SimulationInput)(nil),   // 0: tms.v1.SimulationInput
+	(*SimulationMeta)(nil),    // 1: tms.v1.SimulationMeta
+	(*Jitter)(nil),            // 2: tms.v1.Jitter
+	(*GraphData)(nil),         // 3: tms.v1.GraphData
+	(*Coordinate)(nil),        // 4: tms.v1.Coordinate
+	(*Node)(nil),              // 5: tms.v1.Node
+	(*Edge)(nil),              // 6: tms.v1.Edge
+	(*Block)(nil),             // 7: tms.v1.Block
+	(*Position)(nil),          // 8: tms.v1.Position
+	(*Segment)(nil),           // 9: tms.v1.Segment
+	(*Vehicle)(nil),           // 10: tms.v1.Vehicle
+	(*UnitRef)(nil),           // 11: tms.v1.UnitRef
+	(*Service)(nil),           // 12: tms.v1.Service
+	(*RouteStop)(nil),         // 13: tms.v1.RouteStop
+	(*Division)(nil),          // 14: tms.v1.Division
+	(*Route)(nil),             // 15: tms.v1.Route
+	(*SimEvent)(nil),          // 16: tms.v1.SimEvent
+	(*TSR)(nil),               // 17: tms.v1.TSR
+	(*FrequencySpec)(nil),     // 18: tms.v1.FrequencySpec
+	(*SimulationLog)(nil),     // 19: tms.v1.SimulationLog
+	(*SimulationLogRow)(nil),  // 20: tms.v1.SimulationLogRow
+	(*ServiceLog)(nil),        // 21: tms.v1.ServiceLog
+	(*SimulationSummary)(nil), // 22: tms.v1.SimulationSummary
+	(*ServiceSummary)(nil),    // 23: tms.v1.ServiceSummary
+	(*Separation)(nil),        // 24: tms.v1.Separation
+	(*Journey)(nil),           // 25: tms.v1.Journey
+	(*StopEvent)(nil),         // 26: tms.v1.StopEvent
+	(*ErrorInfo)(nil),         // 27: tms.v1.ErrorInfo
+	nil,                       // 28: tms.v1.SimulationInput.VehicleLibraryEntry
+	nil,                       // 29: tms.v1.SimulationInput.RouteLibraryEntry
+	(*structpb.Struct)(nil),   // 30: google.protobuf.Struct
+}
+var file_api_tms_v1_tms_proto_depIdxs = []int32{
+	1,  // 0: tms.v1.SimulationInput.simulation_meta:type_name -> tms.v1.SimulationMeta
+	3,  // 1: tms.v1.SimulationInput.graph_data:type_name -> tms.v1.GraphData
+	12, // 2: tms.v1.SimulationInput.service_list:type_name -> tms.v1.Service
+	16, // 3: tms.v1.SimulationInput.events:type_name -> tms.v1.SimEvent
+	17, // 4: tms.v1.SimulationInput.tsrs:type_name -> tms.v1.TSR
+	28, // 5: tms.v1.SimulationInput.vehicle_library:type_name -> tms.v1.SimulationInput.VehicleLibraryEntry
+	29, // 6: tms.v1.SimulationInput.route_library:type_name -> tms.v1.SimulationInput.RouteLibraryEntry
+	18, // 7: tms.v1.SimulationInput.frequencies:type_name -> tms.v1.FrequencySpec
+	2,  // 8: tms.v1.SimulationMeta.dwell_jitter:type_name -> tms.v1.Jitter
+	2,  // 9: tms.v1.SimulationMeta.departure_jitter:type_name -> tms.v1.Jitter
+	5,  // 10: tms.v1.GraphData.nodes:type_name -> tms.v1.Node
+	6,  // 11: tms.v1.GraphData.edges:type_name -> tms.v1.Edge
+	7,  // 12: tms.v1.GraphData.blocks:type_name -> tms.v1.Block
+	4,  // 13: tms.v1.Node.loc:type_name -> tms.v1.Coordinate
+	30, // 14: tms.v1.Vehicle.kinematics:type_name -> google.protobuf.Struct
+	10, // 15: tms.v1.UnitRef.unit:type_name -> tms.v1.Vehicle
+	8,  // 16: tms.v1.Service.start_position:type_name -> tms.v1.Position
+	13, // 17: tms.v1.Service.route:type_name -> tms.v1.RouteStop
+	10, // 18: tms.v1.Service.vehicle:type_name -> tms.v1.Vehicle
+	11, // 19: tms.v1.Service.units:type_name -> tms.v1.UnitRef
+	30, // 20: tms.v1.Service.dwell_model:type_name -> google.protobuf.Struct
+	14, // 21: tms.v1.RouteStop.divide:type_name -> tms.v1.Division
+	30, // 22: tms.v1.RouteStop.dwell_model:type_name -> google.protobuf.Struct
+	13, // 23: tms.v1.Division.route:type_name -> tms.v1.RouteStop
+	13, // 24: tms.v1.Route.stops:type_name -> tms.v1.RouteStop
+	9,  // 25: tms.v1.TSR.segment:type_name -> tms.v1.Segment
+	10, // 26: tms.v1.FrequencySpec.vehicle:type_name -> tms.v1.Vehicle
+	1,  // 27: tms.v1.SimulationLog.simulation_meta:type_name -> tms.v1.SimulationMeta
+	20, // 28: tms.v1.SimulationLog.output:type_name -> tms.v1.SimulationLogRow
+	22, // 29: tms.v1.SimulationLog.summary:type_name -> tms.v1.SimulationSummary
+	25, // 30: tms.v1.SimulationLog.journeys:type_name -> tms.v1.Journey
+	21, // 31: tms.v1.SimulationLogRow.service_logs:type_name -> tms.v1.ServiceLog
+	8,  // 32: tms.v1.ServiceLog.current_position:type_name -> tms.v1.Position
+	4,  // 33: tms.v1.ServiceLog.coordinate:type_name -> tms.v1.Coordinate
+	23, // 34: tms.v1.SimulationSummary.services:type_name -> tms.v1.ServiceSummary
+	24, // 35: tms.v1.SimulationSummary.separations:type_name -> tms.v1.Separation
+	26, // 36: tms.v1.Journey.stops:type_name -> tms.v1.StopEvent
+	10, // 37: tms.v1.SimulationInput.VehicleLibraryEntry.value:type_name -> tms.v1.Vehicle
+	15, // 38: tms.v1.SimulationInput.RouteLibraryEntry.value:type_name -> tms.v1.Route
+	0,  // 39: tms.v1.Simulation.RunSimulation:input_type -> tms.v1.SimulationInput
+	0,  // 40: tms.v1.Simulation.RunSimulationStream:input_type -> tms.v1.SimulationInput
+	19, // 41: tms.v1.Simulation.RunSimulation:output_type -> tms.v1.SimulationLog
+	20, // 42: tms.v1.Simulation.RunSimulationStream:output_type -> tms.v1.SimulationLogRow
+	41, // [41:43] is the sub-list for method output_type
+	39, // [39:41] is the sub-list for method input_type
+	39, // [39:39] is the sub-list for extension type_name
+	39, // [39:39] is the sub-list for extension extendee
+	0,  // [0:39] is the sub-list for field type_name
+}
+
+func init() { file_api_tms_v1_tms_proto_init() }
+func file_api_tms_v1_tms_proto_init() {
+	if File_api_tms_v1_tms_proto != nil {
+		return
+	}
+	file_api_tms_v1_tms_proto_msgTypes[1].OneofWrappers = []any{}
+	file_api_tms_v1_tms_proto_msgTypes[6].OneofWrappers = []any{}
+	file_api_tms_v1_tms_proto_msgTypes[13].OneofWrappers = []any{}
+	file_api_tms_v1_tms_proto_msgTypes[21].OneofWrappers = []any{}
+	file_api_tms_v1_tms_proto_msgTypes[23].OneofWrappers = []any{}
+	file_api_tms_v1_tms_proto_msgTypes[26].OneofWrappers = []any{}
+	file_api_tms_v1_tms_proto_msgTypes[27].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_tms_v1_tms_proto_rawDesc), len(file_api_tms_v1_tms_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   30,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_tms_v1_tms_proto_goTypes,
+		DependencyIndexes: file_api_tms_v1_tms_proto_depIdxs,
+		MessageInfos:      file_api_tms_v1_tms_proto_msgTypes,
+	}.Build()
+	File_api_tms_v1_tms_proto = out.File
+	file_api_tms_v1_tms_proto_goTypes = nil
+	file_api_tms_v1_tms_proto_depIdxs = nil
+}
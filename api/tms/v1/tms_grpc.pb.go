@@ -0,0 +1,182 @@
+// Service definition for running the TMS engine over gRPC (see cmd/grpcserver).
+//
+// The messages mirror the JSON contract in README.md field for field, under the same
+// names, so that a document reads the same either way. Where the JSON takes one of two
+// shapes, the message has a field for each: route_ref for a route given as the ID of a
+// route library entry, and Vehicle.ref for a vehicle given as the name of a vehicle
+// library entry. Kinematics and dwell models are open to registration by name, so their
+// parameters are carried as a Struct holding the JSON object, "model" key and all.
+//
+// Regenerate the Go bindings after changing this file with
+//
+//   protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. api/tms/v1/tms.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/tms/v1/tms.proto
+
+package tmsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Simulation_RunSimulation_FullMethodName       = "/tms.v1.Simulation/RunSimulation"
+	Simulation_RunSimulationStream_FullMethodName = "/tms.v1.Simulation/RunSimulationStream"
+)
+
+// SimulationClient is the client API for Simulation service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SimulationClient interface {
+	// RunSimulation runs a simulation to completion and returns its log.
+	RunSimulation(ctx context.Context, in *SimulationInput, opts ...grpc.CallOption) (*SimulationLog, error)
+	// RunSimulationStream runs a simulation, sending each log row as soon as it is
+	// computed. A failed run ends the stream with an error naming the timestep.
+	RunSimulationStream(ctx context.Context, in *SimulationInput, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SimulationLogRow], error)
+}
+
+type simulationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSimulationClient(cc grpc.ClientConnInterface) SimulationClient {
+	return &simulationClient{cc}
+}
+
+func (c *simulationClient) RunSimulation(ctx context.Context, in *SimulationInput, opts ...grpc.CallOption) (*SimulationLog, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimulationLog)
+	err := c.cc.Invoke(ctx, Simulation_RunSimulation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulationClient) RunSimulationStream(ctx context.Context, in *SimulationInput, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SimulationLogRow], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Simulation_ServiceDesc.Streams[0], Simulation_RunSimulationStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SimulationInput, SimulationLogRow]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Simulation_RunSimulationStreamClient = grpc.ServerStreamingClient[SimulationLogRow]
+
+// SimulationServer is the server API for Simulation service.
+// All implementations must embed UnimplementedSimulationServer
+// for forward compatibility.
+type SimulationServer interface {
+	// RunSimulation runs a simulation to completion and returns its log.
+	RunSimulation(context.Context, *SimulationInput) (*SimulationLog, error)
+	// RunSimulationStream runs a simulation, sending each log row as soon as it is
+	// computed. A failed run ends the stream with an error naming the timestep.
+	RunSimulationStream(*SimulationInput, grpc.ServerStreamingServer[SimulationLogRow]) error
+	mustEmbedUnimplementedSimulationServer()
+}
+
+// UnimplementedSimulationServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSimulationServer struct{}
+
+func (UnimplementedSimulationServer) RunSimulation(context.Context, *SimulationInput) (*SimulationLog, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunSimulation not implemented")
+}
+func (UnimplementedSimulationServer) RunSimulationStream(*SimulationInput, grpc.ServerStreamingServer[SimulationLogRow]) error {
+	return status.Errorf(codes.Unimplemented, "method RunSimulationStream not implemented")
+}
+func (UnimplementedSimulationServer) mustEmbedUnimplementedSimulationServer() {}
+func (UnimplementedSimulationServer) testEmbeddedByValue()                    {}
+
+// UnsafeSimulationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SimulationServer will
+// result in compilation errors.
+type UnsafeSimulationServer interface {
+	mustEmbedUnimplementedSimulationServer()
+}
+
+func RegisterSimulationServer(s grpc.ServiceRegistrar, srv SimulationServer) {
+	// If the following call pancis, it indicates UnimplementedSimulationServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Simulation_ServiceDesc, srv)
+}
+
+func _Simulation_RunSimulation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimulationInput)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulationServer).RunSimulation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Simulation_RunSimulation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulationServer).RunSimulation(ctx, req.(*SimulationInput))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Simulation_RunSimulationStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SimulationInput)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SimulationServer).RunSimulationStream(m, &grpc.GenericServerStream[SimulationInput, SimulationLogRow]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Simulation_RunSimulationStreamServer = grpc.ServerStreamingServer[SimulationLogRow]
+
+// Simulation_ServiceDesc is the grpc.ServiceDesc for Simulation service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Simulation_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tms.v1.Simulation",
+	HandlerType: (*SimulationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RunSimulation",
+			Handler:    _Simulation_RunSimulation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RunSimulationStream",
+			Handler:       _Simulation_RunSimulationStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/tms/v1/tms.proto",
+}
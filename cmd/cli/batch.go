@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cxd309/tms-engine/internal/engine"
+)
+
+// inputFiles expands args into the input files to run: a directory stands for the JSON
+// files in it, and a glob pattern for the files it matches, other than logs. It reports
+// whether they make a batch: more than one file, or any from a directory or pattern.
+func inputFiles(args []string) ([]string, bool, error) {
+	var files []string
+	batch := len(args) > 1
+	for _, arg := range args {
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			matches, err := filepath.Glob(filepath.Join(arg, "*.json"))
+			if err != nil {
+				return nil, false, err
+			}
+			files = append(files, notLogs(matches)...)
+			batch = true
+			continue
+		}
+		if strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, false, err
+			}
+			if len(matches) == 0 {
+				return nil, false, fmt.Errorf("no files match %q", arg)
+			}
+			files = append(files, notLogs(matches)...)
+			batch = true
+			continue
+		}
+		files = append(files, arg)
+	}
+	return files, batch, nil
+}
+
+// notLogs returns the paths in paths other than those of logs written by runBatch.
+func notLogs(paths []string) []string {
+	return slices.DeleteFunc(paths, func(p string) bool {
+		return strings.Contains(filepath.Base(p), ".log.")
+	})
+}
+
+// runBatch runs each of files, up to jobs at once, writing each one's log in format,
+// indented if pretty, beside it as <name>.log.<format> and a summary line for it to
+// stderr. Inputs over the same network share one copy of it. Each run is abandoned after
+// timeout, if set, and all of them once ctx is done. It reports whether every run
+// succeeded.
+func runBatch(ctx context.Context, files []string, format string, pretty bool, jobs int, timeout time.Duration) bool {
+	cache := engine.NewNetworkCache()
+	errs := make([]error, len(files))
+	lines := make([]string, len(files))
+	run := func(i int) {
+		ctx := ctx
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		start := time.Now()
+		lines[i], errs[i] = runFile(ctx, cache, files[i], format, pretty)
+		if errs[i] == nil {
+			lines[i] += fmt.Sprintf(" (%v)", time.Since(start).Round(time.Millisecond))
+		}
+	}
+
+	sem := make(chan struct{}, max(jobs, 1))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i := range files {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			run(i)
+			mu.Lock()
+			defer mu.Unlock()
+			if errs[i] != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", files[i], errs[i])
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", files[i], lines[i])
+			}
+		})
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d inputs failed\n", failed, len(files))
+	}
+	return failed == 0
+}
+
+// runFile runs the input in path and writes its log beside it, returning a summary of
+// the run.
+func runFile(ctx context.Context, cache *engine.NetworkCache, path, format string, pretty bool) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+	var input engine.SimulationInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		return "", fmt.Errorf("invalid input JSON: %w", err)
+	}
+	tms, err := cache.NewTMS(input)
+	if err != nil {
+		return "", fmt.Errorf("simulation error: %w", err)
+	}
+	simLog, err := tms.RunContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("simulation error: %w", err)
+	}
+
+	var out bytes.Buffer
+	if format == "csv" {
+		err = engine.WriteCSV(simLog, &out)
+	} else {
+		enc := json.NewEncoder(&out)
+		if pretty {
+			enc.SetIndent("", "  ")
+		}
+		err = enc.Encode(simLog)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error writing output: %w", err)
+	}
+	dest := strings.TrimSuffix(path, filepath.Ext(path)) + ".log." + format
+	if err := os.WriteFile(dest, out.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("error writing output: %w", err)
+	}
+
+	return fmt.Sprintf("%d services, %.0f m run in %v s -> %s",
+		len(simLog.Summary.Services), simLog.Summary.TotalDistance, input.Meta.RunTime, dest), nil
+}
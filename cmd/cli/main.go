@@ -1,23 +1,72 @@
 // Command tms-engine reads a SimulationInput JSON from a file argument (or stdin),
-// runs the simulation, and writes the SimulationLog JSON to stdout.
+// runs the simulation, and writes the SimulationLog JSON to stdout, or to the file named
+// by -o, indented with -pretty. With -format csv the log is written as CSV instead, one
+// row per service per timestep; with -format ndjson it is streamed as newline-delimited
+// JSON, one log row per line as the run goes. With -validate the input is only checked,
+// every problem found being listed on stderr. With -dot the network is written as a
+// GraphViz DOT digraph instead of running.
+//
+// Given several files, a directory or a glob pattern, it runs each input as a batch,
+// up to -j at once, writing each log beside its input as <name>.log.json (or .log.csv)
+// and a summary line for each to stderr.
+//
+// The run is abandoned on SIGINT/SIGTERM or, if -timeout is set, once it has taken longer
+// than the given duration.
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/cxd309/tms-engine/internal/engine"
 )
 
 func main() {
-	var (
-		data []byte
-		err  error
-	)
+	timeout := flag.Duration("timeout", 0, "abandon the run after this long (e.g. 30s); 0 = no limit")
+	format := flag.String("format", "json", "output format: json, ndjson or csv")
+	validate := flag.Bool("validate", false, "check the input without running it")
+	dot := flag.Bool("dot", false, "write the network as a GraphViz DOT digraph without running")
+	jobs := flag.Int("j", 1, "with several inputs, how many to run at once")
+	output := flag.String("o", "", "write the output to this file rather than stdout")
+	pretty := flag.Bool("pretty", false, "indent the JSON output")
+	flag.Parse()
+	if *format != "json" && *format != "ndjson" && *format != "csv" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", *format)
+		os.Exit(2)
+	}
+	if *pretty && *format != "json" {
+		fmt.Fprintln(os.Stderr, "-pretty applies to -format json only")
+		os.Exit(2)
+	}
 
-	if len(os.Args) > 1 {
-		data, err = os.ReadFile(os.Args[1])
+	files, batch, err := inputFiles(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading input: %v\n", err)
+		os.Exit(1)
+	}
+	if batch {
+		if *validate || *dot || *output != "" || *format == "ndjson" {
+			fmt.Fprintln(os.Stderr, "-validate, -dot, -o and -format ndjson take a single input")
+			os.Exit(2)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if !runBatch(ctx, files, *format, *pretty, *jobs, *timeout) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	var data []byte
+	if len(files) > 0 {
+		data, err = os.ReadFile(files[0])
 	} else {
 		data, err = io.ReadAll(os.Stdin)
 	}
@@ -26,11 +75,113 @@ func main() {
 		os.Exit(1)
 	}
 
-	result, err := engine.RunJSON(string(data))
+	if *dot {
+		var input engine.SimulationInput
+		if err := json.Unmarshal(data, &input); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid input JSON: %v\n", err)
+			os.Exit(1)
+		}
+		g, err := engine.BuildGraph(input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "building graph: %v\n", err)
+			os.Exit(1)
+		}
+		if err := writeOutput(*output, g.ToDOT); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *validate {
+		errs := engine.ValidateJSON(string(data))
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "invalid input: %v\n", err)
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+		if tms, err := engine.NewTMSFromJSON(string(data)); err == nil {
+			for _, w := range tms.Warnings() {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+			}
+		}
+		fmt.Fprintln(os.Stderr, "input is valid")
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	if *format == "ndjson" {
+		var runErr error
+		err := writeOutput(*output, func(w io.Writer) error {
+			runErr = engine.RunJSONStreamContext(ctx, string(data), w)
+			return nil
+		})
+		if runErr != nil {
+			fmt.Fprintf(os.Stderr, "simulation error: %v\n", runErr)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *format == "csv" {
+		simLog, err := engine.RunInput(ctx, string(data))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "simulation error: %v\n", err)
+			os.Exit(1)
+		}
+		err = writeOutput(*output, func(w io.Writer) error { return engine.WriteCSV(simLog, w) })
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	result, err := engine.RunJSONContext(ctx, string(data))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "simulation error: %v\n", err)
 		os.Exit(1)
 	}
+	out := bytes.NewBufferString(result)
+	if *pretty {
+		out.Reset()
+		if err := json.Indent(out, []byte(result), "", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	out.WriteByte('\n')
+	if err := writeOutput(*output, func(w io.Writer) error { _, err := out.WriteTo(w); return err }); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
 
-	fmt.Println(result)
+// writeOutput calls write with the file at path, created afresh, or with stdout if path
+// is empty, and closes the file.
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path == "" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
@@ -0,0 +1,48 @@
+//go:build cgo
+
+// Command clib exposes the TMS engine through a C ABI, for use from other languages
+// (e.g. Python via cffi or ctypes). Build it as a shared library with
+//
+//	go build -buildmode=c-shared -o dist/libtms.so ./cmd/clib
+//
+// which also writes the C header dist/libtms.h. It exports:
+//
+//	char *RunSimulation(char *input);
+//	void FreeResult(char *result);
+//
+// RunSimulation takes a NUL-terminated JSON-encoded SimulationInput and returns the
+// JSON-encoded SimulationLog, exactly as the CLI prints it, or, if the run fails,
+// {"error": {...}} describing the failure (see engine.ErrorInfo).
+//
+// Memory ownership: the input remains the caller's, and is not retained after the call
+// returns. The result is allocated by the library with malloc and belongs to the caller,
+// who must release it with exactly one call to FreeResult, never with free from another
+// C runtime, and must not use it afterwards.
+package main
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"encoding/json"
+	"unsafe"
+
+	"github.com/cxd309/tms-engine/internal/engine"
+)
+
+//export RunSimulation
+func RunSimulation(input *C.char) *C.char {
+	result, err := engine.RunJSON(C.GoString(input))
+	if err != nil {
+		out, _ := json.Marshal(engine.RunResult{Error: engine.NewErrorInfo(err)})
+		result = string(out)
+	}
+	return C.CString(result)
+}
+
+//export FreeResult
+func FreeResult(result *C.char) {
+	C.free(unsafe.Pointer(result))
+}
+
+func main() {} // required for -buildmode=c-shared
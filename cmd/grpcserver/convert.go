@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	tmsv1 "github.com/cxd309/tms-engine/api/tms/v1"
+	"github.com/cxd309/tms-engine/internal/engine"
+)
+
+// newTMS builds a TMS from a SimulationInput message. The message is written out as the
+// JSON it mirrors and read back by the engine, so that it is checked, and its models
+// built, exactly as a JSON input is.
+func newTMS(in *tmsv1.SimulationInput, opts ...engine.RunOption) (*engine.TMS, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("encoding input: %w", err)
+	}
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("encoding input: %w", err)
+	}
+	jsonShapes(doc)
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encoding input: %w", err)
+	}
+	return engine.NewTMSFromJSON(string(data), opts...)
+}
+
+// jsonShapes rewrites doc, a SimulationInput message as protojson writes it, into the
+// shapes the JSON input takes where they differ: a vehicle given by ref as its name
+// alone, a route given by route_ref as the ID in its place, a route library entry as its
+// stops alone, and the seed as a number rather than a string.
+func jsonShapes(doc map[string]any) {
+	if meta, ok := doc["simulation_meta"].(map[string]any); ok {
+		if seed, ok := meta["seed"].(string); ok {
+			meta["seed"] = json.Number(seed)
+		}
+	}
+	if lib, ok := doc["vehicle_library"].(map[string]any); ok {
+		for name, v := range lib {
+			lib[name] = vehicleShape(v)
+		}
+	}
+	if lib, ok := doc["route_library"].(map[string]any); ok {
+		for id, r := range lib {
+			stops := r.(map[string]any)["stops"]
+			if stops == nil {
+				stops = []any{}
+			}
+			lib[id] = stops
+		}
+	}
+	for _, s := range list(doc["service_list"]) {
+		svc := s.(map[string]any)
+		if ref, ok := svc["route_ref"]; ok {
+			svc["route"] = ref
+			delete(svc, "route_ref")
+		}
+		if v, ok := svc["vehicle"]; ok {
+			svc["vehicle"] = vehicleShape(v)
+		}
+		for _, u := range list(svc["units"]) {
+			unit := u.(map[string]any)
+			if v, ok := unit["unit"]; ok {
+				unit["unit"] = vehicleShape(v)
+			}
+		}
+	}
+	for _, f := range list(doc["frequencies"]) {
+		freq := f.(map[string]any)
+		if v, ok := freq["vehicle"]; ok {
+			freq["vehicle"] = vehicleShape(v)
+		}
+	}
+}
+
+// vehicleShape returns v, a Vehicle message as protojson writes it, as the JSON input
+// takes it: its ref alone, if it has one.
+func vehicleShape(v any) any {
+	if ref, ok := v.(map[string]any)["ref"]; ok {
+		return ref
+	}
+	return v
+}
+
+// list returns v as a JSON array, or nil if it is not one.
+func list(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+// toMessage converts v, a value of the engine's output, to m, the message mirroring it,
+// by way of its JSON. A field m does not mirror is an error, not dropped.
+func toMessage(v any, m proto.Message) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding output: %w", err)
+	}
+	if err := protojson.Unmarshal(data, m); err != nil {
+		return fmt.Errorf("encoding output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Command grpcserver serves the engine over gRPC: the Simulation service defined in
+// api/tms/v1/tms.proto, whose RunSimulation RPC returns a run's log and whose
+// RunSimulationStream RPC streams its rows as they are computed, alongside the standard
+// grpc.health.v1.Health service.
+//
+// On SIGINT/SIGTERM it reports itself as not serving, stops accepting requests and waits
+// up to -grace for the runs in progress to finish, then cancels any still running. A run
+// taking longer than -timeout, if set, is abandoned.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	tmsv1 "github.com/cxd309/tms-engine/api/tms/v1"
+)
+
+func main() {
+	addr := flag.String("addr", ":50051", "address to listen on")
+	timeout := flag.Duration("timeout", 0, "abandon a run after this long (e.g. 30s); 0 = no limit")
+	grace := flag.Duration("grace", 30*time.Second, "on shutdown, how long to let runs in progress finish")
+	flag.Parse()
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error listening: %v\n", err)
+		os.Exit(1)
+	}
+	srv, healthSrv := newServer(*timeout)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(lis) }()
+	fmt.Fprintf(os.Stderr, "serving on %s\n", lis.Addr())
+
+	select {
+	case err := <-served:
+		fmt.Fprintf(os.Stderr, "error serving: %v\n", err)
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+	fmt.Fprintln(os.Stderr, "shutting down")
+	healthSrv.Shutdown()
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(*grace):
+		srv.Stop() // cancels the runs still in progress
+		<-stopped
+	}
+}
+
+// newServer returns a gRPC server with the Simulation and Health services registered,
+// and the Health service, reporting both it and Simulation as serving.
+func newServer(timeout time.Duration) (*grpc.Server, *health.Server) {
+	srv := grpc.NewServer()
+	tmsv1.RegisterSimulationServer(srv, &server{timeout: timeout})
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus(tmsv1.Simulation_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthSrv)
+	return srv, healthSrv
+}
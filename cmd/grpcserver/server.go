@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tmsv1 "github.com/cxd309/tms-engine/api/tms/v1"
+	"github.com/cxd309/tms-engine/internal/engine"
+)
+
+// server implements the Simulation service, running each request as a simulation of its
+// own.
+type server struct {
+	tmsv1.UnimplementedSimulationServer
+	// timeout, if set, abandons a run that takes longer.
+	timeout time.Duration
+}
+
+// runContext returns the context a run for a request with context ctx goes by.
+func (s *server) runContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout > 0 {
+		return context.WithTimeout(ctx, s.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// RunSimulation implements tmsv1.SimulationServer.
+func (s *server) RunSimulation(ctx context.Context, in *tmsv1.SimulationInput) (*tmsv1.SimulationLog, error) {
+	ctx, cancel := s.runContext(ctx)
+	defer cancel()
+	tms, err := newTMS(in)
+	if err != nil {
+		return nil, runError(err)
+	}
+	simLog, err := tms.RunContext(ctx)
+	if err != nil {
+		return nil, runError(err)
+	}
+	var out tmsv1.SimulationLog
+	if err := toMessage(simLog, &out); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &out, nil
+}
+
+// RunSimulationStream implements tmsv1.SimulationServer. The run is abandoned as soon as
+// a row cannot be sent.
+func (s *server) RunSimulationStream(in *tmsv1.SimulationInput, stream tmsv1.Simulation_RunSimulationStreamServer) error {
+	ctx, cancel := s.runContext(stream.Context())
+	defer cancel()
+	tms, err := newTMS(in)
+	if err != nil {
+		return runError(err)
+	}
+
+	var sendErr error
+	rows, errc := tms.RunStream(ctx)
+	for row := range rows {
+		if sendErr != nil {
+			continue
+		}
+		var out tmsv1.SimulationLogRow
+		if err := toMessage(row, &out); err != nil {
+			sendErr = status.Error(codes.Internal, err.Error())
+		} else {
+			sendErr = stream.Send(&out)
+		}
+		if sendErr != nil {
+			cancel()
+		}
+	}
+	runErr := <-errc
+	if sendErr != nil {
+		return sendErr
+	}
+	if runErr != nil {
+		return runError(runErr)
+	}
+	return nil
+}
+
+// runError returns the status for err, an error from the engine, with its ErrorInfo
+// attached: InvalidArgument for an input that does not validate, Canceled or
+// DeadlineExceeded for a run cut short, and Aborted for a run that failed part-way.
+func runError(err error) error {
+	info := engine.NewErrorInfo(err)
+	code := codes.Internal
+	switch info.Kind {
+	case engine.ErrorKindValidation:
+		code = codes.InvalidArgument
+	case engine.ErrorKindRouting, engine.ErrorKindKinematics, engine.ErrorKindDeadlock:
+		code = codes.Aborted
+	case engine.ErrorKindCancelled:
+		code = codes.Canceled
+		if errors.Is(err, context.DeadlineExceeded) {
+			code = codes.DeadlineExceeded
+		}
+	}
+	st := status.New(code, info.Message)
+	if detailed, err := st.WithDetails(&tmsv1.ErrorInfo{
+		Kind:       info.Kind,
+		ServiceId:  info.ServiceID,
+		ServiceIds: info.ServiceIDs,
+		EdgeId:     info.EdgeID,
+		Time:       info.Time,
+		Message:    info.Message,
+	}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
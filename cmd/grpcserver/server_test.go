@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	tmsv1 "github.com/cxd309/tms-engine/api/tms/v1"
+	"github.com/cxd309/tms-engine/internal/engine"
+)
+
+// dial starts a server on an in-memory listener and returns a connection to it, both
+// closed when the test ends.
+func dial(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv, _ := newServer(0)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// lineInput is a line A-B-C with two services over it, one with its route and vehicle
+// given by reference to the libraries, and its JSON equivalent.
+func lineInput(t *testing.T) (*tmsv1.SimulationInput, string) {
+	t.Helper()
+	kinem, err := structpb.NewStruct(map[string]any{"model": "constant", "v_max": 20, "a_acc": 0.5, "a_dcc": 0.7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vehicle := &tmsv1.Vehicle{Name: "emu", Length: 50, Kinematics: kinem}
+	in := &tmsv1.SimulationInput{
+		SimulationMeta: &tmsv1.SimulationMeta{SimulationId: "line", RunTime: 200, TimeStep: 1, Seed: 7},
+		GraphData: &tmsv1.GraphData{
+			Nodes: []*tmsv1.Node{{NodeId: "A"}, {NodeId: "B", Type: "station"}, {NodeId: "C"}},
+			Edges: []*tmsv1.Edge{
+				{EdgeId: "AB", U: "A", V: "B", Length: 800},
+				{EdgeId: "BC", U: "B", V: "C", Length: 600, SpeedLimit: proto.Float64(15)},
+			},
+		},
+		ServiceList: []*tmsv1.Service{
+			{ServiceId: "S1", InitialPosition: "A", Route: []*tmsv1.RouteStop{{NodeId: "B", MinDwell: 20}, {NodeId: "C"}}, Vehicle: vehicle},
+			{ServiceId: "S2", InitialPosition: "A", DepartureDelay: 60, RouteRef: "stopping", Vehicle: &tmsv1.Vehicle{Ref: "emu"}},
+		},
+		VehicleLibrary: map[string]*tmsv1.Vehicle{"emu": vehicle},
+		RouteLibrary:   map[string]*tmsv1.Route{"stopping": {Stops: []*tmsv1.RouteStop{{NodeId: "C", ScheduledArrival: proto.Float64(150)}}}},
+	}
+	emu := `{"name": "emu", "length": 50, "kinematics": {"model": "constant", "v_max": 20, "a_acc": 0.5, "a_dcc": 0.7}}`
+	return in, `{
+		"simulation_meta": {"simulation_id": "line", "run_time": 200, "time_step": 1, "seed": 7},
+		"graph_data": {
+			"nodes": [{"node_id": "A"}, {"node_id": "B", "type": "station"}, {"node_id": "C"}],
+			"edges": [
+				{"edge_id": "AB", "u": "A", "v": "B", "length": 800},
+				{"edge_id": "BC", "u": "B", "v": "C", "length": 600, "speed_limit": 15}
+			]
+		},
+		"service_list": [
+			{"service_id": "S1", "initial_position": "A", "route": [{"node_id": "B", "min_dwell": 20}, {"node_id": "C", "min_dwell": 0}], "vehicle": ` + emu + `},
+			{"service_id": "S2", "initial_position": "A", "departure_delay": 60, "route": "stopping", "vehicle": "emu"}
+		],
+		"vehicle_library": {"emu": ` + emu + `},
+		"route_library": {"stopping": [{"node_id": "C", "min_dwell": 0, "scheduled_arrival": 150}]}
+	}`
+}
+
+// TestRunSimulation checks that the RPCs return the log a run of the equivalent JSON
+// input gives, whole or row by row.
+func TestRunSimulation(t *testing.T) {
+	in, jsonInput := lineInput(t)
+	simLog, err := engine.RunInput(context.Background(), jsonInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want tmsv1.SimulationLog
+	if err := toMessage(simLog, &want); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(want.Journeys); n != 2 {
+		t.Fatalf("want log has %d journeys, want 2", n)
+	}
+
+	client := tmsv1.NewSimulationClient(dial(t))
+	got, err := client.RunSimulation(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, &want) {
+		t.Errorf("RunSimulation log differs from the JSON run's:\ngot  %v\nwant %v", got.Summary, want.Summary)
+	}
+
+	stream, err := client.RunSimulationStream(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []*tmsv1.SimulationLogRow
+	for {
+		row, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) != len(want.Output) {
+		t.Fatalf("streamed %d rows, want %d", len(rows), len(want.Output))
+	}
+	for i, row := range rows {
+		if !proto.Equal(row, want.Output[i]) {
+			t.Fatalf("row %d: got %v, want %v", i, row, want.Output[i])
+		}
+	}
+}
+
+// TestRunSimulationError checks that an input that does not validate fails the RPCs with
+// InvalidArgument and its ErrorInfo attached.
+func TestRunSimulationError(t *testing.T) {
+	in, _ := lineInput(t)
+	in.ServiceList[0].Route[1].NodeId = "Z"
+	client := tmsv1.NewSimulationClient(dial(t))
+
+	_, err := client.RunSimulation(context.Background(), in)
+	checkError(t, err, codes.InvalidArgument, engine.ErrorKindValidation)
+	stream, err := client.RunSimulationStream(context.Background(), in)
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	checkError(t, err, codes.InvalidArgument, engine.ErrorKindValidation)
+}
+
+// checkError fails the test unless err has the given code and an ErrorInfo of the given
+// kind.
+func checkError(t *testing.T, err error, code codes.Code, kind string) {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != code {
+		t.Fatalf("error %v: code %v, want %v", err, st.Code(), code)
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*tmsv1.ErrorInfo); ok {
+			if info.Kind != kind {
+				t.Errorf("error %v: kind %q, want %q", err, info.Kind, kind)
+			}
+			return
+		}
+	}
+	t.Errorf("error %v: no ErrorInfo attached", err)
+}
+
+// TestHealth checks that the server reports itself, and the Simulation service, as
+// serving.
+func TestHealth(t *testing.T) {
+	client := healthpb.NewHealthClient(dial(t))
+	for _, svc := range []string{"", tmsv1.Simulation_ServiceDesc.ServiceName} {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: svc})
+		if err != nil {
+			t.Fatalf("%q: %v", svc, err)
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("%q: status %v, want SERVING", svc, resp.Status)
+		}
+	}
+}
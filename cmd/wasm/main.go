@@ -3,31 +3,172 @@
 // Command wasm exposes the TMS engine to the browser via WebAssembly.
 // After loading, it registers a global JavaScript function:
 //
-//	runSimulation(jsonString) -> jsonString
+//	runSimulation(jsonString, [timeoutMs], [onProgress]) -> jsonString
 //
 // The input and output are JSON-encoded SimulationInput and SimulationLog
 // respectively, matching the same contract used by the CLI and Python wrapper.
+// If timeoutMs is given and positive, the run is abandoned with an error once it has
+// taken longer than that many milliseconds. If onProgress is a function it is called as
+// onProgress(curTime, runTime) after every timestep.
+//
+// It also registers
+//
+//	runSimulationResult(jsonString, [timeoutMs], [onProgress]) -> jsonString
+//
+// taking the same arguments, which always returns JSON: {"log": ...} holding the
+// SimulationLog, or {"error": {"kind": ..., "message": ..., ...}} describing the failure
+// (see engine.ErrorInfo).
+//
+// To drive the simulation frame by frame instead, for live animation, it registers
+//
+//	initSimulation(jsonString) -> handle
+//	stepSimulation(handle) -> jsonString | null
+//	disposeSimulation(handle)
+//
+// initSimulation builds a simulation and returns a numeric handle to it. Each call to
+// stepSimulation runs the next timestep and returns its SimulationLogRow, or null once
+// the run is complete. disposeSimulation releases the simulation; call it once done with
+// a handle, whether or not the run completed. Failures are returned as {"error": message}.
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"syscall/js"
+	"time"
 
 	"github.com/cxd309/tms-engine/internal/engine"
 )
 
 func main() {
 	js.Global().Set("runSimulation", js.FuncOf(runSimulation))
+	js.Global().Set("runSimulationResult", js.FuncOf(runSimulationResult))
+	js.Global().Set("initSimulation", js.FuncOf(initSimulation))
+	js.Global().Set("stepSimulation", js.FuncOf(stepSimulation))
+	js.Global().Set("disposeSimulation", js.FuncOf(disposeSimulation))
 	select {} // keep the WASM module alive until the page is closed
 }
 
+// simulations holds the simulations being stepped from JavaScript, by handle. JavaScript
+// calls arrive one at a time, so it needs no locking.
+var (
+	simulations = make(map[int]*engine.TMS)
+	nextHandle  = 1
+)
+
 func runSimulation(_ js.Value, args []js.Value) any {
 	if len(args) < 1 {
 		return map[string]any{"error": "no input provided"}
 	}
+	ctx, cancel, opts := runArgs(args)
+	defer cancel()
 
-	result, err := engine.RunJSON(args[0].String())
+	result, err := engine.RunJSONContext(ctx, args[0].String(), opts...)
 	if err != nil {
 		return map[string]any{"error": err.Error()}
 	}
 	return result
 }
+
+func runSimulationResult(_ js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		out, _ := json.Marshal(engine.RunResult{Error: &engine.ErrorInfo{
+			Kind:    engine.ErrorKindValidation,
+			Message: "no input provided",
+		}})
+		return string(out)
+	}
+	ctx, cancel, opts := runArgs(args)
+	defer cancel()
+	return engine.RunJSONResultContext(ctx, args[0].String(), opts...)
+}
+
+// runArgs reads the optional timeout and progress callback following the input.
+func runArgs(args []js.Value) (context.Context, context.CancelFunc, []engine.RunOption) {
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if len(args) > 1 && args[1].Type() == js.TypeNumber && args[1].Float() > 0 {
+		timeout := time.Duration(args[1].Float() * float64(time.Millisecond))
+		ctx = deadlineContext{Context: ctx, deadline: time.Now().Add(timeout)}
+	}
+
+	var opts []engine.RunOption
+	if len(args) > 2 && args[2].Type() == js.TypeFunction {
+		onProgress := args[2]
+		opts = append(opts, engine.WithProgress(func(curTime, runTime float64) {
+			onProgress.Invoke(curTime, runTime)
+		}))
+	}
+	return ctx, cancel, opts
+}
+
+// deadlineContext is a context that expires at deadline, found by reading the clock
+// whenever Err is called, as the engine does before each timestep. A run is a single
+// synchronous call from JavaScript, and timers only fire once control returns to the
+// browser's event loop, so the timer behind context.WithTimeout would never fire during
+// one. Done is never closed.
+type deadlineContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c deadlineContext) Deadline() (time.Time, bool) { return c.deadline, true }
+
+func (c deadlineContext) Err() error {
+	if !time.Now().Before(c.deadline) {
+		return context.DeadlineExceeded
+	}
+	return c.Context.Err()
+}
+
+func initSimulation(_ js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return map[string]any{"error": "no input provided"}
+	}
+	tms, err := engine.NewTMSFromJSON(args[0].String())
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	handle := nextHandle
+	nextHandle++
+	simulations[handle] = tms
+	return handle
+}
+
+func stepSimulation(_ js.Value, args []js.Value) any {
+	tms, err := simulation(args)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	row, ok, err := tms.Step()
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	if !ok {
+		return nil
+	}
+	out, err := json.Marshal(row)
+	if err != nil {
+		return map[string]any{"error": fmt.Sprintf("marshaling output: %v", err)}
+	}
+	return string(out)
+}
+
+func disposeSimulation(_ js.Value, args []js.Value) any {
+	if len(args) > 0 && args[0].Type() == js.TypeNumber {
+		delete(simulations, args[0].Int())
+	}
+	return nil
+}
+
+// simulation returns the simulation whose handle is the first of args.
+func simulation(args []js.Value) (*engine.TMS, error) {
+	if len(args) < 1 || args[0].Type() != js.TypeNumber {
+		return nil, fmt.Errorf("no simulation handle provided")
+	}
+	tms, ok := simulations[args[0].Int()]
+	if !ok {
+		return nil, fmt.Errorf("unknown simulation handle %d", args[0].Int())
+	}
+	return tms, nil
+}
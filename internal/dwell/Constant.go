@@ -0,0 +1,27 @@
+package dwell
+
+import "fmt"
+
+// ConstantModelName is the JSON discriminator string for the Constant model.
+const ConstantModelName = "constant"
+
+func init() { Register(ConstantModelName, parse[Constant]) }
+
+// ModelName returns ConstantModelName.
+func (c Constant) ModelName() string { return ConstantModelName }
+
+// Constant dwells for the same time at every call, whatever the passengers.
+//
+// JSON discriminator: "model": "constant"
+type Constant struct {
+	Dwell float64 `json:"dwell"` // seconds
+}
+
+func (c Constant) Validate() error {
+	if c.Dwell < 0 {
+		return fmt.Errorf("dwell %v must not be negative", c.Dwell)
+	}
+	return nil
+}
+
+func (c Constant) DwellTime(Context) float64 { return c.Dwell }
@@ -0,0 +1,31 @@
+// Package dwell defines the DwellModel interface for timing a service's calls at stops,
+// along with built-in implementations.
+//
+// Adding a new dwell model requires only implementing DwellModel and registering it
+// under its JSON discriminator with Register — neither the service package nor the
+// simulation engine needs to change.
+package dwell
+
+// DwellModel times a service's call at a stop. Times are in seconds.
+type DwellModel interface {
+	// DwellTime returns how long the service needs at the stop described by ctx before it
+	// may depart. A scheduled departure still holds the service back on top of this.
+	DwellTime(ctx Context) float64
+}
+
+// Context describes a call at a stop for a DwellModel to time.
+type Context struct {
+	Time     float64 // simulation time the dwell starts, seconds
+	MinDwell float64 // the stop's min_dwell, seconds
+	// Boarders and Alighters are the passengers getting on and off, the alighters no
+	// more than were on board; OnBoard is the load once they have.
+	Boarders  int
+	Alighters int
+	OnBoard   int
+	// DoorFlowRate is the stop's passengers per second through the doors; 0 if not given.
+	DoorFlowRate float64
+	// ScheduledArrival and ScheduledDeparture are the stop's timetabled times, on the
+	// first pass of a route only; nil if not timetabled.
+	ScheduledArrival   *float64
+	ScheduledDeparture *float64
+}
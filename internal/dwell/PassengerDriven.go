@@ -0,0 +1,37 @@
+package dwell
+
+import "fmt"
+
+// PassengerModelName is the JSON discriminator string for the PassengerDriven model.
+const PassengerModelName = "passenger"
+
+func init() { Register(PassengerModelName, parse[PassengerDriven]) }
+
+// ModelName returns PassengerModelName.
+func (p PassengerDriven) ModelName() string { return PassengerModelName }
+
+// PassengerDriven dwells for a fixed time, for the doors and despatch, plus a time per
+// passenger boarding and alighting. The stop's min_dwell and door_flow_rate are not used.
+//
+// JSON discriminator: "model": "passenger"
+type PassengerDriven struct {
+	Base        float64 `json:"base"`                   // seconds
+	PerBoarder  float64 `json:"per_boarder,omitempty"`  // seconds per passenger boarding
+	PerAlighter float64 `json:"per_alighter,omitempty"` // seconds per passenger alighting
+}
+
+func (p PassengerDriven) Validate() error {
+	switch {
+	case p.Base < 0:
+		return fmt.Errorf("base %v must not be negative", p.Base)
+	case p.PerBoarder < 0:
+		return fmt.Errorf("per_boarder %v must not be negative", p.PerBoarder)
+	case p.PerAlighter < 0:
+		return fmt.Errorf("per_alighter %v must not be negative", p.PerAlighter)
+	}
+	return nil
+}
+
+func (p PassengerDriven) DwellTime(ctx Context) float64 {
+	return p.Base + p.PerBoarder*float64(ctx.Boarders) + p.PerAlighter*float64(ctx.Alighters)
+}
@@ -0,0 +1,23 @@
+package dwell
+
+// ScheduledModelName is the JSON discriminator string for the Scheduled model.
+const ScheduledModelName = "scheduled"
+
+func init() { Register(ScheduledModelName, parse[Scheduled]) }
+
+// ModelName returns ScheduledModelName.
+func (s Scheduled) ModelName() string { return ScheduledModelName }
+
+// Scheduled dwells for the timetabled dwell, from the stop's scheduled arrival to its
+// scheduled departure, or the stop's min_dwell if that is longer or either time is not
+// given. Unlike the default, a late service does not shorten its dwell to recover time.
+//
+// JSON discriminator: "model": "scheduled"
+type Scheduled struct{}
+
+func (s Scheduled) DwellTime(ctx Context) float64 {
+	if ctx.ScheduledArrival == nil || ctx.ScheduledDeparture == nil {
+		return ctx.MinDwell
+	}
+	return max(ctx.MinDwell, *ctx.ScheduledDeparture-*ctx.ScheduledArrival)
+}
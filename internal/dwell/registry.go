@@ -0,0 +1,82 @@
+package dwell
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cxd309/tms-engine/internal/registry"
+)
+
+// Factory builds a model from its JSON parameters: the dwell_model object, including its
+// "model" discriminator.
+type Factory = registry.Factory[DwellModel]
+
+// Named is implemented by models that can name their JSON discriminator, so that they
+// can be written back out as JSON. All the built-in models implement it.
+type Named = registry.Named
+
+var models = registry.New[DwellModel]("dwell")
+
+// Register makes a model available under the JSON discriminator name. The built-in
+// models register themselves; others may be registered from an init function before any
+// input is read. Register panics if name is already registered or factory is nil.
+func Register(name string, factory Factory) {
+	models.Register(name, factory)
+}
+
+// Lookup returns the factory registered under name, if any.
+func Lookup(name string) (Factory, bool) {
+	return models.Lookup(name)
+}
+
+// parse is a Factory for models read straight from their JSON parameters, checked if the
+// model is a registry.Validator.
+func parse[M DwellModel](raw json.RawMessage) (DwellModel, error) {
+	return registry.Parse[DwellModel, M](raw)
+}
+
+// Spec holds a dwell model as given in the input: a JSON object naming the model in its
+// "model" field alongside the model's parameters.
+type Spec struct {
+	DwellModel
+}
+
+// UnmarshalJSON implements json.Unmarshaler for Spec, building the model registered
+// under the object's "model" discriminator.
+func (s *Spec) UnmarshalJSON(data []byte) error {
+	var disc struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal(data, &disc); err != nil {
+		return err
+	}
+	factory, ok := Lookup(disc.Model)
+	if !ok {
+		return fmt.Errorf("unknown dwell model %q", disc.Model)
+	}
+	m, err := factory(data)
+	if err != nil {
+		return fmt.Errorf("parsing %s dwell model: %w", disc.Model, err)
+	}
+	s.DwellModel = m
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler for Spec, writing the model with its "model"
+// discriminator. The model must implement Named.
+func (s Spec) MarshalJSON() ([]byte, error) {
+	named, ok := s.DwellModel.(Named)
+	if !ok {
+		return nil, fmt.Errorf("cannot marshal dwell model %T", s.DwellModel)
+	}
+	params, err := json.Marshal(s.DwellModel)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling dwell model: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(params, &fields); err != nil {
+		return nil, fmt.Errorf("marshalling dwell model: %w", err)
+	}
+	fields["model"], _ = json.Marshal(named.ModelName())
+	return json.Marshal(fields)
+}
@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// computeMaxAllowedDistance returns the maximum distance svc may travel without
+// entering any other service's safety envelope (minimal MA + vehicle length), or the
+// SafetyMargin behind it.
+//
+// Each other service's protected zone is traced back from its front across edge
+// boundaries (see occupiedZone) and projected onto svc's corridor to its next stop, so
+// conflicts are found wherever the two share track: on the same edge, ahead on the
+// path, or where the other service is still clearing a merge or diverge. With a
+// ConflictHorizon, the corridor ends that far ahead of svc's front. Only the services
+// the occupancy index has on the corridor have their zones traced. Under fixed-block
+// signalling, services are kept apart by signal sections instead, so the MA is unlimited.
+func (t *TMS) computeMaxAllowedDistance(svc *service.SimService, minMAs map[string]movementAuthority) (float64, error) {
+	if t.fixedBlock() {
+		return math.MaxFloat64, nil
+	}
+	ahead, err := t.corridor(svc)
+	if err != nil {
+		return 0, err
+	}
+	if h := t.meta.ConflictHorizon; h > 0 {
+		maps.DeleteFunc(ahead, func(_ graph.EdgeID, offset float64) bool { return offset >= h })
+	}
+
+	maxDist := math.Inf(1)
+	for _, other := range t.occupancy.on(slices.Collect(maps.Keys(ahead))) {
+		if other.ServiceID == svc.ServiceID || other.Finished() {
+			continue
+		}
+
+		zone, err := t.occupiedZone(other, minMAs[other.ServiceID]+t.meta.SafetyMargin)
+		if err != nil {
+			return 0, err
+		}
+		if other.CurrentPosition.DistanceAlongEdge == 0 {
+			// A front drawn up on a node is not in the zone, but nothing may pass it.
+			zone = append(zone, graph.Segment{Edge: other.CurrentPosition.Edge})
+		}
+		for _, seg := range zone {
+			offset, ok := ahead[seg.Edge]
+			if !ok || offset+seg.End <= 0 {
+				continue // not on our corridor, or behind or level with our front
+			}
+			// We must not enter the zone.
+			if allowed := offset + seg.Start; allowed < maxDist {
+				maxDist = allowed
+			}
+		}
+	}
+
+	if math.IsInf(maxDist, 1) {
+		return math.MaxFloat64, nil
+	}
+	return math.Max(0, maxDist), nil
+}
+
+// corridor returns, for each edge on svc's path to its next stop, the distance from
+// svc's front to the start of that edge. The current edge's start is behind the front,
+// so its offset is negative. Edges revisited later on the path keep their first offset.
+func (t *TMS) corridor(svc *service.SimService) (map[graph.EdgeID]float64, error) {
+	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
+	if err != nil {
+		return nil, err
+	}
+	path, err := t.pathAhead(svc)
+	if err != nil {
+		return nil, err
+	}
+	ahead := map[graph.EdgeID]float64{edge.ID: -svc.CurrentPosition.DistanceAlongEdge}
+	offset := edge.Length - svc.CurrentPosition.DistanceAlongEdge
+	for _, e := range path {
+		if _, seen := ahead[e.ID]; !seen {
+			ahead[e.ID] = offset
+		}
+		offset += e.Length
+	}
+	return ahead, nil
+}
+
+// occupiedZone returns the track protected by svc as segments: its body plus envelope
+// metres behind its rear, traced back from its front along its current edge and then its
+// trail of previous edges. The zone is cut short where the trail runs out. A front drawn
+// up at the very start of an edge has not yet entered it, so that edge is left out.
+func (t *TMS) occupiedZone(svc *service.SimService, envelope float64) ([]graph.Segment, error) {
+	remaining := svc.Vehicle.Length + envelope
+	edgeID := svc.CurrentPosition.Edge
+	end := svc.CurrentPosition.DistanceAlongEdge
+
+	var zone []graph.Segment
+	for i := len(svc.Trail); ; i-- {
+		start := math.Max(0, end-remaining)
+		if end > 0 || i == 0 {
+			zone = append(zone, graph.Segment{Edge: edgeID, Start: start, End: end})
+		}
+		remaining -= end - start
+		if remaining <= 0 || i == 0 {
+			return zone, nil
+		}
+		edgeID = svc.Trail[i-1]
+		e, err := t.graph.GetEdgeByID(edgeID)
+		if err != nil {
+			return nil, err
+		}
+		end = e.Length
+	}
+}
+
+// turnRound reverses svc where it stands, as at a terminus: its front moves to where its
+// rear was, on the reverse edge, and its trail becomes the reverses of the rest of the
+// track under its body. Every edge under it must be bidirectional.
+func (t *TMS) turnRound(svc *service.SimService) error {
+	zone, err := t.occupiedZone(svc, 0)
+	if err != nil {
+		return err
+	}
+	reversed := make([]graph.Edge, len(zone))
+	for i, seg := range zone {
+		if reversed[i], err = t.graph.GetReverseEdge(seg.Edge); err != nil {
+			return err
+		}
+	}
+	// The zone runs from front to rear, so the old front is now furthest behind.
+	rear := zone[len(zone)-1]
+	svc.CurrentPosition = graph.Position{
+		Edge:              reversed[len(zone)-1].ID,
+		DistanceAlongEdge: reversed[len(zone)-1].Length - rear.Start,
+	}
+	svc.Trail = nil
+	for _, e := range reversed[:len(zone)-1] {
+		svc.Trail = append(svc.Trail, e.ID)
+	}
+	t.occupancy.update(svc)
+	return nil
+}
+
+// recordTrail appends edge, which svc is leaving, to its trail and drops the oldest
+// edges no longer needed to trace its occupied zone, its length plus its braking
+// distance from top speed, or to set it back as far as its route may call for.
+func (t *TMS) recordTrail(svc *service.SimService, edge graph.Edge) error {
+	svc.Trail = append(svc.Trail, edge.ID)
+
+	m, err := t.motionModel(svc)
+	if err != nil {
+		return err
+	}
+	reach := svc.Vehicle.Length + m.BrakingDistance(m.VMax()) + svc.LongestSetBack()
+
+	covered := 0.0
+	for i := len(svc.Trail) - 1; i > 0; i-- {
+		e, err := t.graph.GetEdgeByID(svc.Trail[i])
+		if err != nil {
+			return err
+		}
+		if covered += e.Length; covered >= reach {
+			svc.Trail = svc.Trail[i:]
+			break
+		}
+	}
+	return nil
+}
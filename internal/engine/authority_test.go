@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// denseInput is a double-track line of 100 sections, each 1 km long, with services
+// setting off from either end every 30 seconds, run until the last has set off.
+func denseInput(tb testing.TB, services int) SimulationInput {
+	tb.Helper()
+	var nodes, edges, list []string
+	for i := range 101 {
+		nodes = append(nodes, fmt.Sprintf(`{"node_id": "N%d"}`, i))
+	}
+	for i := range 100 {
+		edges = append(edges,
+			fmt.Sprintf(`{"edge_id": "U%d", "u": "N%d", "v": "N%d", "length": 1000}`, i, i, i+1),
+			fmt.Sprintf(`{"edge_id": "D%d", "u": "N%d", "v": "N%d", "length": 1000}`, i, i+1, i))
+	}
+	for i := range services {
+		from, to := "N0", "N100"
+		if i%2 == 1 {
+			from, to = to, from
+		}
+		list = append(list, fmt.Sprintf(`{"service_id": "S%04d", "initial_position": %q, "departure_delay": %d, "route": [{"node_id": %q, "min_dwell": 0}], "vehicle": "VEHICLE"}`,
+			i, from, i/2*30, to))
+	}
+	return testInput(tb, fmt.Sprintf(`{
+		"simulation_meta": {"simulation_id": "dense", "run_time": %d, "time_step": 1},
+		"graph_data": {"nodes": [%s], "edges": [%s]},
+		"service_list": [%s]
+	}`, services/2*30, strings.Join(nodes, ", "), strings.Join(edges, ", "), strings.Join(list, ", ")))
+}
+
+// busyLineInput is the line of denseInput with services already under way over the
+// whole of it, half in each direction, evenly spaced and cruising at 20 m/s.
+func busyLineInput(tb testing.TB, services int) SimulationInput {
+	tb.Helper()
+	input := denseInput(tb, 1)
+	template := input.ServiceList[0]
+	input.ServiceList = nil
+	spacing := 2 * 100_000 / float64(services)
+	for i := range services {
+		pos := spacing/2 + float64(i/2)*spacing
+		edge, to := fmt.Sprintf("U%d", int(pos/1000)), graph.NodeID("N100")
+		if i%2 == 1 {
+			edge, to = fmt.Sprintf("D%d", int(pos/1000)), "N0"
+		}
+		svc := template
+		svc.ServiceID = fmt.Sprintf("S%04d", i)
+		svc.InitialPosition = ""
+		svc.StartPosition = &graph.Position{Edge: edge, DistanceAlongEdge: math.Mod(pos, 1000)}
+		svc.InitialVelocity = 20
+		svc.Route = []service.RouteStop{{NodeID: to}}
+		input.ServiceList = append(input.ServiceList, svc)
+	}
+	input.Meta.RunTime = 100_000
+	return input
+}
+
+// BenchmarkConflictHorizon times a timestep of a line busy with services, with and
+// without a conflict horizon.
+func BenchmarkConflictHorizon(b *testing.B) {
+	for _, services := range []int{100, 400} {
+		for _, horizon := range []float64{0, 2000} {
+			b.Run(fmt.Sprint(services, "/", horizon), func(b *testing.B) {
+				input := busyLineInput(b, services)
+				input.Meta.ConflictHorizon = horizon
+				tms, err := NewTMS(input)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for b.Loop() {
+					if _, ok, err := tms.Step(); err != nil || !ok {
+						b.Fatalf("step: %v, ok %v", err, ok)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestConflictHorizon checks that a conflict horizon longer than any braking distance
+// leaves the outcome of each safety feature's scenarios unchanged: services following
+// one another, passing on single track, crossing at a junction and converging on a
+// merge.
+func TestConflictHorizon(t *testing.T) {
+	scenarios := map[string]SimulationInput{
+		"following":    denseInput(t, 10),
+		"passing loop": testInput(t, singleTrackInput(400, true)),
+		"junction":     testInput(t, crossingInput(500, 400, 10)),
+	}
+	for _, cm := range []float64{200, 400} {
+		for _, delay := range []float64{0, 4, 8, 12} {
+			scenarios[fmt.Sprint("merge ", cm, "/", delay)] = testInput(t, yMergeInput(cm, delay))
+		}
+	}
+	for name, input := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			want, err := runLog(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			input.Meta.ConflictHorizon = 1000
+			got, err := runLog(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Error("output differs with the conflict horizon")
+			}
+		})
+	}
+}
+
+// runLog runs input to completion and returns its log as JSON, less its meta.
+func runLog(input SimulationInput) (string, error) {
+	tms, err := NewTMS(input)
+	if err != nil {
+		return "", err
+	}
+	log, err := tms.Run()
+	if err != nil {
+		return "", err
+	}
+	log.Meta = SimulationMeta{}
+	out, err := json.Marshal(log)
+	return string(out), err
+}
@@ -0,0 +1,190 @@
+package engine
+
+import (
+	"math"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// occupiedBlocks returns the blocks svc's body currently lies in.
+func (t *TMS) occupiedBlocks(svc *service.SimService) (map[graph.BlockID]bool, error) {
+	zone, err := t.occupiedZone(svc, 0)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make(map[graph.BlockID]bool)
+	for _, seg := range zone {
+		if b, ok := t.graph.BlockOf(seg.Edge); ok {
+			blocks[b] = true
+		}
+	}
+	return blocks, nil
+}
+
+// updateBlockHolds refreshes the blocks svc holds. A service holds the blocks its body
+// lies in, plus, while running ahead (see runningAhead), those ahead on its path that
+// start within its hold reach (see holdReach), since it could not be sure of stopping
+// short of them by the next timestep, up to the first that is closed to it (see
+// blockHeldAgainst). A front drawn up at the very start of its current edge has that
+// edge's block ahead of it. A block already held by another service is not taken over;
+// holds svc no longer needs are released.
+func (t *TMS) updateBlockHolds(svc *service.SimService) error {
+	if !t.graph.HasBlocks() {
+		return nil
+	}
+	claimed, err := t.occupiedBlocks(svc)
+	if err != nil {
+		return err
+	}
+	if runningAhead(svc) {
+		edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
+		if err != nil {
+			return err
+		}
+		path, err := t.pathAhead(svc)
+		if err != nil {
+			return err
+		}
+		m, err := t.motionModel(svc)
+		if err != nil {
+			return err
+		}
+		reach := holdReach(svc, m, svc.TopSpeed(), t.meta.TimeStep)
+		offset := edge.Length - svc.CurrentPosition.DistanceAlongEdge
+		if svc.CurrentPosition.DistanceAlongEdge == 0 {
+			path, offset = append([]graph.Edge{edge}, path...), 0
+		}
+		var ahead []graph.BlockID
+		for _, e := range path {
+			if offset >= reach {
+				break
+			}
+			if b, ok := t.graph.BlockOf(e.ID); ok && !claimed[b] {
+				ahead = append(ahead, b)
+			}
+			offset += e.Length
+		}
+		occupied, err := t.othersOccupiedBlocks(svc, ahead)
+		if err != nil {
+			return err
+		}
+		for _, b := range ahead {
+			if t.blockHeldAgainst(svc, b, occupied) {
+				break
+			}
+			claimed[b] = true
+		}
+	}
+
+	for b, holder := range t.blockHolds {
+		if holder == svc.ServiceID && !claimed[b] {
+			delete(t.blockHolds, b)
+		}
+	}
+	for b := range claimed {
+		if _, held := t.blockHolds[b]; !held {
+			t.blockHolds[b] = svc.ServiceID
+		}
+	}
+	return nil
+}
+
+// blockHeldAgainst reports whether block b is closed to svc: held by another service, or
+// occupied by one that has not yet claimed it.
+func (t *TMS) blockHeldAgainst(svc *service.SimService, b graph.BlockID, occupied map[graph.BlockID]bool) bool {
+	if holder, ok := t.blockHolds[b]; ok {
+		return holder != svc.ServiceID
+	}
+	return occupied[b]
+}
+
+// othersOccupiedBlocks returns the blocks the bodies of services other than svc lie in,
+// of those services the occupancy index has on any edge of the given blocks. Those
+// blocks are marked occupied or not; others may be marked too.
+func (t *TMS) othersOccupiedBlocks(svc *service.SimService, blocks []graph.BlockID) (map[graph.BlockID]bool, error) {
+	var edges []graph.EdgeID
+	for _, b := range blocks {
+		edges = append(edges, t.graph.BlockEdges(b)...)
+	}
+	occupied := make(map[graph.BlockID]bool)
+	for _, other := range t.occupancy.on(edges) {
+		if other == svc || other.Finished() {
+			continue
+		}
+		blocks, err := t.occupiedBlocks(other)
+		if err != nil {
+			return nil, err
+		}
+		for b := range blocks {
+			occupied[b] = true
+		}
+	}
+	return occupied, nil
+}
+
+// distanceToHeldBlock returns the distance from svc's front to the start of the first
+// block on its path to its next stop that is closed to it (see blockHeldAgainst), or +Inf
+// if there is none. Blocks svc's own body lies in are never closed to it, so services may
+// follow one another through a block under the usual movement authority. A front drawn
+// up at the very start of its current edge has yet to enter that edge's block.
+func (t *TMS) distanceToHeldBlock(svc *service.SimService) (float64, error) {
+	if !t.graph.HasBlocks() {
+		return math.Inf(1), nil
+	}
+	own, err := t.occupiedBlocks(svc)
+	if err != nil {
+		return 0, err
+	}
+
+	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
+	if err != nil {
+		return 0, err
+	}
+	path, err := t.pathAhead(svc)
+	if err != nil {
+		return 0, err
+	}
+	offset := edge.Length - svc.CurrentPosition.DistanceAlongEdge
+	if svc.CurrentPosition.DistanceAlongEdge == 0 {
+		path, offset = append([]graph.Edge{edge}, path...), 0
+	}
+	var blocks []graph.BlockID
+	for _, e := range path {
+		if b, ok := t.graph.BlockOf(e.ID); ok && !own[b] {
+			blocks = append(blocks, b)
+		}
+	}
+	occupied, err := t.othersOccupiedBlocks(svc, blocks)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range path {
+		if b, ok := t.graph.BlockOf(e.ID); ok && !own[b] && t.blockHeldAgainst(svc, b, occupied) {
+			return offset, nil
+		}
+		offset += e.Length
+	}
+	return math.Inf(1), nil
+}
+
+// releaseHolds drops every block hold, junction lock and merge lock svc has, and its
+// entries in the occupancy index, once it has left the simulation.
+func (t *TMS) releaseHolds(svc *service.SimService) {
+	t.occupancy.update(svc)
+	for b, holder := range t.blockHolds {
+		if holder == svc.ServiceID {
+			delete(t.blockHolds, b)
+		}
+	}
+	for n, lock := range t.junctionLocks {
+		if lock.holder == svc.ServiceID {
+			delete(t.junctionLocks, n)
+		}
+	}
+	for key, lock := range t.mergeLocks {
+		if lock.holder == svc.ServiceID {
+			delete(t.mergeLocks, key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// singleTrackInput is a line A-B-C-D of single track, each section length metres long,
+// with a loop of two parallel tracks between B and C if loop is set, and services S1 from
+// A to D and S2 from D to A, which keeps to the other track of the loop.
+func singleTrackInput(length float64, loop bool) string {
+	edges := fmt.Sprintf(`{"edge_id": "BC", "u": "B", "v": "C", "length": %v, "bidirectional": true}`, length)
+	track := ""
+	if loop {
+		edges += fmt.Sprintf(`, {"edge_id": "BC2", "u": "B", "v": "C", "length": %v, "bidirectional": true}`, length)
+		track = `, "track": ["BC2:reverse"]`
+	}
+	return fmt.Sprintf(`{
+		"simulation_meta": {"simulation_id": "single-track", "run_time": 600, "time_step": 1},
+		"graph_data": {
+			"nodes": [{"node_id": "A"}, {"node_id": "B"}, {"node_id": "C"}, {"node_id": "D"}],
+			"edges": [
+				{"edge_id": "AB", "u": "A", "v": "B", "length": %[1]v, "bidirectional": true},
+				%[2]s,
+				{"edge_id": "CD", "u": "C", "v": "D", "length": %[1]v, "bidirectional": true}
+			]
+		},
+		"service_list": [
+			{"service_id": "S1", "initial_position": "A", "route": [{"node_id": "D", "min_dwell": 0}], "vehicle": "VEHICLE"},
+			{"service_id": "S2", "initial_position": "D", "route": [{"node_id": "A", "min_dwell": 0%[3]s}], "vehicle": "VEHICLE"}
+		]
+	}`, length, edges, track)
+}
+
+// lengths are section lengths for the single-track tests. 306.25 m is run in exactly 35
+// s from a stand, so that a front comes to rest on a node at the end of a timestep.
+var lengths = []float64{200, 306.25, 400, 1000}
+
+// TestBlocksHeadOn runs two services towards each other over single track with nowhere
+// to pass, and checks they never meet: the run must end in deadlock instead.
+func TestBlocksHeadOn(t *testing.T) {
+	for _, length := range lengths {
+		t.Run(fmt.Sprint(length), func(t *testing.T) {
+			tms := newTestTMS(t, singleTrackInput(length, false))
+			err := runChecked(tms, func(row SimulationLogRow) { checkNoCollision(t, tms, row.Timestamp) })
+			var deadlock *DeadlockError
+			if !errors.As(err, &deadlock) {
+				t.Fatalf("run ended with %v, want a deadlock", err)
+			}
+		})
+	}
+}
+
+// TestBlocksPassingLoop checks that two services running towards each other over single
+// track pass at a loop without meeting, and both reach their destinations.
+func TestBlocksPassingLoop(t *testing.T) {
+	for _, length := range lengths {
+		t.Run(fmt.Sprint(length), func(t *testing.T) {
+			tms := newTestTMS(t, singleTrackInput(length, true))
+			if err := runChecked(tms, func(row SimulationLogRow) { checkNoCollision(t, tms, row.Timestamp) }); err != nil {
+				t.Fatal(err)
+			}
+			for _, svc := range tms.services {
+				if !svc.Finished() {
+					t.Errorf("%s did not finish: %s at %v", svc.ServiceID, svc.State, svc.CurrentPosition)
+				}
+			}
+		})
+	}
+}
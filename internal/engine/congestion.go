@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"math"
+	"slices"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// minCongestedSpeed is the least fraction of its free speed a congested edge slows
+// services to, so that a full edge still empties rather than jamming for good.
+const minCongestedSpeed = 0.1
+
+// congestedLimit returns the speed limit on edge for svc, given limit, its free-flow
+// limit, when the simulation has Congestion and the edge a Capacity: the free speed
+// falls linearly with the number of other services whose bodies lie on the edge, of
+// those the occupancy index has there, to minCongestedSpeed of it once they reach the
+// capacity. The free speed is limit or, where that is unlimited, svc's top speed.
+func (t *TMS) congestedLimit(svc *service.SimService, edge graph.Edge, limit float64) (float64, error) {
+	if !t.meta.Congestion || edge.Capacity == 0 {
+		return limit, nil
+	}
+	others := 0
+	for _, other := range t.occupancy.on([]graph.EdgeID{edge.ID}) {
+		if other == svc || other.Finished() {
+			continue
+		}
+		zone, err := t.occupiedZone(other, 0)
+		if err != nil {
+			return 0, err
+		}
+		if slices.ContainsFunc(zone, func(seg graph.Segment) bool { return seg.Edge == edge.ID }) {
+			others++
+		}
+	}
+	if others == 0 {
+		return limit, nil
+	}
+	free := math.Min(limit, svc.TopSpeed())
+	return free * math.Max(minCongestedSpeed, 1-float64(others)/float64(edge.Capacity)), nil
+}
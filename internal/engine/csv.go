@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// csvHeader names the columns WriteCSV emits.
+var csvHeader = []string{
+	"timestamp", "service_id", "edge", "distance_along_edge", "velocity", "state",
+	"next_stop", "remaining_dwell",
+}
+
+// WriteCSV writes log to w as CSV, one row per service per timestep under a header row.
+// Floats are written in their shortest exact form, so the same log always gives the
+// same bytes.
+func WriteCSV(log SimulationLog, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("writing CSV: %w", err)
+	}
+	for _, row := range log.Output {
+		for _, sl := range row.ServiceLogs {
+			record := []string{
+				formatFloat(row.Timestamp),
+				string(sl.ServiceID),
+				string(sl.CurrentPosition.Edge),
+				formatFloat(sl.CurrentPosition.DistanceAlongEdge),
+				formatFloat(sl.Velocity),
+				string(sl.State),
+				string(sl.NextStop),
+				formatFloat(sl.RemainingDwell),
+			}
+			if err := cw.Write(record); err != nil {
+				return fmt.Errorf("writing CSV: %w", err)
+			}
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("writing CSV: %w", err)
+	}
+	return nil
+}
+
+// formatFloat formats f in the shortest form that parses back to f exactly.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
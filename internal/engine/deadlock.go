@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// deadlockSteps is how many consecutive timesteps every service must be stuck before the
+// run is declared deadlocked.
+const deadlockSteps = 5
+
+// checkDeadlock counts the consecutive timesteps in which no service has made progress
+// and fails once there have been deadlockSteps of them. stuck lists the services granted
+// no movement this timestep; progress is set if any other service moved, dwelt, awaited
+// departure or was held, or an event was in effect, as any of these may yet free them.
+func (t *TMS) checkDeadlock(stuck []*service.SimService, progress bool) error {
+	if progress || len(stuck) == 0 {
+		t.stalled = 0
+		return nil
+	}
+	if t.stalled++; t.stalled < deadlockSteps {
+		return nil
+	}
+	parts := make([]string, len(stuck))
+	ids := make([]service.ServiceID, len(stuck))
+	for i, svc := range stuck {
+		ids[i] = svc.ServiceID
+		where, err := t.blockedAt(svc)
+		if err != nil {
+			return routingError(svc, fmt.Errorf("service %q deadlock check: %w", svc.ServiceID, err))
+		}
+		parts[i] = fmt.Sprintf("%q %s", svc.ServiceID, where)
+	}
+	return &DeadlockError{ServiceIDs: ids, Err: fmt.Errorf("deadlock: no service has moved for %d timesteps: %s",
+		t.stalled, strings.Join(parts, "; "))}
+}
+
+// blockedAt describes where svc is stuck: short of the edge it is drawn up at the start
+// of, or on its current edge, short of the next edge on its path if it has reached the
+// end of it.
+func (t *TMS) blockedAt(svc *service.SimService) (string, error) {
+	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case svc.CurrentPosition.DistanceAlongEdge == 0:
+		return fmt.Sprintf("short of %s", edge.ID), nil
+	case svc.CurrentPosition.DistanceAlongEdge < edge.Length:
+		return fmt.Sprintf("on %s", edge.ID), nil
+	}
+	path, err := t.pathAhead(svc)
+	if err != nil {
+		return "", err
+	}
+	if len(path) == 0 {
+		return fmt.Sprintf("on %s", edge.ID), nil
+	}
+	return fmt.Sprintf("on %s short of %s", edge.ID, path[0].ID), nil
+}
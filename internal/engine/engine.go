@@ -8,135 +8,661 @@
 //  2. Motion pass - every service proposes its desired movement, has that
 //     proposal trimmed by the MA record from pass 1 and any edge speed limits,
 //     then updates its position, velocity, and state accordingly.
+//
+// Services are processed, and logged, in ascending ServiceID order, so results do not
+// depend on the order of the input service list.
 package engine
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
+	"slices"
+	"strings"
 
 	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/kinematics"
 	"github.com/cxd309/tms-engine/internal/service"
 )
 
 // NewTMS constructs a TMS from a SimulationInput, building the graph and
 // placing each service at its initial position.
 func NewTMS(input SimulationInput) (*TMS, error) {
-	g, err := graph.NewGraph(input.GraphData)
+	t, errs := build(input)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return t, nil
+}
+
+// NewTMSWithGraph is NewTMS for services on g, a graph already built with
+// graph.NewGraph, so that many runs over the same network build it only once. g's
+// speed limits are taken as m/s whatever meta's SpeedUnit. A TMS only reads its graph,
+// and its cache of shortest paths carries over from run to run, but g must not be
+// changed while any TMS built on it remains in use; Freeze it to make sure. Only a
+// frozen g may be shared by TMSs running in different goroutines.
+func NewTMSWithGraph(meta SimulationMeta, g *graph.Graph, services []service.Service) (*TMS, error) {
+	var errs []error
+	for _, err := range checkMeta(meta) {
+		errs = append(errs, invalid("", "", err))
+	}
+	input := SimulationInput{Meta: meta, ServiceList: services}.inMetresPerSecond()
+	t, errs := buildOn(input, g, errs)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return t, nil
+}
+
+// BuildGraph builds the network of input as NewTMS does, with its speed limits in m/s,
+// without checking the rest of input.
+func BuildGraph(input SimulationInput) (*graph.Graph, error) {
+	return graph.NewGraph(input.inMetresPerSecond().GraphData)
+}
+
+// Validate makes every check NewTMS makes on input, without running the simulation, and
+// returns all the problems found rather than only the first; nil means input is ready
+// to run. Problems that stop later checks from making sense, such as a graph that
+// cannot be built, end the checking early.
+func Validate(input SimulationInput) []error {
+	_, errs := build(input)
+	return errs
+}
+
+// ValidateJSON is Validate for a JSON-encoded SimulationInput.
+func ValidateJSON(jsonInput string) []error {
+	var input SimulationInput
+	if err := json.Unmarshal([]byte(jsonInput), &input); err != nil {
+		return []error{invalid("", "", fmt.Errorf("invalid input JSON: %w", err))}
+	}
+	return Validate(input)
+}
+
+// build constructs a TMS from input as NewTMS does, carrying on past problems for as long
+// as later checks still make sense, and returns every problem found in the order met.
+func build(input SimulationInput) (*TMS, []error) {
+	return buildWith(input, graph.NewGraph)
+}
+
+// buildWith is build, building input's network with newGraph.
+func buildWith(input SimulationInput, newGraph func(graph.GraphData) (*graph.Graph, error)) (*TMS, []error) {
+	var errs []error
+	for _, err := range checkMeta(input.Meta) {
+		errs = append(errs, invalid("", "", err))
+	}
+	input, inputErrs := input.resolved()
+	errs = append(errs, inputErrs...)
+	input = input.inMetresPerSecond()
+
+	g, err := newGraph(input.GraphData)
 	if err != nil {
-		return nil, fmt.Errorf("building graph: %w", err)
+		return nil, append(errs, invalid("", "", fmt.Errorf("building graph: %w", err)))
 	}
+	return buildOn(input, g, errs)
+}
 
-	services := make([]*service.SimService, 0, len(input.ServiceList))
+// buildOn carries on build for input, already in m/s, on the graph g built from its
+// GraphData, after the problems errs already found. The TMS only reads g, so TMSs built
+// for runs over the same network can share it.
+func buildOn(input SimulationInput, g *graph.Graph, errs []error) (*TMS, []error) {
+	input.ServiceList = slices.Clone(input.ServiceList)
+	unplaced := make(map[service.ServiceID]bool)
+	for i := range input.ServiceList {
+		svc := &input.ServiceList[i]
+		if err := resolveStart(g, svc); err != nil {
+			errs = append(errs, invalid(svc.ServiceID, svc.StartPosition.Edge, fmt.Errorf("service %q start position: %w", svc.ServiceID, err)))
+			unplaced[svc.ServiceID] = true
+		}
+	}
+
+	// Check the network and every leg of every journey up front, including those of
+	// portions divided off along the way, reporting all problems together, rather than
+	// discovering a broken leg mid-run.
+	defs := slices.Clone(input.ServiceList)
 	for _, svc := range input.ServiceList {
-		firstStop, _, err := service.GetFirstStop(svc)
-		if err != nil {
-			return nil, fmt.Errorf("service %q: %w", svc.ServiceID, err)
+		defs = append(defs, svc.Portions()...)
+	}
+	routes := make(map[string][]graph.NodeID, len(defs))
+	seen := make(map[service.ServiceID]bool, len(defs))
+	for i, svc := range defs {
+		if seen[svc.ServiceID] {
+			errs = append(errs, invalid(svc.ServiceID, "", fmt.Errorf("duplicate service %q", svc.ServiceID)))
+			continue
 		}
-		initialPos, err := g.GetPathStartPosition(svc.InitialPosition, firstStop)
+		seen[svc.ServiceID] = true
+		if unplaced[svc.ServiceID] {
+			continue
+		}
+		if i >= len(input.ServiceList) && len(svc.Route) == 0 {
+			// A portion that terminates where it divides.
+			routes["service "+svc.ServiceID] = []graph.NodeID{svc.InitialPosition}
+			continue
+		}
+		if err := checkTrack(g, svc); err != nil {
+			errs = append(errs, invalid(svc.ServiceID, "", fmt.Errorf("service %q: %w", svc.ServiceID, err)))
+		}
+		waypoints, err := svc.Waypoints()
 		if err != nil {
-			return nil, fmt.Errorf("service %q initial position: %w", svc.ServiceID, err)
+			errs = append(errs, invalid(svc.ServiceID, "", fmt.Errorf("service %q: %w", svc.ServiceID, err)))
+			continue
 		}
-		simSvc, err := service.NewSimService(svc, initialPos)
+		routes["service "+svc.ServiceID] = waypoints
+	}
+	if err := g.Validate(routes); err != nil {
+		errs = append(errs, invalid("", "", fmt.Errorf("invalid network:\n%w", err)))
+	}
+	if err := checkPortions(defs); err != nil {
+		errs = append(errs, invalid("", "", fmt.Errorf("invalid portion working:\n%w", err)))
+	}
+	if len(errs) > 0 {
+		// Services cannot be placed on a network that does not hold their routes.
+		return nil, errs
+	}
+
+	rng := newRand(input.Meta.Seed)
+	services := make([]*service.SimService, 0, len(input.ServiceList))
+	for _, svc := range input.ServiceList {
+		svc.DepartureDelay = draw(rng, input.Meta.DepartureJitter, svc.DepartureDelay)
+		simSvc, err := service.NewSimService(svc, graph.Position{})
 		if err != nil {
-			return nil, fmt.Errorf("creating service %q: %w", svc.ServiceID, err)
+			errs = append(errs, invalid(svc.ServiceID, "", fmt.Errorf("creating service %q: %w", svc.ServiceID, err)))
+			continue
 		}
 		services = append(services, simSvc)
 	}
+	slices.SortFunc(services, func(a, b *service.SimService) int {
+		return strings.Compare(a.ServiceID, b.ServiceID)
+	})
+
+	t := &TMS{
+		meta:          input.Meta,
+		graph:         g,
+		curTime:       0,
+		blockHolds:    make(map[graph.BlockID]service.ServiceID),
+		junctionLocks: make(map[graph.NodeID]junctionLock),
+		mergeLocks:    make(map[mergeKey]junctionLock),
+		entries:       make(map[graph.EdgeID]edgeEntry),
+		logged:        make(map[service.ServiceID]service.ServiceLog),
+		occupancy:     newOccupancy(),
+		separations:   make(map[[2]service.ServiceID]Separation),
+		rng:           rng,
+	}
+	// Place each service at the start of its first edge, unless it has a StartPosition.
+	// Services starting at the same node spread out over any parallel first edges, each
+	// seeing only those placed before it.
+	for _, svc := range services {
+		if svc.StartPosition == nil {
+			svc.PassNode(svc.InitialPosition)
+			edge, err := t.nextEdge(svc, svc.InitialPosition)
+			if err != nil {
+				errs = append(errs, invalid(svc.ServiceID, "", fmt.Errorf("service %q initial position: %w", svc.ServiceID, err)))
+				continue
+			}
+			svc.CurrentPosition = graph.Position{Edge: edge.ID, DistanceAlongEdge: 0}
+		}
+		if err := t.checkInitialVelocity(svc); err != nil {
+			errs = append(errs, invalid(svc.ServiceID, svc.CurrentPosition.Edge, fmt.Errorf("service %q: %w", svc.ServiceID, err)))
+			continue
+		}
+		t.services = append(t.services, svc)
+		t.occupancy.update(svc)
+		t.jitterDwells(svc)
+	}
+	t.order = slices.Clone(t.services)
+	slices.SortStableFunc(t.order, byPriority)
 
-	return &TMS{
-		meta:     input.Meta,
-		graph:    g,
-		services: services,
-		curTime:  0,
-	}, nil
+	for i, ev := range input.Events {
+		if err := t.checkEvent(ev); err != nil {
+			errs = append(errs, invalid(ev.ServiceID, ev.EdgeID, fmt.Errorf("event %d (%s): %w", i, ev.Type, err)))
+		}
+	}
+	errs = append(errs, t.addTSRs(input.TSRs)...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	t.events = slices.Clone(input.Events)
+	slices.SortStableFunc(t.events, func(a, b SimEvent) int {
+		return cmp.Compare(a.Time, b.Time)
+	})
+	return t, nil
+}
+
+// maxTimesteps is the most timesteps a run may take, well beyond any sensible run; more
+// means time_step is mistakenly tiny.
+const maxTimesteps = 100_000_000
+
+// checkMeta returns every problem with the simulation-wide parameters in meta.
+func checkMeta(meta SimulationMeta) []error {
+	var errs []error
+	switch {
+	case meta.TimeStep <= 0:
+		errs = append(errs, fmt.Errorf("time_step %v must be positive", meta.TimeStep))
+	case meta.RunTime < 0:
+		errs = append(errs, fmt.Errorf("run_time %v must not be negative", meta.RunTime))
+	case meta.RunTime/meta.TimeStep > maxTimesteps:
+		errs = append(errs, fmt.Errorf("run_time %v at time_step %v is more than %v timesteps", meta.RunTime, meta.TimeStep, float64(maxTimesteps)))
+	}
+	if a := meta.Adhesion; a != nil && (*a <= 0 || *a > 1) {
+		errs = append(errs, fmt.Errorf("adhesion %v must be in (0, 1]", *a))
+	}
+	if meta.MinHeadway < 0 {
+		errs = append(errs, fmt.Errorf("min_headway %v must not be negative", meta.MinHeadway))
+	}
+	if meta.SafetyMargin < 0 {
+		errs = append(errs, fmt.Errorf("safety_margin %v must not be negative", meta.SafetyMargin))
+	}
+	if err := checkSignallingMode(meta.SignallingMode); err != nil {
+		errs = append(errs, err)
+	}
+	if meta.BlockLength < 0 {
+		errs = append(errs, fmt.Errorf("block_length %v must not be negative", meta.BlockLength))
+	}
+	if meta.ConflictHorizon < 0 {
+		errs = append(errs, fmt.Errorf("conflict_horizon %v must not be negative", meta.ConflictHorizon))
+	}
+	if meta.MinSeparation < 0 {
+		errs = append(errs, fmt.Errorf("min_separation %v must not be negative", meta.MinSeparation))
+	}
+	if meta.RegulationThreshold < 0 {
+		errs = append(errs, fmt.Errorf("regulation_threshold %v must not be negative", meta.RegulationThreshold))
+	}
+	if meta.SubSteps < 0 {
+		errs = append(errs, fmt.Errorf("sub_steps %d must not be negative", meta.SubSteps))
+	}
+	if err := checkLogMode(meta.LogMode); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkSpeedUnit(meta.SpeedUnit); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkJitter("dwell_jitter", meta.DwellJitter); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkJitter("departure_jitter", meta.DepartureJitter); err != nil {
+		errs = append(errs, err)
+	}
+	if meta.LogVelocityThreshold < 0 {
+		errs = append(errs, fmt.Errorf("log_velocity_threshold %v must not be negative", meta.LogVelocityThreshold))
+	}
+	if meta.LogInterval < 0 {
+		errs = append(errs, fmt.Errorf("log_interval %v must not be negative", meta.LogInterval))
+	}
+	if p := meta.OutputPrecision; p != nil && (*p < 0 || *p > maxOutputPrecision) {
+		errs = append(errs, fmt.Errorf("output_precision %d must be in [0, %d]", *p, maxOutputPrecision))
+	}
+	return errs
+}
+
+// byPriority orders services by descending priority.
+func byPriority(a, b *service.SimService) int {
+	return cmp.Compare(b.Priority, a.Priority)
 }
 
 // Run executes the full simulation and returns the log.
 func (t *TMS) Run() (SimulationLog, error) {
+	return t.RunContext(context.Background())
+}
+
+// RunContext executes the simulation until it completes or ctx is done, checking ctx
+// before each timestep. On cancellation it returns the log accumulated so far together
+// with an error wrapping ctx.Err().
+func (t *TMS) RunContext(ctx context.Context) (SimulationLog, error) {
 	log := SimulationLog{Meta: t.meta}
-	for t.curTime <= t.meta.RunTime {
-		row, err := t.step()
-		if err != nil {
-			return SimulationLog{}, fmt.Errorf("at t=%.2f: %w", t.curTime, err)
-		}
+	rows, errc := t.RunStream(ctx)
+	for row := range rows {
 		log.Output = append(log.Output, row)
-		t.curTime += t.meta.TimeStep
 	}
+	if err := <-errc; err != nil {
+		if ctx.Err() == nil {
+			return SimulationLog{}, err
+		}
+		t.totalEnergy(&log)
+		log.Journeys = t.journeys()
+		log.Warnings = t.Warnings()
+		return log, err
+	}
+	t.totalEnergy(&log)
+	log.Journeys = t.journeys()
+	log.Warnings = t.Warnings()
+	summary := Summarise(log)
+	if t.meta.ReportSeparations {
+		summary.Separations = t.separationReport()
+	}
+	log.Summary = &summary
 	return log, nil
 }
 
+// Warnings returns the problems found with the input that did not stop the TMS being
+// built, such as edges shorter than their ends' coordinates allow.
+func (t *TMS) Warnings() []string { return t.graph.Warnings() }
+
+// RunStream executes the simulation in a new goroutine, sending each log row on the
+// returned row channel as soon as it is computed, cut down as the log mode requires. If
+// a timestep fails, or ctx is done before the run completes, a single error naming the
+// timestep is sent on the error channel. Both channels are closed when the run ends, the
+// row channel first; callers should drain rows and then receive from the error channel,
+// which yields nil on success.
+//
+// Cancel ctx to stop the run early if rows are no longer being received.
+func (t *TMS) RunStream(ctx context.Context) (<-chan SimulationLogRow, <-chan error) {
+	rows := make(chan SimulationLogRow)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(rows)
+		for t.curTime <= t.meta.RunTime {
+			if err := ctx.Err(); err != nil {
+				errc <- &StepError{Time: t.curTime, Err: err}
+				return
+			}
+			row, err := t.step()
+			if err != nil {
+				errc <- &StepError{Time: t.curTime, Err: err}
+				return
+			}
+			if row, ok := t.filterRow(row, t.curTime+t.meta.TimeStep > t.meta.RunTime); ok {
+				select {
+				case rows <- row:
+				case <-ctx.Done():
+					errc <- &StepError{Time: t.curTime, Err: ctx.Err()}
+					return
+				}
+			}
+			if t.Progress != nil {
+				t.Progress(t.curTime, t.meta.RunTime)
+			}
+			t.curTime += t.meta.TimeStep
+		}
+	}()
+	return rows, errc
+}
+
+// totalEnergy sums the services' cumulative energy into log.
+func (t *TMS) totalEnergy(log *SimulationLog) {
+	for _, svc := range t.services {
+		log.TractionEnergy += svc.TractionEnergy
+		log.RegenEnergy += svc.RegenEnergy
+	}
+}
+
+// Step runs the next timestep and returns its log row, for callers that drive the
+// simulation themselves, such as an animation. It reports false, with no row, once the
+// run is complete. Rows are returned in full whatever the log mode or interval, and
+// Progress is not called. Step must not be used together with Run or RunStream.
+func (t *TMS) Step() (SimulationLogRow, bool, error) {
+	if t.curTime > t.meta.RunTime {
+		return SimulationLogRow{}, false, nil
+	}
+	row, err := t.step()
+	if err != nil {
+		return SimulationLogRow{}, false, &StepError{Time: t.curTime, Err: err}
+	}
+	t.curTime += t.meta.TimeStep
+	return row, true, nil
+}
+
+// journeys returns the record of every service's calls at stops so far, by ServiceID,
+// leaving out services yet to call at any.
+func (t *TMS) journeys() []Journey {
+	var journeys []Journey
+	for _, svc := range t.services {
+		if len(svc.Arrivals) > 0 {
+			journeys = append(journeys, Journey{ServiceID: svc.ServiceID, Stops: slices.Clone(svc.Arrivals)})
+		}
+	}
+	return journeys
+}
+
 // step advances the simulation by one timestep and returns the resulting log row.
 func (t *TMS) step() (SimulationLogRow, error) {
 	dt := t.meta.TimeStep
+	t.applyEvents()
+	before := t.statesBefore()
+	if err := t.updateTrafficReach(); err != nil {
+		return SimulationLogRow{}, err
+	}
 
-	// Pass 1: compute the minimal MA (braking-distance safety envelope) for each service.
-	minMAs := make(map[string]movementAuthority, len(t.services))
-	for _, svc := range t.services {
-		minMAs[svc.ServiceID] = svc.BrakingDistance()
+	// Pass 1: compute the minimal MA (stopping-distance safety envelope) for each service.
+	minMAs, err := t.safetyPass()
+	if err != nil {
+		return SimulationLogRow{}, err
 	}
 
-	// Pass 2: propose, grant, and apply movement for each service.
-	for _, svc := range t.services {
-		switch svc.State {
-		case service.StateStationary:
-			// Hold until the departure delay has elapsed, then start moving.
-			if t.curTime < svc.DepartureDelay {
-				continue
-			}
-			svc.State = service.StateAccelerating
-			continue
-		case service.StateDwelling:
-			svc.AdvanceDwell(dt)
+	// Pass 2: propose, grant, and apply movement for each service. Higher-priority
+	// services go first, so they take movement authority, blocks and junctions first.
+	// Services that make no progress are noted for deadlock detection.
+	var stuck []*service.SimService
+	progress := len(t.active) > 0
+	for _, svc := range t.order {
+		if svc.Finished() {
 			continue
 		}
-
-		distToStop, err := t.distanceToNextStop(svc)
+		// Near a stop or speed change, move in substeps to resolve the approach finely;
+		// once the service stops under way, the rest of the timestep is not needed.
+		n, err := t.subSteps(svc, dt)
 		if err != nil {
-			return SimulationLogRow{}, fmt.Errorf("service %q distance to stop: %w", svc.ServiceID, err)
+			return SimulationLogRow{}, err
 		}
-
-		sl, err := t.getSpeedLimitInfo(svc)
-		if err != nil {
-			return SimulationLogRow{}, fmt.Errorf("service %q speed limit info: %w", svc.ServiceID, err)
+		moved := false
+		for i := range n {
+			h := dt / float64(n)
+			ok, err := t.moveService(svc, t.curTime-dt+float64(i+1)*h, h, minMAs)
+			if err != nil {
+				return SimulationLogRow{}, err
+			}
+			moved = moved || ok
+			if !moving(svc.State) {
+				break
+			}
+		}
+		if moved {
+			progress = true
+		} else {
+			stuck = append(stuck, svc)
 		}
+	}
 
-		// Kinematic proposal: how far would this service travel in dt with no MA constraints?
-		proposedDist, newVelocity, newState := proposeMovement(svc, dt, distToStop, sl)
+	if err := t.couplePortions(); err != nil {
+		return SimulationLogRow{}, err
+	}
+	if err := t.checkDeadlock(stuck, progress); err != nil {
+		return SimulationLogRow{}, err
+	}
+	if t.meta.ReportSeparations {
+		if err := t.recordSeparations(); err != nil {
+			return SimulationLogRow{}, err
+		}
+	}
 
-		// MA check: how far is the service allowed to travel given other services' safety envelopes?
-		maxAllowed, err := t.computeMaxAllowedDistance(svc, minMAs)
-		if err != nil {
-			return SimulationLogRow{}, fmt.Errorf("service %q MA check: %w", svc.ServiceID, err)
+	// Snapshot all services for the log.
+	logs := make([]service.ServiceLog, len(t.services))
+	for i, svc := range t.services {
+		logs[i] = svc.GetLog()
+		if t.meta.LogCoordinates {
+			c, err := t.graph.PositionCoordinate(svc.CurrentPosition)
+			if err != nil {
+				return SimulationLogRow{}, routingError(svc, fmt.Errorf("service %q coordinate: %w", svc.ServiceID, err))
+			}
+			logs[i].Coordinate = &c
 		}
+	}
+	row := t.roundRow(t.inSpeedUnit(SimulationLogRow{Timestamp: t.curTime, ServiceLogs: logs}))
+	t.notify(before, row)
+	return row, nil
+}
 
-		grantedDist := math.Min(proposedDist, maxAllowed)
+// moveService proposes, grants and applies svc's movement over the dt seconds ending at
+// simulation time end, given each service's minimal MA. It reports whether svc made
+// progress: moved, dwelt, left its origin, or waited on a hold or headway that will lapse.
+func (t *TMS) moveService(svc *service.SimService, end, dt float64, minMAs map[string]movementAuthority) (bool, error) {
+	if err := t.updateClaims(svc); err != nil {
+		return false, err
+	}
 
-		// If MA trims the movement, recompute velocity from the shorter granted distance.
-		if grantedDist < proposedDist {
-			newVelocity, newState = constrainedKinematics(svc, grantedDist)
+	switch svc.State {
+	case service.StateStationary:
+		// Hold until the service is due to depart, then start moving at once: if it fell
+		// due part-way through the timestep, it runs for the rest of it, having first
+		// claimed the track ahead.
+		due := svc.DepartureDue()
+		if !svc.Depart(end) || due >= end {
+			return true, nil
 		}
-
-		// Advance position and detect stop arrival.
-		arrived, err := t.advancePosition(svc, grantedDist)
-		if err != nil {
-			return SimulationLogRow{}, fmt.Errorf("service %q advance: %w", svc.ServiceID, err)
+		dt = math.Min(dt, end-due)
+		if err := t.updateClaims(svc); err != nil {
+			return false, err
 		}
+	case service.StateDwelling:
+		if err := t.regulate(svc, dt); err != nil {
+			return false, routingError(svc, fmt.Errorf("service %q regulation: %w", svc.ServiceID, err))
+		}
+		svc.AdvanceDwell(t.curTime, dt)
+		// A dwell that ends at once is only a pause at a hold point.
+		return svc.State == service.StateDwelling, nil
+	case service.StateHeld:
+		// Stand until the hold ends, then move off at once.
+		if svc.HeldAt(t.curTime) {
+			return true, nil
+		}
+		svc.State = service.StateAccelerating
+	}
+	if svc.SettingBack > 0 {
+		return t.setBack(svc, dt, minMAs)
+	}
 
-		if arrived {
-			svc.ArriveAtStop()
-		} else {
-			svc.Velocity = newVelocity
-			svc.State = newState
+	distToStop, err := t.distanceToNextStop(svc)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q distance to stop: %w", svc.ServiceID, err))
+	}
+	// A partner waiting at the stop takes up the platform: draw up behind it to couple.
+	distToPartner, waiting, err := t.distanceToPartner(svc)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q partner check: %w", svc.ServiceID, err))
+	}
+	if waiting {
+		distToStop = math.Min(distToStop, distToPartner)
+	}
+
+	sl, err := t.getSpeedLimitInfo(svc)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q speed limit info: %w", svc.ServiceID, err))
+	}
+
+	distToHold, err := t.distanceToHeldBlock(svc)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q block check: %w", svc.ServiceID, err))
+	}
+	distToJunction, err := t.distanceToLockedJunction(svc)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q junction check: %w", svc.ServiceID, err))
+	}
+	distToClosure, err := t.distanceToClosedEdge(svc)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q closure check: %w", svc.ServiceID, err))
+	}
+	distToHeadway, err := t.distanceToHeadway(svc)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q headway check: %w", svc.ServiceID, err))
+	}
+	distToSignal, err := t.distanceToOccupiedSection(svc)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q signal check: %w", svc.ServiceID, err))
+	}
+	distToMerge, err := t.distanceToMerge(svc)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q merge check: %w", svc.ServiceID, err))
+	}
+	progress := !math.IsInf(distToHeadway, 1) // the headway will run out
+	distToHold = min(distToHold, distToJunction, distToClosure, distToHeadway, distToSignal, distToMerge)
+
+	m, err := t.motionModel(svc)
+	if err != nil {
+		return false, kinematicsError(svc, fmt.Errorf("service %q motion model: %w", svc.ServiceID, err))
+	}
+	if svc.HeldAt(t.curTime) {
+		// A held service stops as soon as it can, wherever that is.
+		distToHold = math.Min(distToHold, m.BrakingDistance(svc.Velocity))
+		progress = true // the hold will end
+	}
+
+	// Kinematic proposal: how far would this service travel in dt with no MA constraints?
+	proposedDist, newVelocity, newState := proposeMovement(svc, m, dt, distToStop, distToHold, sl)
+	braking := newVelocity < svc.Velocity && newState != service.StateAccelerating && newState != service.StateCoasting
+	if svc.Reacting(braking, dt) {
+		// The driver has yet to react: carry on as before for now.
+		proposedDist, newVelocity, newState = svc.Velocity*dt, svc.Velocity, svc.State
+	}
+
+	// MA check: how far is the service allowed to travel given other services' safety envelopes?
+	maxAllowed, err := t.computeMaxAllowedDistance(svc, minMAs)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q MA check: %w", svc.ServiceID, err))
+	}
+
+	// Never enter a held block or locked junction: one the service can no longer stop
+	// short of has been claimed or closed too late.
+	if proposedDist > distToHold+holdTolerance {
+		return false, kinematicsError(svc, fmt.Errorf("service %q cannot stop short of its hold point %v m ahead", svc.ServiceID, distToHold))
+	}
+	grantedDist := math.Min(proposedDist, math.Min(maxAllowed, distToHold))
+
+	// If MA trims the movement, recompute velocity from the shorter granted distance.
+	if grantedDist < proposedDist {
+		newVelocity, newState = constrainedKinematics(svc, m, grantedDist)
+	}
+
+	// Advance position and detect stop arrival.
+	v0 := svc.Velocity
+	reached, arrived, err := t.advancePosition(svc, grantedDist)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q advance: %w", svc.ServiceID, err))
+	}
+	svc.Odometer += reached
+	progress = progress || grantedDist > 0 || arrived
+
+	if arrived {
+		reversed := svc.Reversed
+		at := end - dt + timeToCover(v0, newVelocity, grantedDist, dt, reached)
+		svc.ArriveAtStop(at, t.curTime)
+		if svc.Finished() {
+			t.releaseHolds(svc)
+		}
+		if err := t.divide(svc); err != nil {
+			return false, kinematicsError(svc, fmt.Errorf("service %q dividing: %w", svc.ServiceID, err))
+		}
+		if svc.Turning() || svc.Reversed != reversed {
+			if err := t.turnRound(svc); err != nil {
+				return false, routingError(svc, fmt.Errorf("service %q reversing: %w", svc.ServiceID, err))
+			}
+			svc.TurnedRound()
+		}
+	} else {
+		svc.Velocity = newVelocity
+		svc.State = newState
+		if newState == service.StateDwelling && svc.HeldAt(t.curTime) {
+			// Brought to a stand by its own hold rather than at a hold point.
+			svc.State = service.StateHeld
 		}
 	}
+	svc.AddEnergy(m.EnergyDelta(v0, svc.Velocity, grantedDist, svc.Mass()))
+	return progress, nil
+}
 
-	// Snapshot all services for the log.
-	logs := make([]service.ServiceLog, len(t.services))
-	for i, svc := range t.services {
-		logs[i] = svc.GetLog()
+// updateClaims refreshes the blocks, junctions and merges svc holds (see
+// updateBlockHolds, updateJunctionLocks and updateMergeLocks).
+func (t *TMS) updateClaims(svc *service.SimService) error {
+	if err := t.updateBlockHolds(svc); err != nil {
+		return routingError(svc, fmt.Errorf("service %q block holds: %w", svc.ServiceID, err))
+	}
+	if err := t.updateJunctionLocks(svc); err != nil {
+		return routingError(svc, fmt.Errorf("service %q junction locks: %w", svc.ServiceID, err))
 	}
-	return SimulationLogRow{Timestamp: t.curTime, ServiceLogs: logs}, nil
+	if err := t.updateMergeLocks(svc); err != nil {
+		return routingError(svc, fmt.Errorf("service %q merge locks: %w", svc.ServiceID, err))
+	}
+	return nil
 }
 
 // distanceToNextStop returns the metres from svc's current position to its next stop node.
@@ -145,139 +671,221 @@ func (t *TMS) distanceToNextStop(svc *service.SimService) (float64, error) {
 	if err != nil {
 		return 0, err
 	}
-	remainingOnEdge := edge.Length - svc.CurrentPosition.DistanceAlongEdge
+	dist := edge.Length - svc.CurrentPosition.DistanceAlongEdge
 
-	if edge.V == svc.NextStop {
-		return remainingOnEdge, nil
+	path, err := t.pathAhead(svc)
+	if err != nil {
+		return 0, fmt.Errorf("no path to next stop %q: %w", svc.NextStop, err)
+	}
+	for _, e := range path {
+		dist += e.Length
 	}
+	return dist, nil
+}
 
-	path, err := t.graph.GetShortestPath(edge.V, svc.NextStop)
+// motionModel returns svc's kinematics model evaluated under the conditions of the
+// edge it currently occupies (gradient and adhesion). A service setting back runs
+// against the edge's gradient.
+func (t *TMS) motionModel(svc *service.SimService) (kinematics.MotionModel, error) {
+	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
 	if err != nil {
-		return 0, fmt.Errorf("no path to next stop %q: %w", svc.NextStop, err)
+		return nil, err
+	}
+	m := kinematics.WithAdhesion(svc.Kinematics(), t.adhesion(edge))
+	if svc.SettingBack > 0 {
+		return kinematics.AtGradient(m, -edge.Gradient), nil
+	}
+	return kinematics.AtGradient(m, edge.Gradient), nil
+}
+
+// adhesion returns the braking adhesion factor on edge, falling back to the
+// simulation-wide default and then to full adhesion.
+func (t *TMS) adhesion(edge graph.Edge) float64 {
+	switch {
+	case edge.Adhesion != nil:
+		return *edge.Adhesion
+	case t.meta.Adhesion != nil:
+		return *t.meta.Adhesion
+	default:
+		return 1
 	}
-	return remainingOnEdge + path.Length, nil
 }
 
 // getSpeedLimitInfo returns the effective speed limits relevant to svc's current position.
-// currentMax is the effective VMax on the current edge (min of vehicle VMax and edge limit).
-// distToChange is the remaining distance on the current edge.
-// nextMax is the effective VMax on the next edge toward the next stop; it is 0 when the
-// next stop is at the end of the current edge (stop braking handles that case instead).
+// currentMax is the effective VMax at the front (min of the service's top speed, the edge
+// limit and any TSR over it).
+// distToChange and nextMax are the distance to, and effective VMax of, the next limit
+// ahead that is lower than currentMax: the start of an edge on the path toward the next
+// stop, including any curve limit on the turn onto it, or the start of a TSR. The path is
+// scanned as far as the service could need to brake from the higher of its speed and
+// currentMax, and of several lower limits the one it must start braking for first is
+// given. With none, they are the remaining distance on the current edge and the limit on
+// the next; nextMax is 0 when the next stop is at the end of the current edge (stop
+// braking handles that case instead).
 func (t *TMS) getSpeedLimitInfo(svc *service.SimService) (speedLimitInfo, error) {
 	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
 	if err != nil {
 		return speedLimitInfo{}, err
 	}
+	d := svc.CurrentPosition.DistanceAlongEdge
+	top := svc.TopSpeed()
 
-	currentMax := svc.Vehicle.Kinem.VMax()
-	if edge.SpeedLimit != nil && *edge.SpeedLimit < currentMax {
-		currentMax = *edge.SpeedLimit
+	currentMax := math.Min(top, t.speedLimit(edge))
+	currentMax = math.Min(currentMax, t.tsrLimit(edge.ID, d))
+	currentMax, err = t.congestedLimit(svc, edge, currentMax)
+	if err != nil {
+		return speedLimitInfo{}, err
 	}
 
-	distToChange := edge.Length - svc.CurrentPosition.DistanceAlongEdge
-
-	// If the next stop is at the end of this edge, stop braking already handles the approach.
-	if edge.V == svc.NextStop {
-		return speedLimitInfo{currentMax: currentMax, distToChange: distToChange, nextMax: 0}, nil
+	path, err := t.pathAhead(svc)
+	if err != nil {
+		return speedLimitInfo{}, err
 	}
-
-	// Look ahead one edge to anticipate an upcoming speed limit change.
-	nextMax := svc.Vehicle.Kinem.VMax()
-	nextEdge, err := t.graph.GetNextEdge(edge.V, svc.NextStop)
-	if err == nil && nextEdge.SpeedLimit != nil && *nextEdge.SpeedLimit < nextMax {
-		nextMax = *nextEdge.SpeedLimit
+	m, err := t.motionModel(svc)
+	if err != nil {
+		return speedLimitInfo{}, err
 	}
+	v := math.Max(svc.Velocity, currentMax)
+	horizon := svc.ReactionDistance() + m.BrakingDistance(v) + v*t.meta.TimeStep
 
-	return speedLimitInfo{currentMax: currentMax, distToChange: distToChange, nextMax: nextMax}, nil
-}
-
-// computeMaxAllowedDistance returns the maximum distance svc may travel without
-// entering any other service's safety envelope (minimal MA + vehicle length).
-//
-// TODO: extend to full segment-based MA comparison for branching networks.
-// Currently only checks services on the same edge.
-func (t *TMS) computeMaxAllowedDistance(svc *service.SimService, minMAs map[string]movementAuthority) (float64, error) {
-	maxDist := math.Inf(1)
-
-	for _, other := range t.services {
-		if other.ServiceID == svc.ServiceID {
-			continue
+	distToChange := edge.Length - d
+	nextMax := 0.0
+	changes := t.tsrChanges(nil, edge.ID, d, 0, top)
+	prev, offset := edge, distToChange
+	for i, e := range path {
+		if i > 0 && offset > horizon {
+			break
 		}
-
-		// Only check services ahead on the same edge.
-		// TODO: resolve conflicts across edge boundaries for full network coverage.
-		if other.CurrentPosition.Edge != svc.CurrentPosition.Edge {
-			continue
-		}
-
-		otherPos := other.CurrentPosition.DistanceAlongEdge
-		myPos := svc.CurrentPosition.DistanceAlongEdge
-
-		if otherPos <= myPos {
-			continue // other is behind or level
+		limit := math.Min(top, t.speedLimit(e))
+		limit = math.Min(limit, t.graph.CurveSpeedLimit(prev, e))
+		limit = math.Min(limit, t.tsrLimit(e.ID, 0))
+		limit, err = t.congestedLimit(svc, e, limit)
+		if err != nil {
+			return speedLimitInfo{}, err
 		}
-
-		// Other's protected zone: from its rear (front − length) minus its braking distance.
-		// We must not enter that zone.
-		safetyZoneStart := otherPos - other.Vehicle.Length - minMAs[other.ServiceID]
-		allowed := safetyZoneStart - myPos
-		if allowed < maxDist {
-			maxDist = allowed
+		if i == 0 {
+			nextMax = limit
 		}
+		changes = append(changes, limitChange{dist: offset, limit: limit})
+		changes = t.tsrChanges(changes, e.ID, 0, offset, top)
+		prev, offset = e, offset+e.Length
 	}
 
-	if math.IsInf(maxDist, 1) {
-		return math.MaxFloat64, nil
+	changes = slices.DeleteFunc(changes, func(c limitChange) bool { return c.limit >= currentMax })
+	if len(changes) == 0 {
+		return speedLimitInfo{currentMax: currentMax, distToChange: distToChange, nextMax: nextMax}, nil
 	}
-	return math.Max(0, maxDist), nil
+	next := mostUrgent(changes, m, svc.Velocity)
+	return speedLimitInfo{currentMax: currentMax, distToChange: next.dist, nextMax: next.limit}, nil
 }
 
-// advancePosition moves svc along the graph by dist metres, following the shortest
-// path toward its next stop. Returns true if the service arrived at the next stop.
-func (t *TMS) advancePosition(svc *service.SimService, dist float64) (bool, error) {
-	for dist > 0 {
+// advancePosition moves svc along the graph by dist metres, following its path toward
+// its next stop (see pathAhead). It returns the distance travelled, which falls short of
+// dist if the service arrived at the next stop, and whether it did. A negative dist sets
+// svc back instead (see retreat).
+func (t *TMS) advancePosition(svc *service.SimService, dist float64) (float64, bool, error) {
+	if dist < 0 {
+		return t.retreat(svc, -dist)
+	}
+	travelled := 0.0
+	for dist > travelled {
 		edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
 		if err != nil {
-			return false, err
+			return 0, false, err
+		}
+		if svc.CurrentPosition.DistanceAlongEdge == 0 {
+			t.enterEdge(svc, edge.ID)
 		}
 		remaining := edge.Length - svc.CurrentPosition.DistanceAlongEdge
 
-		if dist < remaining {
-			svc.CurrentPosition.DistanceAlongEdge += dist
-			return false, nil
+		if dist-travelled < remaining {
+			svc.CurrentPosition.DistanceAlongEdge += dist - travelled
+			return dist, false, nil
 		}
 
-		dist -= remaining
+		travelled += remaining
 
 		if edge.V == svc.NextStop {
 			svc.CurrentPosition.DistanceAlongEdge = edge.Length
-			return true, nil
+			return travelled, true, nil
 		}
 
-		nextEdge, err := t.graph.GetNextEdge(edge.V, svc.NextStop)
+		svc.PassNode(edge.V)
+		nextEdge, err := t.nextEdge(svc, edge.V)
 		if err != nil {
-			return false, fmt.Errorf("advancing past edge %q: %w", edge.ID, err)
+			return 0, false, fmt.Errorf("advancing past edge %q: %w", edge.ID, err)
+		}
+		if err := t.recordTrail(svc, edge); err != nil {
+			return 0, false, err
 		}
 		svc.CurrentPosition = graph.Position{Edge: nextEdge.ID, DistanceAlongEdge: 0}
+		t.occupancy.update(svc)
+	}
+	return dist, false, nil
+}
+
+// timeToCover returns the seconds into a movement of dist metres over dt seconds, from
+// velocity v0 to v1, at which the first reached metres of it have been covered. The
+// acceleration is taken as constant while under way, which ends before dt if the
+// movement comes to a stand part-way.
+func timeToCover(v0, v1, dist, dt, reached float64) float64 {
+	active := dt
+	if v0+v1 > 0 {
+		active = math.Min(dt, 2*dist/(v0+v1))
+	}
+	if reached >= dist {
+		return active
+	}
+	a := (v1 - v0) / active
+	if math.Abs(a) < 1e-9 {
+		return reached / v0
 	}
-	return false, nil
+	disc := v0*v0 + 2*a*reached
+	if disc <= 0 {
+		return active
+	}
+	return math.Min(active, (math.Sqrt(disc)-v0)/a)
 }
 
 // proposeMovement returns the distance, resulting velocity, and resulting state for svc
-// over timestep dt, applying speed limits from sl and braking for the next stop.
+// over timestep dt under motion model m, applying speed limits from sl, braking for
+// the next stop, and holding short of a held block or locked junction distToHold ahead
+// (+Inf if none). Braking for a point ahead is called for the driver's reaction distance
+// early, so that braking begins on time once the driver has reacted.
 //
 // Priority (highest first):
 //  1. Braking to stop at next stop
-//  2. Braking for an upcoming edge speed limit reduction (lookahead)
-//  3. Decelerating to the current edge speed limit (if currently over it)
-//  4. Normal state machine (accelerate / cruise / decelerate)
-func proposeMovement(svc *service.SimService, dt, distToStop float64, sl speedLimitInfo) (float64, float64, service.ServiceState) {
+//  2. Braking to hold short of a block or junction held by another service, a closed
+//     edge or one entered within the minimum headway, or to a stand when the service
+//     itself is held
+//  3. Braking for the most pressing lower edge or TSR speed limit ahead (lookahead)
+//  4. Braking to the vehicle's creep speed short of the next stop
+//  5. Decelerating to the current edge speed limit, or the creep speed within the
+//     creep distance of the next stop (if currently over it)
+//  6. Normal state machine (accelerate / cruise / coast / decelerate)
+func proposeMovement(svc *service.SimService, m kinematics.MotionModel, dt, distToStop, distToHold float64, sl speedLimitInfo) (float64, float64, service.ServiceState) {
 	v := svc.Velocity
-	m := svc.Vehicle.Kinem
 	effectiveVMax := sl.currentMax
+	reaction := svc.ReactionDistance()
+	creep, toCreep := svc.Vehicle.CreepSpeed, distToStop-svc.Vehicle.CreepDistance
+	if creep > 0 && toCreep <= 0 {
+		effectiveVMax = math.Min(effectiveVMax, creep)
+	}
 
 	// 1. Stop braking (highest priority).
-	if distToStop <= m.BrakingDistance(v) {
+	if distToStop <= reaction+m.BrakingDistance(v) {
+		dist, newV := m.DecelerateStep(v, 0, dt)
+		if newV <= 0 {
+			return dist, 0, service.StateDwelling
+		}
+		return dist, newV, service.StateDecelerating
+	}
+
+	// 2. Hold braking, called for if the service could no longer stop short of the hold
+	// point after running on for this step as hard as it may. Like an MA stop, coming to
+	// rest at the hold point reads as a zero-length dwell, after which the service tries
+	// again.
+	if distToHold < distToStop && distToHold <= holdReach(svc, m, effectiveVMax, dt) {
 		dist, newV := m.DecelerateStep(v, 0, dt)
 		if newV <= 0 {
 			return dist, 0, service.StateDwelling
@@ -285,9 +893,9 @@ func proposeMovement(svc *service.SimService, dt, distToStop float64, sl speedLi
 		return dist, newV, service.StateDecelerating
 	}
 
-	// 2. Lookahead braking for an upcoming lower speed limit on the next edge.
+	// 3. Lookahead braking for an upcoming lower speed limit on the next edge.
 	if sl.nextMax > 0 && sl.nextMax < effectiveVMax && v > sl.nextMax {
-		if sl.distToChange <= m.BrakingDistanceTo(v, sl.nextMax) {
+		if sl.distToChange <= reaction+m.BrakingDistanceTo(v, sl.nextMax) {
 			dist, newV := m.DecelerateStep(v, sl.nextMax, dt)
 			if newV <= sl.nextMax {
 				return dist, newV, service.StateCruising
@@ -296,7 +904,17 @@ func proposeMovement(svc *service.SimService, dt, distToStop float64, sl speedLi
 		}
 	}
 
-	// 3. Decelerate to current edge speed limit if currently over it.
+	// 4. Creep braking: reach the creep speed by the creep distance short of the stop.
+	// Once begun, the braking curve is followed to within rounding.
+	if creep > 0 && toCreep > 0 && v > creep && toCreep <= reaction+m.BrakingDistanceTo(v, creep)+creepTolerance {
+		dist, newV := m.DecelerateStep(v, creep, dt)
+		if newV <= creep {
+			return dist, newV, service.StateCruising
+		}
+		return dist, newV, service.StateDecelerating
+	}
+
+	// 5. Decelerate to current edge speed limit if currently over it.
 	if v > effectiveVMax {
 		dist, newV := m.DecelerateStep(v, effectiveVMax, dt)
 		if newV <= effectiveVMax {
@@ -305,9 +923,13 @@ func proposeMovement(svc *service.SimService, dt, distToStop float64, sl speedLi
 		return dist, newV, service.StateDecelerating
 	}
 
-	// 4. Normal state machine.
+	// 6. Normal state machine.
+	if c, ok := m.(kinematics.Coaster); ok && coasts(svc, effectiveVMax) {
+		dist, newV := c.CoastStep(v, dt)
+		return dist, newV, service.StateCoasting
+	}
 	switch svc.State {
-	case service.StateAccelerating:
+	case service.StateAccelerating, service.StateCoasting:
 		dist, newV := m.AccelerateStep(v, effectiveVMax, dt)
 		if newV >= effectiveVMax {
 			return dist, effectiveVMax, service.StateCruising
@@ -315,6 +937,14 @@ func proposeMovement(svc *service.SimService, dt, distToStop float64, sl speedLi
 		return dist, newV, service.StateAccelerating
 
 	case service.StateCruising:
+		if v < effectiveVMax {
+			// The limit has risen: accelerate up to it.
+			dist, newV := m.AccelerateStep(v, effectiveVMax, dt)
+			if newV >= effectiveVMax {
+				return dist, effectiveVMax, service.StateCruising
+			}
+			return dist, newV, service.StateAccelerating
+		}
 		return effectiveVMax * dt, effectiveVMax, service.StateCruising
 
 	case service.StateDecelerating:
@@ -329,10 +959,53 @@ func proposeMovement(svc *service.SimService, dt, distToStop float64, sl speedLi
 	}
 }
 
+// holdTolerance is how far (metres) a service braking for a hold point may run past it
+// through rounding, to be drawn up at it.
+const holdTolerance = 1e-6
+
+// creepTolerance is how far (metres) a service braking to its creep speed may fall
+// behind its braking curve through rounding and still carry on braking to it.
+const creepTolerance = 1e-6
+
+// coasts reports whether svc, under way with no braking called for, should run without
+// traction: from the moment it reaches its vehicle's CoastSpeed, or the line speed
+// effectiveVMax if lower, until it has slowed to the ResumeSpeed.
+func coasts(svc *service.SimService, effectiveVMax float64) bool {
+	vehicle := svc.Vehicle
+	switch {
+	case vehicle.CoastSpeed <= 0:
+		return false
+	case svc.State == service.StateCoasting:
+		return svc.Velocity > vehicle.ResumeSpeed
+	case svc.State == service.StateAccelerating || svc.State == service.StateCruising:
+		return svc.Velocity >= math.Min(vehicle.CoastSpeed, effectiveVMax)
+	default:
+		return false
+	}
+}
+
+// stoppingDistance returns the distance svc needs to stop under motion model m: its
+// driver's reaction distance plus its braking distance.
+func stoppingDistance(svc *service.SimService, m kinematics.MotionModel) float64 {
+	return svc.ReactionDistance() + m.BrakingDistance(svc.Velocity)
+}
+
+// holdReach returns how far ahead of svc's front, under motion model m, a hold point must
+// lie for svc to be sure of stopping short of it after running on for a step of dt
+// seconds, as hard as it may toward vMax: its reaction distance, that step's running, and
+// its braking distance from the speed it then reaches. The step is taken on a copy of
+// svc's kinematic state, so that working out the reach leaves the state as it was.
+func holdReach(svc *service.SimService, m kinematics.MotionModel, vMax, dt float64) float64 {
+	state := svc.KinemState
+	m = kinematics.WithState(m, &state)
+	ahead, vAhead := m.AccelerateStep(svc.Velocity, math.Max(svc.Velocity, vMax), dt)
+	return svc.ReactionDistance() + ahead + m.BrakingDistance(vAhead)
+}
+
 // constrainedKinematics derives the velocity after travelling grantedDist under
 // maximum braking (used when the MA limits movement to less than proposed).
-func constrainedKinematics(svc *service.SimService, grantedDist float64) (float64, service.ServiceState) {
-	newV := svc.Vehicle.Kinem.VelocityAfterBraking(svc.Velocity, grantedDist)
+func constrainedKinematics(svc *service.SimService, m kinematics.MotionModel, grantedDist float64) (float64, service.ServiceState) {
+	newV := m.VelocityAfterBraking(svc.Velocity, grantedDist)
 	if newV <= 0 {
 		return 0, service.StateDwelling
 	}
@@ -343,17 +1016,13 @@ func constrainedKinematics(svc *service.SimService, grantedDist float64) (float6
 // It accepts a JSON-encoded SimulationInput, runs the simulation, and returns a
 // JSON-encoded SimulationLog.
 func RunJSON(jsonInput string) (string, error) {
-	var input SimulationInput
-	if err := json.Unmarshal([]byte(jsonInput), &input); err != nil {
-		return "", fmt.Errorf("invalid input JSON: %w", err)
-	}
-
-	tms, err := NewTMS(input)
-	if err != nil {
-		return "", err
-	}
+	return RunJSONContext(context.Background(), jsonInput)
+}
 
-	simLog, err := tms.Run()
+// RunJSONContext is RunJSON with cancellation: the simulation stops early with an error
+// once ctx is done. opts are applied to the TMS before it runs.
+func RunJSONContext(ctx context.Context, jsonInput string, opts ...RunOption) (string, error) {
+	simLog, err := RunInput(ctx, jsonInput, opts...)
 	if err != nil {
 		return "", err
 	}
@@ -364,3 +1033,30 @@ func RunJSON(jsonInput string) (string, error) {
 	}
 	return string(out), nil
 }
+
+// RunInput is RunJSONContext without encoding the output: it returns the SimulationLog
+// itself, for callers that write it in another format (see WriteCSV).
+func RunInput(ctx context.Context, jsonInput string, opts ...RunOption) (SimulationLog, error) {
+	tms, err := NewTMSFromJSON(jsonInput, opts...)
+	if err != nil {
+		return SimulationLog{}, err
+	}
+	return tms.RunContext(ctx)
+}
+
+// NewTMSFromJSON builds a TMS from a JSON-encoded SimulationInput and applies opts to it.
+func NewTMSFromJSON(jsonInput string, opts ...RunOption) (*TMS, error) {
+	var input SimulationInput
+	if err := json.Unmarshal([]byte(jsonInput), &input); err != nil {
+		return nil, invalid("", "", fmt.Errorf("invalid input JSON: %w", err))
+	}
+
+	tms, err := NewTMS(input)
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(tms)
+	}
+	return tms, nil
+}
@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// testVehicle is the vehicle the scenario tests run unless they give their own.
+const testVehicle = `{"length": 50, "kinematics": {"model": "constant", "v_max": 20, "a_acc": 0.5, "a_dcc": 0.7}}`
+
+// newTestTMS builds a TMS from a JSON-encoded SimulationInput, failing the test if it
+// cannot be built.
+func newTestTMS(tb testing.TB, input string) *TMS {
+	tb.Helper()
+	tms, err := NewTMS(testInput(tb, input))
+	if err != nil {
+		tb.Fatalf("NewTMS: %v", err)
+	}
+	return tms
+}
+
+// testInput decodes a JSON-encoded SimulationInput, with testVehicle for each "VEHICLE".
+func testInput(tb testing.TB, input string) SimulationInput {
+	tb.Helper()
+	input = strings.ReplaceAll(input, `"VEHICLE"`, testVehicle)
+	var in SimulationInput
+	if err := json.Unmarshal([]byte(input), &in); err != nil {
+		tb.Fatalf("decoding input: %v", err)
+	}
+	return in
+}
+
+// runChecked steps tms to the end of the run, calling check after every timestep, and
+// returns the error of the timestep that failed, if any.
+func runChecked(tms *TMS, check func(row SimulationLogRow)) error {
+	for {
+		row, ok, err := tms.Step()
+		if err != nil || !ok {
+			return err
+		}
+		if check != nil {
+			check(row)
+		}
+	}
+}
+
+// trackSegment returns seg on the track it covers, expressed along the forward edge of a
+// bidirectional pair, so that services running either way over it can be compared.
+func trackSegment(g *graph.Graph, seg graph.Segment) graph.Segment {
+	if base, ok := strings.CutSuffix(seg.Edge, ":reverse"); ok {
+		if e, err := g.GetEdgeByID(base); err == nil {
+			return graph.Segment{Edge: base, Start: e.Length - seg.End, End: e.Length - seg.Start}
+		}
+	}
+	return seg
+}
+
+// bodies returns the track each unfinished service's body lies on, by ServiceID.
+func bodies(tb testing.TB, tms *TMS) map[service.ServiceID][]graph.Segment {
+	tb.Helper()
+	found := make(map[service.ServiceID][]graph.Segment)
+	for _, svc := range tms.services {
+		if svc.Finished() {
+			continue
+		}
+		zone, err := tms.occupiedZone(svc, 0)
+		if err != nil {
+			tb.Fatalf("service %q zone: %v", svc.ServiceID, err)
+		}
+		for _, seg := range zone {
+			found[svc.ServiceID] = append(found[svc.ServiceID], trackSegment(tms.graph, seg))
+		}
+	}
+	return found
+}
+
+// checkNoCollision fails the test if the bodies of any two services in tms overlap.
+func checkNoCollision(tb testing.TB, tms *TMS, at float64) {
+	tb.Helper()
+	found := bodies(tb, tms)
+	for a, segsA := range found {
+		for b, segsB := range found {
+			if a >= b {
+				continue
+			}
+			for _, x := range segsA {
+				for _, y := range segsB {
+					if x.Edge == y.Edge && x.Start < y.End && y.Start < x.End {
+						tb.Fatalf("t=%v: %s %v and %s %v overlap", at, a, x, b, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+// checkNoFreeze fails the test if a service on the move, either way, at both of two
+// consecutive log rows, prev and row, has not moved between them.
+func checkNoFreeze(tb testing.TB, prev, row SimulationLogRow) {
+	tb.Helper()
+	for i, l := range row.ServiceLogs {
+		p := prev.ServiceLogs[i]
+		if l.Velocity != 0 && p.Velocity != 0 && l.CurrentPosition == p.CurrentPosition {
+			tb.Fatalf("t=%v: %s stands at %v at %v m/s", row.Timestamp, l.ServiceID, l.CurrentPosition, l.Velocity)
+		}
+	}
+}
+
+// yMergeInput is a Y-shaped network, edges AM (500 m) and CM (cm metres) converging at
+// M onto MB (1500 m), with S1 running from A and S2, delay seconds later, from C, both
+// to B.
+func yMergeInput(cm, delay float64) string {
+	return fmt.Sprintf(`{
+		"simulation_meta": {"simulation_id": "y-merge", "run_time": 300, "time_step": 1},
+		"graph_data": {
+			"nodes": [{"node_id": "A"}, {"node_id": "C"}, {"node_id": "M"}, {"node_id": "B"}],
+			"edges": [
+				{"edge_id": "AM", "u": "A", "v": "M", "length": 500},
+				{"edge_id": "CM", "u": "C", "v": "M", "length": %v},
+				{"edge_id": "MB", "u": "M", "v": "B", "length": 1500}
+			]
+		},
+		"service_list": [
+			{"service_id": "S1", "initial_position": "A", "route": [{"node_id": "B", "min_dwell": 0}], "vehicle": "VEHICLE"},
+			{"service_id": "S2", "initial_position": "C", "departure_delay": %v, "route": [{"node_id": "B", "min_dwell": 0}], "vehicle": "VEHICLE"}
+		]
+	}`, cm, delay)
+}
+
+// jerkVehicle is testVehicle under the jerk-limited model, whose acceleration carries over
+// from one step to the next.
+const jerkVehicle = `{"length": 50, "kinematics": {"model": "jerk", "v_max": 20, "a_acc": 0.5, "a_dcc": 0.7, "j_acc": 0.2, "j_dcc": 0.2}}`
+
+// TestHoldBraking checks that a service braking for a hold point draws up at or short of
+// it, rather than being stopped there with speed to spare.
+func TestHoldBraking(t *testing.T) {
+	for _, tc := range []struct {
+		vehicle string
+		delay   float64
+	}{
+		{testVehicle, 14},
+		{jerkVehicle, 8},
+		{jerkVehicle, 12},
+	} {
+		tms := newTestTMS(t, strings.ReplaceAll(yMergeInput(200, tc.delay), `"VEHICLE"`, tc.vehicle))
+		var prev SimulationLogRow
+		err := runChecked(tms, func(row SimulationLogRow) {
+			if prev.ServiceLogs != nil {
+				checkNoFreeze(t, prev, row)
+			}
+			prev = row
+		})
+		if err != nil {
+			t.Fatalf("%s, delay %v: %v", tc.vehicle, tc.delay, err)
+		}
+	}
+}
+
+// TestClosureTooLate checks that a service which cannot stop short of an edge closed just
+// ahead of it runs on through rather than being stopped dead.
+func TestClosureTooLate(t *testing.T) {
+	tms := newTestTMS(t, `{
+		"simulation_meta": {"simulation_id": "closure", "run_time": 400, "time_step": 1},
+		"graph_data": {
+			"nodes": [{"node_id": "A"}, {"node_id": "B"}, {"node_id": "C"}],
+			"edges": [
+				{"edge_id": "AB", "u": "A", "v": "B", "length": 500},
+				{"edge_id": "BC", "u": "B", "v": "C", "length": 1000}
+			]
+		},
+		"service_list": [
+			{"service_id": "S1", "initial_position": "A", "route": [{"node_id": "C", "min_dwell": 0}], "vehicle": "VEHICLE"}
+		],
+		"events": [{"type": "edge_closure", "time": 43, "duration": 60, "edge_id": "BC"}]
+	}`)
+	var prev SimulationLogRow
+	err := runChecked(tms, func(row SimulationLogRow) {
+		if prev.ServiceLogs != nil {
+			checkNoFreeze(t, prev, row)
+		}
+		prev = row
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc := tms.service("S1"); !svc.Finished() {
+		t.Errorf("S1 did not finish: %s at %v", svc.State, svc.CurrentPosition)
+	}
+}
+
+// TestRaisedLimit checks that a service cruising at a speed limit accelerates up to a
+// higher one as it is raised, whether by running onto a faster edge or by a TSR ending,
+// rather than jumping to it.
+func TestRaisedLimit(t *testing.T) {
+	tms := newTestTMS(t, `{
+		"simulation_meta": {"simulation_id": "raised-limit", "run_time": 300, "time_step": 1},
+		"graph_data": {
+			"nodes": [{"node_id": "A"}, {"node_id": "B"}, {"node_id": "C"}, {"node_id": "D"}],
+			"edges": [
+				{"edge_id": "AB", "u": "A", "v": "B", "length": 1000, "speed_limit": 8},
+				{"edge_id": "BC", "u": "B", "v": "C", "length": 1500},
+				{"edge_id": "CD", "u": "C", "v": "D", "length": 3000}
+			]
+		},
+		"service_list": [
+			{"service_id": "S1", "initial_position": "A", "route": [{"node_id": "D", "min_dwell": 0}], "vehicle": "VEHICLE"}
+		],
+		"events": [{"type": "temporary_speed_limit", "time": 0, "duration": 180, "edge_id": "CD", "speed_limit": 12}]
+	}`)
+	const aAcc = 0.5 // testVehicle's
+	var prev SimulationLogRow
+	err := runChecked(tms, func(row SimulationLogRow) {
+		if prev.ServiceLogs != nil {
+			p, l := prev.ServiceLogs[0], row.ServiceLogs[0]
+			if dt := row.Timestamp - prev.Timestamp; l.Velocity > p.Velocity+aAcc*dt+1e-9 {
+				t.Errorf("t=%v: speed rose from %v to %v m/s at %v", row.Timestamp, p.Velocity, l.Velocity, l.CurrentPosition)
+			}
+		}
+		prev = row
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
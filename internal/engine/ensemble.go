@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// RunEnsemble runs input once for each of seeds, in place of its own Meta.Seed, and
+// returns the summaries of the runs in the order of seeds. Up to workers runs go at
+// once; workers below 2 runs them one after another. The graph is built, and input
+// checked, only once, and shared by every run. If any run fails, RunEnsemble returns
+// the error of the first seed to fail.
+func RunEnsemble(input SimulationInput, seeds []int64, workers int) ([]SimulationSummary, error) {
+	if len(seeds) == 0 {
+		return nil, nil
+	}
+	input.Meta.Seed = seeds[0]
+	first, err := NewTMS(input)
+	if err != nil {
+		return nil, err
+	}
+	first.graph.Freeze()
+	input, _ = input.resolved()
+	input = input.inMetresPerSecond()
+
+	summaries := make([]SimulationSummary, len(seeds))
+	errs := make([]error, len(seeds))
+	run := func(i int) {
+		t := first
+		if i > 0 {
+			in := input
+			in.Meta.Seed = seeds[i]
+			built, buildErrs := buildOn(in, first.graph, nil)
+			if len(buildErrs) > 0 {
+				errs[i] = buildErrs[0]
+				return
+			}
+			t = built
+		}
+		log, err := t.Run()
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		summaries[i] = *log.Summary
+	}
+
+	if workers < 2 {
+		for i := range seeds {
+			run(i)
+		}
+	} else {
+		sem := make(chan struct{}, workers)
+		var wg sync.WaitGroup
+		for i := range seeds {
+			sem <- struct{}{}
+			wg.Go(func() {
+				defer func() { <-sem }()
+				run(i)
+			})
+		}
+		wg.Wait()
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("seed %d: %w", seeds[i], err)
+		}
+	}
+	return summaries, nil
+}
+
+// DelayPercentile returns, for each service with a delay in any of summaries, the p-th
+// percentile (0 to 100) of its delays across them, interpolating linearly between the
+// nearest two. Runs in which a service reached no timetabled stop are left out.
+func DelayPercentile(summaries []SimulationSummary, p float64) map[service.ServiceID]float64 {
+	delays := make(map[service.ServiceID][]float64)
+	for _, summary := range summaries {
+		for _, s := range summary.Services {
+			if s.Delay != nil {
+				delays[s.ServiceID] = append(delays[s.ServiceID], *s.Delay)
+			}
+		}
+	}
+	out := make(map[service.ServiceID]float64, len(delays))
+	for id, ds := range delays {
+		slices.Sort(ds)
+		rank := math.Min(math.Max(p, 0), 100) / 100 * float64(len(ds)-1)
+		lo := int(math.Floor(rank))
+		hi := int(math.Ceil(rank))
+		out[id] = ds[lo] + (ds[hi]-ds[lo])*(rank-float64(lo))
+	}
+	return out
+}
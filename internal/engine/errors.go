@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// The engine's errors carry their cause in one of the types below, which callers can
+// pick out with errors.As to tell what went wrong and where. Each type's message is that
+// of the error it wraps.
+
+// ValidationError reports a problem with the simulation input, found before it runs.
+type ValidationError struct {
+	ServiceID service.ServiceID // the service at fault, if any
+	EdgeID    graph.EdgeID      // the edge at fault, if any
+	Err       error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// RoutingError reports a service that could not find its way through the network, or
+// trace itself or others over it, during the run.
+type RoutingError struct {
+	ServiceID service.ServiceID
+	EdgeID    graph.EdgeID // the edge the service was on
+	Err       error
+}
+
+func (e *RoutingError) Error() string { return e.Err.Error() }
+func (e *RoutingError) Unwrap() error { return e.Err }
+
+// KinematicsError reports a service whose motion model could not be formed or applied
+// during the run, as when coupling or dividing its units.
+type KinematicsError struct {
+	ServiceID service.ServiceID
+	Err       error
+}
+
+func (e *KinematicsError) Error() string { return e.Err.Error() }
+func (e *KinematicsError) Unwrap() error { return e.Err }
+
+// DeadlockError reports a run in which no service could move for deadlockSteps
+// consecutive timesteps.
+type DeadlockError struct {
+	ServiceIDs []service.ServiceID // the services stuck, in priority order
+	Err        error
+}
+
+func (e *DeadlockError) Error() string { return e.Err.Error() }
+func (e *DeadlockError) Unwrap() error { return e.Err }
+
+// StepError reports the simulation time of the timestep at which a run failed or was
+// cancelled.
+type StepError struct {
+	Time float64 // seconds
+	Err  error
+}
+
+func (e *StepError) Error() string { return fmt.Sprintf("at t=%.2f: %v", e.Time, e.Err) }
+func (e *StepError) Unwrap() error { return e.Err }
+
+// invalid returns err as a ValidationError against the given service and edge.
+func invalid(id service.ServiceID, edge graph.EdgeID, err error) error {
+	return &ValidationError{ServiceID: id, EdgeID: edge, Err: err}
+}
+
+// routingError returns err as a RoutingError for svc at its current position.
+func routingError(svc *service.SimService, err error) error {
+	return &RoutingError{ServiceID: svc.ServiceID, EdgeID: svc.CurrentPosition.Edge, Err: err}
+}
+
+// kinematicsError returns err as a KinematicsError for svc.
+func kinematicsError(svc *service.SimService, err error) error {
+	return &KinematicsError{ServiceID: svc.ServiceID, Err: err}
+}
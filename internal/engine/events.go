@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// checkEvent reports whether ev can be applied to the simulation.
+func (t *TMS) checkEvent(ev SimEvent) error {
+	if ev.Time < 0 {
+		return fmt.Errorf("time %v must not be negative", ev.Time)
+	}
+	if ev.Duration < 0 {
+		return fmt.Errorf("duration %v must not be negative", ev.Duration)
+	}
+	switch ev.Type {
+	case EventHoldService, EventReleaseService:
+		if t.service(ev.ServiceID) == nil {
+			return fmt.Errorf("unknown service %q", ev.ServiceID)
+		}
+		return nil
+	}
+	if ev.Duration == 0 {
+		return fmt.Errorf("duration %v must be positive", ev.Duration)
+	}
+	switch ev.Type {
+	case EventTemporarySpeedLimit, EventEdgeClosure:
+		if _, err := t.graph.GetEdgeByID(ev.EdgeID); err != nil {
+			return err
+		}
+		if ev.Type == EventTemporarySpeedLimit && ev.SpeedLimit <= 0 {
+			return fmt.Errorf("speed limit %v must be positive", ev.SpeedLimit)
+		}
+	default:
+		return fmt.Errorf("unknown event type %q", ev.Type)
+	}
+	return nil
+}
+
+// service returns the service with the given ID, or nil if there is none.
+func (t *TMS) service(id service.ServiceID) *service.SimService {
+	i, found := slices.BinarySearchFunc(t.services, id, func(s *service.SimService, id service.ServiceID) int {
+		return strings.Compare(s.ServiceID, id)
+	})
+	if !found {
+		return nil
+	}
+	return t.services[i]
+}
+
+// applyEvents brings into effect the events due by the current timestep and retires
+// those that have run their course. Holds and releases are passed straight to their
+// service.
+func (t *TMS) applyEvents() {
+	t.active = slices.DeleteFunc(t.active, func(ev SimEvent) bool {
+		return ev.Time+ev.Duration <= t.curTime
+	})
+	for len(t.events) > 0 && t.events[0].Time <= t.curTime {
+		ev := t.events[0]
+		t.events = t.events[1:]
+		switch {
+		case ev.Type == EventHoldService && ev.Duration == 0:
+			t.service(ev.ServiceID).HoldUntilReleased()
+			continue
+		case ev.Type == EventHoldService:
+			t.service(ev.ServiceID).Hold(ev.Time + ev.Duration)
+			continue
+		case ev.Type == EventReleaseService:
+			t.service(ev.ServiceID).Release()
+			continue
+		}
+		t.active = append(t.active, ev)
+	}
+}
+
+// HoldService holds service id until ReleaseService is called for it, as a dispatcher
+// holds a train at a signal: brought to a stand where it can if on the move, or kept
+// from moving off if waiting to start or dwelling. It may be called between timesteps
+// (see Step).
+func (t *TMS) HoldService(id service.ServiceID) error {
+	svc := t.service(id)
+	if svc == nil {
+		return fmt.Errorf("unknown service %q", id)
+	}
+	svc.HoldUntilReleased()
+	return nil
+}
+
+// ReleaseService ends every hold on service id, letting it move off again from the next
+// timestep.
+func (t *TMS) ReleaseService(id service.ServiceID) error {
+	svc := t.service(id)
+	if svc == nil {
+		return fmt.Errorf("unknown service %q", id)
+	}
+	svc.Release()
+	return nil
+}
+
+// speedLimit returns the speed limit in force on edge: the lowest of its own limit and
+// any temporary speed limits on it, or +Inf if there is none.
+func (t *TMS) speedLimit(edge graph.Edge) float64 {
+	limit := math.Inf(1)
+	if edge.SpeedLimit != nil {
+		limit = *edge.SpeedLimit
+	}
+	for _, ev := range t.active {
+		if ev.Type == EventTemporarySpeedLimit && ev.EdgeID == edge.ID {
+			limit = math.Min(limit, ev.SpeedLimit)
+		}
+	}
+	return limit
+}
+
+// closed reports whether edge id is closed.
+func (t *TMS) closed(id graph.EdgeID) bool {
+	return slices.ContainsFunc(t.active, func(ev SimEvent) bool {
+		return ev.Type == EventEdgeClosure && ev.EdgeID == id
+	})
+}
+
+// distanceToClosedEdge returns the distance from svc's front to the start of the first
+// closed edge on its path to its next stop that it can still stop short of (see
+// tooLate), or +Inf if there is none. A service already on a closed edge when it closes
+// may run on off it, and so may one that closes too late for it to stop; one whose front
+// is only at its start, at a stand, has not entered it.
+func (t *TMS) distanceToClosedEdge(svc *service.SimService) (float64, error) {
+	if len(t.active) == 0 {
+		return math.Inf(1), nil
+	}
+	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
+	if err != nil {
+		return 0, err
+	}
+	path, err := t.pathAhead(svc)
+	if err != nil {
+		return 0, err
+	}
+	offset := edge.Length - svc.CurrentPosition.DistanceAlongEdge
+	if svc.CurrentPosition.DistanceAlongEdge == 0 {
+		path, offset = append([]graph.Edge{edge}, path...), 0
+	}
+	for _, e := range path {
+		if t.closed(e.ID) {
+			late, err := t.tooLate(svc, offset)
+			if err != nil || !late {
+				return offset, err
+			}
+		}
+		offset += e.Length
+	}
+	return math.Inf(1), nil
+}
+
+// tooLate reports whether a hold point offset metres ahead of svc's front, that has only
+// now come into force, lies within svc's braking distance, so that it cannot stop short
+// of it and must run on through.
+func (t *TMS) tooLate(svc *service.SimService, offset float64) (bool, error) {
+	m, err := t.motionModel(svc)
+	if err != nil {
+		return false, err
+	}
+	return offset+holdTolerance < m.BrakingDistance(svc.Velocity), nil
+}
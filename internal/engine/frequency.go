@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// FrequencySpec is a service pattern given by its frequency rather than departure by
+// departure: a service every Headway seconds from Start until End, on the RouteLibrary
+// route Route, from InitialPosition. Times in the route's stops are those of the
+// departure at Start, and are shifted by the headway for each later one.
+type FrequencySpec struct {
+	// ID prefixes the IDs of the services, which are suffixed with their departure times
+	// of day (see frequencyServiceID); "" = Route.
+	ID              string          `json:"id,omitempty"`
+	Route           string          `json:"route"`
+	InitialPosition graph.NodeID    `json:"initial_position"`
+	Vehicle         service.Vehicle `json:"vehicle"`
+	Priority        int             `json:"priority,omitempty"`
+	Start           float64         `json:"start"`   // seconds
+	End             float64         `json:"end"`     // seconds; departures before it
+	Headway         float64         `json:"headway"` // seconds
+}
+
+// maxFrequencyServices is the most services one FrequencySpec may expand into, well
+// beyond any sensible timetable; more means headway is mistakenly tiny.
+const maxFrequencyServices = 100_000
+
+// withFrequencies returns input with the services of each of its Frequencies appended
+// to its ServiceList, and the problems with any that cannot be expanded. input itself is
+// left as it is.
+func (input SimulationInput) withFrequencies() (SimulationInput, []error) {
+	if len(input.Frequencies) == 0 {
+		return input, nil
+	}
+	var errs []error
+	services := make([]service.Service, len(input.ServiceList), len(input.ServiceList)+len(input.Frequencies))
+	copy(services, input.ServiceList)
+	for i, f := range input.Frequencies {
+		expanded, err := input.expand(f)
+		if err != nil {
+			errs = append(errs, invalid("", "", fmt.Errorf("frequency %d (%s): %w", i, f.Route, err)))
+			continue
+		}
+		services = append(services, expanded...)
+	}
+	input.ServiceList = services
+	return input, errs
+}
+
+// expand returns the services of f, one per departure.
+func (input SimulationInput) expand(f FrequencySpec) ([]service.Service, error) {
+	switch {
+	case f.Headway <= 0:
+		return nil, fmt.Errorf("headway %v must be positive", f.Headway)
+	case f.Start < 0:
+		return nil, fmt.Errorf("start %v must not be negative", f.Start)
+	case f.End < f.Start:
+		return nil, fmt.Errorf("end %v must not be before start %v", f.End, f.Start)
+	case (f.End-f.Start)/f.Headway > maxFrequencyServices:
+		return nil, fmt.Errorf("headway %v from %v to %v is more than %d services", f.Headway, f.Start, f.End, maxFrequencyServices)
+	}
+	stops, ok := input.RouteLibrary[f.Route]
+	if !ok {
+		return nil, fmt.Errorf("route %q is not in the route library", f.Route)
+	}
+	prefix := f.ID
+	if prefix == "" {
+		prefix = f.Route
+	}
+
+	var services []service.Service
+	for n := 0; ; n++ {
+		dep := f.Start + float64(n)*f.Headway
+		if dep >= f.End {
+			break
+		}
+		route := make([]service.RouteStop, len(stops))
+		for i, stop := range stops {
+			stop.ScheduledArrival = shifted(stop.ScheduledArrival, dep-f.Start)
+			stop.ScheduledDeparture = shifted(stop.ScheduledDeparture, dep-f.Start)
+			route[i] = stop
+		}
+		services = append(services, service.Service{
+			ServiceID:       frequencyServiceID(prefix, dep),
+			InitialPosition: f.InitialPosition,
+			Route:           route,
+			Vehicle:         f.Vehicle,
+			DepartureDelay:  dep,
+			Priority:        f.Priority,
+		})
+	}
+	return services, nil
+}
+
+// frequencyServiceID returns the ID of the service departing at dep (seconds) in a
+// frequency prefixed prefix: the prefix and the departure's time of day, taking
+// simulation time as seconds since midnight, as "X-0605", or "X-060530" off the minute.
+func frequencyServiceID(prefix string, dep float64) string {
+	s := int(math.Round(dep))
+	if s%60 != 0 {
+		return fmt.Sprintf("%s-%02d%02d%02d", prefix, s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%s-%02d%02d", prefix, s/3600, s/60%60)
+}
+
+// shifted returns a copy of t moved by d seconds, or nil if t is nil.
+func shifted(t *float64, d float64) *float64 {
+	if t == nil {
+		return nil
+	}
+	v := *t + d
+	return &v
+}
@@ -0,0 +1,33 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+)
+
+// PositionsGeoJSON returns the services in row as GeoJSON Points at their fronts,
+// interpolated along their edges between the locations of the edges' ends (see
+// graph.Graph.PositionCoordinate), for drawing over the network's ToGeoJSON. Each
+// point's properties are the service's ID, state, velocity (in SpeedUnit), next stop and
+// edge.
+func (t *TMS) PositionsGeoJSON(row SimulationLogRow) (graph.FeatureCollection, error) {
+	features := make([]graph.Feature, 0, len(row.ServiceLogs))
+	for _, sl := range row.ServiceLogs {
+		c, err := t.graph.PositionCoordinate(sl.CurrentPosition)
+		if err != nil {
+			return graph.FeatureCollection{}, fmt.Errorf("service %q: %w", sl.ServiceID, err)
+		}
+		features = append(features, graph.PointFeature(c, map[string]any{
+			"service_id": sl.ServiceID,
+			"state":      sl.State,
+			"velocity":   sl.Velocity,
+			"next_stop":  sl.NextStop,
+			"edge":       sl.CurrentPosition.Edge,
+		}))
+	}
+	return graph.NewFeatureCollection(features), nil
+}
+
+// NetworkGeoJSON returns the simulation's network as GeoJSON (see graph.Graph.ToGeoJSON).
+func (t *TMS) NetworkGeoJSON() graph.FeatureCollection { return t.graph.ToGeoJSON() }
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"math"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// edgeEntry records the last service whose front entered an edge, and when.
+type edgeEntry struct {
+	service service.ServiceID
+	time    float64
+}
+
+// enterEdge records svc's front passing the start of edge id, for headway enforcement.
+func (t *TMS) enterEdge(svc *service.SimService, id graph.EdgeID) {
+	if t.meta.MinHeadway > 0 {
+		t.entries[id] = edgeEntry{service: svc.ServiceID, time: t.curTime}
+	}
+}
+
+// distanceToHeadway returns the distance from svc's front to the start of the first edge
+// on its path to its next stop that another service entered less than the minimum
+// headway ago and that it can still stop short of (see tooLate), or +Inf if there is
+// none. A front drawn up at the very start of its current edge has yet to enter it.
+func (t *TMS) distanceToHeadway(svc *service.SimService) (float64, error) {
+	if t.meta.MinHeadway <= 0 {
+		return math.Inf(1), nil
+	}
+	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
+	if err != nil {
+		return 0, err
+	}
+	path, err := t.pathAhead(svc)
+	if err != nil {
+		return 0, err
+	}
+	offset := edge.Length - svc.CurrentPosition.DistanceAlongEdge
+	if svc.CurrentPosition.DistanceAlongEdge == 0 {
+		path, offset = append([]graph.Edge{edge}, path...), 0
+	}
+	for _, e := range path {
+		entry, ok := t.entries[e.ID]
+		if ok && entry.service != svc.ServiceID && t.curTime < entry.time+t.meta.MinHeadway {
+			late, err := t.tooLate(svc, offset)
+			if err != nil || !late {
+				return offset, err
+			}
+		}
+		offset += e.Length
+	}
+	return math.Inf(1), nil
+}
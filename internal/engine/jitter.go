@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// checkJitter reports a problem with j, the named jitter in SimulationMeta.
+func checkJitter(name string, j *Jitter) error {
+	if j == nil {
+		return nil
+	}
+	switch j.Distribution {
+	case DistributionNormal, DistributionUniform:
+	default:
+		return fmt.Errorf("%s: unknown distribution %q", name, j.Distribution)
+	}
+	if j.Spread < 0 {
+		return fmt.Errorf("%s: spread %v must not be negative", name, j.Spread)
+	}
+	return nil
+}
+
+// newRand returns the random number source for a run seeded with seed.
+func newRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewPCG(uint64(seed), 0))
+}
+
+// draw returns nominal perturbed by j, drawing from rng, or nominal itself if j is nil.
+func draw(rng *rand.Rand, j *Jitter, nominal float64) float64 {
+	if j == nil {
+		return nominal
+	}
+	var d float64
+	switch j.Distribution {
+	case DistributionNormal:
+		d = rng.NormFloat64() * j.Spread
+	case DistributionUniform:
+		d = (2*rng.Float64() - 1) * j.Spread
+	}
+	return max(0, nominal+d)
+}
+
+// jitterDwells has svc draw its dwells under the run's DwellJitter, if any.
+func (t *TMS) jitterDwells(svc *service.SimService) {
+	if j := t.meta.DwellJitter; j != nil {
+		svc.SetDwellJitter(func(nominal float64) float64 { return draw(t.rng, j, nominal) })
+	}
+}
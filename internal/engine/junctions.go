@@ -0,0 +1,283 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// movement is a passage through a node: the edge arrived on and the edge left by.
+type movement struct {
+	in, out graph.EdgeID
+}
+
+// junctionLock records the service holding a junction node and the movement it is
+// making through it.
+type junctionLock struct {
+	holder service.ServiceID
+	movement
+}
+
+// junctionsSpanned returns the junction nodes svc's body straddles, with the movement
+// it is making through each (see nodesSpanned).
+func (t *TMS) junctionsSpanned(svc *service.SimService) (map[graph.NodeID]movement, error) {
+	return t.nodesSpanned(svc, t.graph.IsJunction)
+}
+
+// junctionsAhead calls fn for each junction node on svc's path to its next stop (see
+// nodesAhead).
+func (t *TMS) junctionsAhead(svc *service.SimService, fn func(n graph.NodeID, offset float64, mv movement) bool) error {
+	return t.nodesAhead(svc, t.graph.IsJunction, fn)
+}
+
+// nodesSpanned returns the nodes for which keep reports true that svc's body straddles,
+// with the movement it is making through each. A front drawn up at the very start of its
+// current edge straddles the node behind it.
+func (t *TMS) nodesSpanned(svc *service.SimService, keep func(graph.NodeID) bool) (map[graph.NodeID]movement, error) {
+	zone, err := t.occupiedZone(svc, 0)
+	if err != nil {
+		return nil, err
+	}
+	spanned := make(map[graph.NodeID]movement)
+	// The zone runs backwards from the front, so each segment follows the next one.
+	for i := 0; i+1 < len(zone); i++ {
+		prev, err := t.graph.GetEdgeByID(zone[i+1].Edge)
+		if err != nil {
+			return nil, err
+		}
+		if keep(prev.V) {
+			spanned[prev.V] = movement{in: prev.ID, out: zone[i].Edge}
+		}
+	}
+	behind, ok, err := t.edgeBehind(svc)
+	if err != nil {
+		return nil, err
+	}
+	if ok && keep(behind.V) {
+		spanned[behind.V] = movement{in: behind.ID, out: svc.CurrentPosition.Edge}
+	}
+	return spanned, nil
+}
+
+// nodesAhead calls fn for each node for which keep reports true on svc's path to its next
+// stop, in order, with the distance from svc's front to the node and the movement through
+// it, until fn returns false. A front drawn up at the very start of its current edge has
+// yet to pass the node behind it, which comes first, at offset 0. The next stop itself is
+// skipped: the movement out of it is not known until the service departs.
+func (t *TMS) nodesAhead(svc *service.SimService, keep func(graph.NodeID) bool, fn func(n graph.NodeID, offset float64, mv movement) bool) error {
+	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
+	if err != nil {
+		return err
+	}
+	path, err := t.pathAhead(svc)
+	if err != nil {
+		return err
+	}
+	offset := edge.Length - svc.CurrentPosition.DistanceAlongEdge
+	prev := edge
+	behind, ok, err := t.edgeBehind(svc)
+	if err != nil {
+		return err
+	}
+	if ok {
+		path, offset, prev = append([]graph.Edge{edge}, path...), 0, behind
+	}
+	for _, e := range path {
+		if keep(prev.V) && !fn(prev.V, offset, movement{in: prev.ID, out: e.ID}) {
+			return nil
+		}
+		offset += e.Length
+		prev = e
+	}
+	return nil
+}
+
+// edgeBehind returns the edge svc's front has just come off, and true, if the front is
+// drawn up at the very start of its current edge, on the node between the two.
+func (t *TMS) edgeBehind(svc *service.SimService) (graph.Edge, bool, error) {
+	if svc.CurrentPosition.DistanceAlongEdge != 0 || len(svc.Trail) == 0 {
+		return graph.Edge{}, false, nil
+	}
+	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
+	if err != nil {
+		return graph.Edge{}, false, err
+	}
+	behind, err := t.graph.GetEdgeByID(svc.Trail[len(svc.Trail)-1])
+	if err != nil {
+		return graph.Edge{}, false, err
+	}
+	return behind, behind.V == edge.U, nil
+}
+
+// passage is a movement another service is making through a junction: one its body
+// straddles, or one it is approaching.
+type passage struct {
+	movement
+	straddling bool
+}
+
+// junctionTraffic returns, for each junction node on svc's path to its next stop, the
+// movements services other than svc are making through it: those their bodies
+// straddle, and those moving higher-priority services are approaching within braking
+// distance from top speed. Only the services the occupancy index has on the edges
+// leading to those junctions, as far as the traffic reach upstream (see trafficReach),
+// are considered.
+func (t *TMS) junctionTraffic(svc *service.SimService) (map[graph.NodeID][]passage, error) {
+	var edges []graph.EdgeID
+	err := t.junctionsAhead(svc, func(n graph.NodeID, _ float64, _ movement) bool {
+		edges = append(edges, t.approachEdges(n, "", t.trafficReach)...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	traffic := make(map[graph.NodeID][]passage)
+	for _, other := range t.occupancy.on(edges) {
+		if other == svc || other.Finished() {
+			continue
+		}
+		nodes, err := t.junctionsSpanned(other)
+		if err != nil {
+			return nil, err
+		}
+		for n, mv := range nodes {
+			traffic[n] = append(traffic[n], passage{movement: mv, straddling: true})
+		}
+
+		if other.Priority <= svc.Priority || other.Velocity <= 0 {
+			continue
+		}
+		m, err := t.motionModel(other)
+		if err != nil {
+			return nil, err
+		}
+		horizon := m.BrakingDistance(m.VMax())
+		err = t.junctionsAhead(other, func(n graph.NodeID, offset float64, mv movement) bool {
+			if offset >= horizon {
+				return false
+			}
+			traffic[n] = append(traffic[n], passage{movement: mv})
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return traffic, nil
+}
+
+// updateTrafficReach sets how far upstream of a junction junctionTraffic looks for the
+// services approaching it for the timestep about to run: the ConflictHorizon if one is
+// set, else the longest braking distance from top speed of any service.
+func (t *TMS) updateTrafficReach() error {
+	if !t.graph.HasJunctions() {
+		return nil
+	}
+	if t.meta.ConflictHorizon > 0 {
+		t.trafficReach = t.meta.ConflictHorizon
+		return nil
+	}
+	t.trafficReach = 0
+	for _, svc := range t.services {
+		if svc.Finished() {
+			continue
+		}
+		m, err := t.motionModel(svc)
+		if err != nil {
+			return kinematicsError(svc, fmt.Errorf("service %q motion model: %w", svc.ServiceID, err))
+		}
+		t.trafficReach = math.Max(t.trafficReach, m.BrakingDistance(m.VMax()))
+	}
+	return nil
+}
+
+// junctionClosed reports whether svc must hold short of junction n to make movement mv:
+// another service holds it for a different movement, or other traffic (see
+// junctionTraffic) through it differs from mv. A junction svc holds itself is open to it
+// unless another service's body straddles it on a different movement.
+func (t *TMS) junctionClosed(svc *service.SimService, n graph.NodeID, mv movement, traffic map[graph.NodeID][]passage) bool {
+	lock, held := t.junctionLocks[n]
+	own := held && lock.holder == svc.ServiceID
+	if held && !own && lock.movement != mv {
+		return true
+	}
+	for _, other := range traffic[n] {
+		if other.movement != mv && (other.straddling || !own) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateJunctionLocks refreshes the junction locks svc holds, in the same way as
+// updateBlockHolds: a service locks the junctions its body straddles and, while running
+// ahead, those ahead within its hold reach (see holdReach), up to the first that is closed
+// to it (see junctionClosed).
+func (t *TMS) updateJunctionLocks(svc *service.SimService) error {
+	if !t.graph.HasJunctions() {
+		return nil
+	}
+	claimed, err := t.junctionsSpanned(svc)
+	if err != nil {
+		return err
+	}
+	if runningAhead(svc) {
+		traffic, err := t.junctionTraffic(svc)
+		if err != nil {
+			return err
+		}
+		m, err := t.motionModel(svc)
+		if err != nil {
+			return err
+		}
+		reach := holdReach(svc, m, svc.TopSpeed(), t.meta.TimeStep)
+		err = t.junctionsAhead(svc, func(n graph.NodeID, offset float64, mv movement) bool {
+			if offset >= reach || t.junctionClosed(svc, n, mv, traffic) {
+				return false
+			}
+			claimed[n] = mv
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for n, lock := range t.junctionLocks {
+		if _, ok := claimed[n]; lock.holder == svc.ServiceID && !ok {
+			delete(t.junctionLocks, n)
+		}
+	}
+	for n, mv := range claimed {
+		if lock, held := t.junctionLocks[n]; !held || lock.holder == svc.ServiceID {
+			t.junctionLocks[n] = junctionLock{holder: svc.ServiceID, movement: mv}
+		}
+	}
+	return nil
+}
+
+// distanceToLockedJunction returns the distance from svc's front to the first junction on
+// its path to its next stop that is closed to it (see junctionClosed), or +Inf if there
+// is none. Services making the same movement may follow one another through a junction
+// under the usual movement authority.
+func (t *TMS) distanceToLockedJunction(svc *service.SimService) (float64, error) {
+	if !t.graph.HasJunctions() {
+		return math.Inf(1), nil
+	}
+	traffic, err := t.junctionTraffic(svc)
+	if err != nil {
+		return 0, err
+	}
+	dist := math.Inf(1)
+	err = t.junctionsAhead(svc, func(n graph.NodeID, offset float64, mv movement) bool {
+		if t.junctionClosed(svc, n, mv, traffic) {
+			dist = offset
+			return false
+		}
+		return true
+	})
+	return dist, err
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// crossingInput is a flat crossing at junction J of lines A-J-B and C-J-D, AJ aj metres
+// long, CJ cj metres and JB and JD 500 m, with S1 running from A to B and S2, delay
+// seconds later, from C to D.
+func crossingInput(aj, cj, delay float64) string {
+	return fmt.Sprintf(`{
+		"simulation_meta": {"simulation_id": "crossing", "run_time": 300, "time_step": 1},
+		"graph_data": {
+			"nodes": [{"node_id": "A"}, {"node_id": "B"}, {"node_id": "C"}, {"node_id": "D"}, {"node_id": "J", "junction": true}],
+			"edges": [
+				{"edge_id": "AJ", "u": "A", "v": "J", "length": %v},
+				{"edge_id": "JB", "u": "J", "v": "B", "length": 500},
+				{"edge_id": "CJ", "u": "C", "v": "J", "length": %v},
+				{"edge_id": "JD", "u": "J", "v": "D", "length": 500}
+			]
+		},
+		"service_list": [
+			{"service_id": "S1", "initial_position": "A", "route": [{"node_id": "B", "min_dwell": 0}], "vehicle": "VEHICLE"},
+			{"service_id": "S2", "initial_position": "C", "departure_delay": %v, "route": [{"node_id": "D", "min_dwell": 0}], "vehicle": "VEHICLE"}
+		]
+	}`, aj, cj, delay)
+}
+
+// checkJunctionsClear fails the test if the bodies of two services straddle a junction on
+// different movements.
+func checkJunctionsClear(tb testing.TB, tms *TMS, at float64) {
+	tb.Helper()
+	through := make(map[string]movement)
+	owner := make(map[string]string)
+	for _, svc := range tms.services {
+		if svc.Finished() {
+			continue
+		}
+		spanned, err := tms.junctionsSpanned(svc)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		for n, mv := range spanned {
+			if other, ok := through[n]; ok && other != mv {
+				tb.Fatalf("t=%v: %s and %s both straddle %s, on %v and %v", at, owner[n], svc.ServiceID, n, other, mv)
+			}
+			through[n], owner[n] = mv, svc.ServiceID
+		}
+	}
+}
+
+// fastVehicles are vehicles quicker off the mark than testVehicle, which reach a
+// junction at speed sooner after claiming it.
+var fastVehicles = []string{
+	`{"length": 50, "kinematics": {"model": "constant", "v_max": 20, "a_acc": 1, "a_dcc": 0.7}}`,
+	`{"length": 100, "kinematics": {"model": "constant", "v_max": 30, "a_acc": 1, "a_dcc": 0.5}}`,
+}
+
+// TestJunctionCrossing runs two services over a flat crossing, one starting after the
+// other by a range of delays, and checks they are never on it together and both reach
+// their destinations.
+func TestJunctionCrossing(t *testing.T) {
+	for i, vehicle := range append([]string{testVehicle}, fastVehicles...) {
+		for _, aj := range []float64{500, 1000} {
+			for _, cj := range []float64{200, 400} {
+				for delay := 0.0; delay <= 40; delay++ {
+					t.Run(fmt.Sprint(i, "/", aj, "/", cj, "/", delay), func(t *testing.T) {
+						input := strings.ReplaceAll(crossingInput(aj, cj, delay), `"VEHICLE"`, vehicle)
+						tms := newTestTMS(t, input)
+						err := runChecked(tms, func(row SimulationLogRow) {
+							checkJunctionsClear(t, tms, row.Timestamp)
+							checkNoCollision(t, tms, row.Timestamp)
+						})
+						if err != nil {
+							t.Fatal(err)
+						}
+						for _, svc := range tms.services {
+							if !svc.Finished() {
+								t.Errorf("%s did not finish: %s at %v", svc.ServiceID, svc.State, svc.CurrentPosition)
+							}
+						}
+					})
+				}
+			}
+		}
+	}
+}
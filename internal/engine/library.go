@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// resolved returns input with its Frequencies expanded and its vehicle references
+// resolved, and the problems found doing so. input itself is left as it is.
+func (input SimulationInput) resolved() (SimulationInput, []error) {
+	input, errs := input.withFrequencies()
+	input, vehicleErrs := input.withVehicles()
+	return input, append(errs, vehicleErrs...)
+}
+
+// withVehicles returns input with every vehicle given by reference, as a service's
+// vehicle or a unit of its consist, replaced by the VehicleLibrary entry of that name,
+// which takes the name if it has none of its own. It also returns a problem for each
+// reference to a name not in the library; those are left unresolved. input itself is
+// left as it is.
+func (input SimulationInput) withVehicles() (SimulationInput, []error) {
+	var errs []error
+	resolve := func(svc service.Service, v *service.Vehicle) {
+		if v.Kinem != nil || v.Ref == "" {
+			return
+		}
+		lib, ok := input.VehicleLibrary[v.Ref]
+		if !ok {
+			errs = append(errs, invalid(svc.ServiceID, "", fmt.Errorf("service %q: vehicle %q is not in the vehicle library", svc.ServiceID, v.Ref)))
+			return
+		}
+		if lib.Name == "" {
+			lib.Name = v.Ref
+		}
+		*v = lib
+	}
+
+	input.ServiceList = slices.Clone(input.ServiceList)
+	for i := range input.ServiceList {
+		svc := &input.ServiceList[i]
+		resolve(*svc, &svc.Vehicle)
+		svc.Units = slices.Clone(svc.Units)
+		for j := range svc.Units {
+			resolve(*svc, &svc.Units[j].Unit)
+		}
+	}
+	return input, errs
+}
+
+// UnmarshalJSON implements json.Unmarshaler for SimulationInput. A service's "route" may
+// be given as the ID of a RouteLibrary entry in place of its stops, and is resolved to a
+// copy of that entry's stops as the input is read. A vehicle library entry or service
+// that cannot be read is named in the error.
+func (input *SimulationInput) UnmarshalJSON(data []byte) error {
+	type plain SimulationInput
+	var raw struct {
+		plain
+		VehicleLibrary map[string]json.RawMessage `json:"vehicle_library"`
+		ServiceList    []json.RawMessage          `json:"service_list"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*input = SimulationInput(raw.plain)
+	if raw.VehicleLibrary != nil {
+		input.VehicleLibrary = make(map[string]service.Vehicle, len(raw.VehicleLibrary))
+	}
+	for _, name := range slices.Sorted(maps.Keys(raw.VehicleLibrary)) {
+		var v service.Vehicle
+		if err := json.Unmarshal(raw.VehicleLibrary[name], &v); err != nil {
+			return fmt.Errorf("vehicle library entry %q: %w", name, err)
+		}
+		input.VehicleLibrary[name] = v
+	}
+	input.ServiceList = make([]service.Service, len(raw.ServiceList))
+	for i, data := range raw.ServiceList {
+		var svc struct {
+			service.Service
+			Route json.RawMessage `json:"route"`
+		}
+		if err := json.Unmarshal(data, &svc); err != nil {
+			var id struct {
+				ServiceID service.ServiceID `json:"service_id"`
+			}
+			if json.Unmarshal(data, &id) != nil || id.ServiceID == "" {
+				// Name it by its place in the list instead.
+				return fmt.Errorf("service %d in service_list: %w", i+1, err)
+			}
+			return fmt.Errorf("service %q: %w", id.ServiceID, err)
+		}
+		var ref string
+		if len(svc.Route) > 0 && svc.Route[0] == '"' {
+			if err := json.Unmarshal(svc.Route, &ref); err != nil {
+				return err
+			}
+			stops, ok := input.RouteLibrary[ref]
+			if !ok {
+				return fmt.Errorf("service %q: route %q is not in the route library", svc.ServiceID, ref)
+			}
+			svc.Service.Route = slices.Clone(stops)
+		} else if len(svc.Route) > 0 {
+			if err := json.Unmarshal(svc.Route, &svc.Service.Route); err != nil {
+				return err
+			}
+		}
+		input.ServiceList[i] = svc.Service
+	}
+	return nil
+}
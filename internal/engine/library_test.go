@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestServiceDecodeError checks that a service that cannot be decoded is named in the
+// error by its ID or, failing that, by its place in the list.
+func TestServiceDecodeError(t *testing.T) {
+	tests := []struct {
+		service string
+		want    string
+	}{
+		{`{"service_id": "S1", "priority": "high"}`, `service "S1": `},
+		{`{"service_id": 7, "priority": 1}`, `service 2 in service_list: `},
+		{`["S1"]`, `service 2 in service_list: `},
+	}
+	for _, tt := range tests {
+		input := `{"simulation_meta": {"simulation_id": "x", "run_time": 10, "time_step": 1},
+			"graph_data": {"nodes": [], "edges": []},
+			"service_list": [{"service_id": "S0"}, ` + tt.service + `]}`
+		var in SimulationInput
+		err := json.Unmarshal([]byte(input), &in)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %v, want one naming %q", tt.service, err, tt.want)
+		}
+	}
+}
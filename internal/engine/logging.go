@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// Log modes for SimulationMeta.LogMode.
+const (
+	LogModeFull   = "full"   // every service at every timestep (the default)
+	LogModeEvents = "events" // only services whose state has moved on; see eventful
+)
+
+// checkLogMode reports an unknown SimulationMeta.LogMode.
+func checkLogMode(mode string) error {
+	switch mode {
+	case "", LogModeFull, LogModeEvents:
+		return nil
+	}
+	return fmt.Errorf("unknown log_mode %q", mode)
+}
+
+// filterRow cuts row down to what is to be logged, reporting false if nothing is. With a
+// LogInterval, rows between the sample times are dropped. In LogModeEvents only eventful
+// service logs are kept, and rows left with none are dropped; every service is logged
+// when it first appears. The final timestep is always logged in full, so that the log
+// spans the run. It leaves the filter's state as if the final timestep were not final,
+// so that a run resumed from there (see Resume) logs as an uninterrupted one would.
+func (t *TMS) filterRow(row SimulationLogRow, final bool) (SimulationLogRow, bool) {
+	due := true
+	if t.meta.LogInterval > 0 {
+		// Allow for rounding in the accumulated simulation time.
+		due = row.Timestamp >= t.nextLog-t.meta.TimeStep*1e-6
+		for due && t.nextLog <= row.Timestamp+t.meta.TimeStep*1e-6 {
+			t.nextLog += t.meta.LogInterval
+		}
+	}
+	if !due && !final {
+		return SimulationLogRow{}, false
+	}
+	if t.meta.LogMode != LogModeEvents {
+		return row, true
+	}
+	var logs []service.ServiceLog
+	for _, sl := range row.ServiceLogs {
+		prev, seen := t.logged[sl.ServiceID]
+		keep := due && (!seen || eventful(prev, sl, t.meta.LogVelocityThreshold))
+		if keep {
+			t.logged[sl.ServiceID] = sl
+		}
+		if keep || final {
+			logs = append(logs, sl)
+		}
+	}
+	if len(logs) == 0 {
+		return SimulationLogRow{}, false
+	}
+	return SimulationLogRow{Timestamp: row.Timestamp, ServiceLogs: logs}, true
+}
+
+// eventful reports whether sl, a service's latest log, records an event since prev, the
+// last one logged: a change of state, next stop, edge, direction or passengers on board,
+// an arrival, or, if threshold is positive, a change in velocity of at least threshold.
+func eventful(prev, sl service.ServiceLog, threshold float64) bool {
+	switch {
+	case sl.State != prev.State, sl.NextStop != prev.NextStop,
+		sl.CurrentPosition.Edge != prev.CurrentPosition.Edge,
+		sl.Reversed != prev.Reversed, sl.Passengers != prev.Passengers,
+		sl.ArrivalTime != nil:
+		return true
+	}
+	return threshold > 0 && math.Abs(sl.Velocity-prev.Velocity) >= threshold
+}
+
+// maxOutputPrecision is the most decimal places SimulationMeta.OutputPrecision may ask
+// for; float64 holds no more than this reliably.
+const maxOutputPrecision = 15
+
+// roundRow rounds the figures in row to the output precision, if one is set.
+func (t *TMS) roundRow(row SimulationLogRow) SimulationLogRow {
+	if t.meta.OutputPrecision == nil {
+		return row
+	}
+	scale := math.Pow10(*t.meta.OutputPrecision)
+	round := func(x float64) float64 {
+		if x = math.Round(x*scale) / scale; x == 0 {
+			return 0 // not -0
+		}
+		return x
+	}
+	roundPtr := func(x *float64) *float64 {
+		if x == nil {
+			return nil
+		}
+		r := round(*x)
+		return &r
+	}
+	row.Timestamp = round(row.Timestamp)
+	for i := range row.ServiceLogs {
+		sl := &row.ServiceLogs[i]
+		sl.CurrentPosition.DistanceAlongEdge = round(sl.CurrentPosition.DistanceAlongEdge)
+		sl.Velocity = round(sl.Velocity)
+		sl.RemainingDwell = round(sl.RemainingDwell)
+		sl.TractionEnergy = round(sl.TractionEnergy)
+		sl.RegenEnergy = round(sl.RegenEnergy)
+		sl.Odometer = round(sl.Odometer)
+		sl.Delay = roundPtr(sl.Delay)
+		sl.ArrivalTime = roundPtr(sl.ArrivalTime)
+		if sl.Coordinate != nil {
+			sl.Coordinate = &graph.Coordinate{X: round(sl.Coordinate.X), Y: round(sl.Coordinate.Y)}
+		}
+	}
+	return row
+}
@@ -0,0 +1,261 @@
+package engine
+
+import (
+	"cmp"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// mergeKey is a merge node and the edge out of it that services coming in by different
+// edges take in turn.
+type mergeKey struct {
+	node graph.NodeID
+	out  graph.EdgeID
+}
+
+// plainMerge reports whether node n is a merge (see graph.Graph.IsMerge) that is not a
+// junction. Junctions are left out of merge handling, as their locks already serialise
+// converging movements.
+func (t *TMS) plainMerge(n graph.NodeID) bool {
+	return t.graph.IsMerge(n) && !t.graph.IsJunction(n)
+}
+
+// mergeAhead calls fn for each merge node on svc's path to its next stop that is not a
+// junction, in order, with the distance from svc's front to the node and the movement
+// through it, until fn returns false (see nodesAhead).
+func (t *TMS) mergeAhead(svc *service.SimService, fn func(n graph.NodeID, offset float64, mv movement) bool) error {
+	return t.nodesAhead(svc, t.plainMerge, fn)
+}
+
+// mergeHorizon returns how far ahead of svc's front merges concern it: its reaction
+// distance and braking distance from top speed, plus a timestep's running, or the
+// ConflictHorizon if that is nearer.
+func (t *TMS) mergeHorizon(svc *service.SimService) (float64, error) {
+	m, err := t.motionModel(svc)
+	if err != nil {
+		return 0, err
+	}
+	h := svc.ReactionDistance() + m.BrakingDistance(m.VMax()) + svc.Velocity*t.meta.TimeStep
+	if t.meta.ConflictHorizon > 0 {
+		h = math.Min(h, t.meta.ConflictHorizon)
+	}
+	return h, nil
+}
+
+// mergeArrival is a service's approach to a merge node, offset metres ahead of its front.
+type mergeArrival struct {
+	svc    *service.SimService
+	offset float64
+}
+
+// eta returns the time (seconds) for a's service to reach the merge at its current
+// speed: 0 if its front is already on it, or +Inf if it is standing short of it.
+func (a mergeArrival) eta() float64 {
+	switch {
+	case a.offset <= 0:
+		return 0
+	case a.svc.Velocity <= 0:
+		return math.Inf(1)
+	}
+	return a.offset / a.svc.Velocity
+}
+
+// compare orders a before b if a is due at the merge first, then by priority, higher
+// first, then by ServiceID.
+func (a mergeArrival) compare(b mergeArrival) int {
+	if c := cmp.Compare(a.eta(), b.eta()); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(b.svc.Priority, a.svc.Priority); c != 0 {
+		return c
+	}
+	return strings.Compare(a.svc.ServiceID, b.svc.ServiceID)
+}
+
+// mergeClosed reports whether svc, offset metres short of merge node n, must hold short
+// of it to make movement mv: another service holds the merge for the same edge out,
+// coming in by a different edge; another service coming in by a different edge has its
+// body over n or its front drawn up on it, and so is already through (see
+// mergeStraddled); or, where svc does not hold the merge itself, another service is due
+// there first (see yieldsAt).
+func (t *TMS) mergeClosed(svc *service.SimService, n graph.NodeID, offset float64, mv movement, reach float64) (bool, error) {
+	lock, held := t.mergeLocks[mergeKey{n, mv.out}]
+	if held && lock.holder != svc.ServiceID && lock.in != mv.in {
+		return true, nil
+	}
+	if through, err := t.mergeStraddled(svc, n, mv); err != nil || through {
+		return through, err
+	}
+	if held && lock.holder == svc.ServiceID {
+		return false, nil
+	}
+	return t.yieldsAt(svc, mergeArrival{svc, offset}, n, mv, reach)
+}
+
+// mergeStraddled reports whether a service other than svc, coming in to merge node n by
+// a different edge from mv's to leave by the same one, has its body over n or its front
+// drawn up on it.
+func (t *TMS) mergeStraddled(svc *service.SimService, n graph.NodeID, mv movement) (bool, error) {
+	for _, other := range t.occupancy.on([]graph.EdgeID{mv.out}) {
+		if other == svc || other.Finished() {
+			continue
+		}
+		spanned, err := t.nodesSpanned(other, func(m graph.NodeID) bool { return m == n })
+		if err != nil {
+			return false, err
+		}
+		if otherMv, ok := spanned[n]; ok && otherMv.out == mv.out && otherMv.in != mv.in {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// mergeReach returns how far upstream of a merge node rivals are looked for (see
+// yieldsAt): svc's merge horizon, or the ConflictHorizon if one is set.
+func (t *TMS) mergeReach(horizon float64) float64 {
+	if t.meta.ConflictHorizon > 0 {
+		return t.meta.ConflictHorizon
+	}
+	return horizon
+}
+
+// updateMergeLocks refreshes the merge locks svc holds, in the same way as
+// updateJunctionLocks: a service locks the merges its body straddles and, while running
+// ahead, those ahead within its hold reach (see holdReach) and merge horizon, up to the
+// first that is closed to it (see mergeClosed). Once locked, a merge stays closed to
+// services coming in by other edges until the holder has passed it, so the order of
+// arrival is settled for good once the first of them is within stopping distance.
+func (t *TMS) updateMergeLocks(svc *service.SimService) error {
+	claimed, err := t.nodesSpanned(svc, t.plainMerge)
+	if err != nil {
+		return err
+	}
+	if runningAhead(svc) {
+		horizon, err := t.mergeHorizon(svc)
+		if err != nil {
+			return err
+		}
+		m, err := t.motionModel(svc)
+		if err != nil {
+			return err
+		}
+		reach := math.Min(holdReach(svc, m, svc.TopSpeed(), t.meta.TimeStep), horizon)
+		var closedErr error
+		err = t.mergeAhead(svc, func(n graph.NodeID, offset float64, mv movement) bool {
+			if offset >= reach {
+				return false
+			}
+			closed, err := t.mergeClosed(svc, n, offset, mv, t.mergeReach(horizon))
+			if err != nil || closed {
+				closedErr = err
+				return false
+			}
+			claimed[n] = mv
+			return true
+		})
+		if err = cmp.Or(err, closedErr); err != nil {
+			return err
+		}
+	}
+
+	for key, lock := range t.mergeLocks {
+		if mv, ok := claimed[key.node]; lock.holder == svc.ServiceID && (!ok || mv.out != key.out) {
+			delete(t.mergeLocks, key)
+		}
+	}
+	for n, mv := range claimed {
+		key := mergeKey{n, mv.out}
+		if lock, held := t.mergeLocks[key]; !held || lock.holder == svc.ServiceID {
+			t.mergeLocks[key] = junctionLock{holder: svc.ServiceID, movement: mv}
+		}
+	}
+	return nil
+}
+
+// distanceToMerge returns the distance from svc's front to the first merge node ahead,
+// within its merge horizon, that is closed to it (see mergeClosed), or +Inf if there is
+// none. Once the service it yields to has passed the node, that service is ahead of svc
+// on the common edge, and movement authority keeps them apart as usual.
+func (t *TMS) distanceToMerge(svc *service.SimService) (float64, error) {
+	horizon, err := t.mergeHorizon(svc)
+	if err != nil {
+		return 0, err
+	}
+	dist := math.Inf(1)
+	var closedErr error
+	err = t.mergeAhead(svc, func(n graph.NodeID, offset float64, mv movement) bool {
+		if offset >= horizon {
+			return false
+		}
+		closed, err := t.mergeClosed(svc, n, offset, mv, t.mergeReach(horizon))
+		if err != nil || closed {
+			dist, closedErr = offset, err
+			return false
+		}
+		return true
+	})
+	return dist, cmp.Or(err, closedErr)
+}
+
+// yieldsAt reports whether svc, arriving as at, must yield at merge node n for mv, its
+// movement through it: whether another service approaching n on a different edge, within
+// its own merge horizon, is to leave it by the same edge and is due there first (see
+// mergeArrival.compare). Only the services the occupancy index has on the edges
+// converging on n, as far as reach upstream of it, are considered.
+func (t *TMS) yieldsAt(svc *service.SimService, at mergeArrival, n graph.NodeID, mv movement, reach float64) (bool, error) {
+	for _, other := range t.occupancy.on(t.approachEdges(n, mv.in, reach)) {
+		if other == svc || other.Finished() {
+			continue
+		}
+		horizon, err := t.mergeHorizon(other)
+		if err != nil {
+			return false, err
+		}
+		yield := false
+		err = t.mergeAhead(other, func(m graph.NodeID, offset float64, otherMv movement) bool {
+			if offset >= horizon {
+				return false
+			}
+			if m != n {
+				return true
+			}
+			yield = otherMv.out == mv.out && otherMv.in != mv.in &&
+				(mergeArrival{other, offset}).compare(at) < 0
+			return false
+		})
+		if err != nil || yield {
+			return yield, err
+		}
+	}
+	return false, nil
+}
+
+// approachEdges returns the edges leading into node n, other than skip, and those
+// leading into them in turn, as far as reach upstream of n.
+func (t *TMS) approachEdges(n graph.NodeID, skip graph.EdgeID, reach float64) []graph.EdgeID {
+	// upstream[e] is the least distance from the end of edge e to n.
+	upstream := make(map[graph.EdgeID]float64)
+	var visit func(v graph.NodeID, dist float64)
+	visit = func(v graph.NodeID, dist float64) {
+		for _, id := range t.graph.EdgesInto(v) {
+			if id == skip && v == n {
+				continue
+			}
+			if d, seen := upstream[id]; seen && d <= dist {
+				continue
+			}
+			upstream[id] = dist
+			if e, err := t.graph.GetEdgeByID(id); err == nil && dist+e.Length < reach {
+				visit(e.U, dist+e.Length)
+			}
+		}
+	}
+	visit(n, 0)
+	return slices.Collect(maps.Keys(upstream))
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// checkMergesClear fails the test if services coming in to a merge node of tms by
+// different edges are passing through it at once, or one's front stands on it while
+// another passes.
+func checkMergesClear(tb testing.TB, tms *TMS, at float64) {
+	tb.Helper()
+	through := make(map[graph.NodeID]map[service.ServiceID]movement)
+	for _, svc := range tms.services {
+		if svc.Finished() {
+			continue
+		}
+		spanned, err := tms.nodesSpanned(svc, tms.plainMerge)
+		if err != nil {
+			tb.Fatalf("service %q merges: %v", svc.ServiceID, err)
+		}
+		for n, mv := range spanned {
+			for other, otherMv := range through[n] {
+				if otherMv.out == mv.out && otherMv.in != mv.in {
+					tb.Fatalf("t=%v: %s and %s both at merge %s", at, svc.ServiceID, other, n)
+				}
+			}
+			if through[n] == nil {
+				through[n] = make(map[service.ServiceID]movement)
+			}
+			through[n][svc.ServiceID] = mv
+		}
+	}
+}
+
+// TestMergeConverging runs two services converging on a merge, one starting after the
+// other by a range of delays, so that they arrive together, in either order, or one well
+// after the other, and checks they pass it one at a time and both reach their
+// destinations.
+func TestMergeConverging(t *testing.T) {
+	for i, vehicle := range append([]string{testVehicle}, fastVehicles...) {
+		for _, cm := range []float64{200, 300, 400, 450} {
+			for _, step := range []string{"1", "2"} {
+				for delay := 0.0; delay <= 30; delay++ {
+					t.Run(fmt.Sprint(i, "/", cm, "/", step, "/", delay), func(t *testing.T) {
+						input := strings.ReplaceAll(yMergeInput(cm, delay), `"VEHICLE"`, vehicle)
+						input = strings.Replace(input, `"time_step": 1`, `"time_step": `+step, 1)
+						tms := newTestTMS(t, input)
+						err := runChecked(tms, func(row SimulationLogRow) {
+							checkMergesClear(t, tms, row.Timestamp)
+							checkNoCollision(t, tms, row.Timestamp)
+						})
+						if err != nil {
+							t.Fatal(err)
+						}
+						for _, svc := range tms.services {
+							if !svc.Finished() {
+								t.Errorf("%s did not finish: %s at %v", svc.ServiceID, svc.State, svc.CurrentPosition)
+							}
+						}
+					})
+				}
+			}
+		}
+	}
+}
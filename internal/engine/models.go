@@ -1,6 +1,8 @@
 package engine
 
 import (
+	"math/rand/v2"
+
 	"github.com/cxd309/tms-engine/internal/graph"
 	"github.com/cxd309/tms-engine/internal/service"
 )
@@ -10,6 +12,84 @@ type SimulationMeta struct {
 	SimulationID string  `json:"simulation_id"`
 	RunTime      float64 `json:"run_time"`  // seconds
 	TimeStep     float64 `json:"time_step"` // seconds
+	// Adhesion is the default braking adhesion factor in (0, 1] for edges that do not
+	// set their own; nil = full adhesion.
+	Adhesion *float64 `json:"adhesion,omitempty"`
+	// MinHeadway is the least time (seconds) allowed between services entering the same
+	// edge; 0 = no headway beyond the braking envelope.
+	MinHeadway float64 `json:"min_headway,omitempty"`
+	// SafetyMargin is an overlap (metres) kept clear behind every other service's safety
+	// envelope, on top of it, so that following services keep that much more distance.
+	SafetyMargin float64 `json:"safety_margin,omitempty"`
+	// SignallingMode selects how services are kept apart: SignallingMovingBlock (the
+	// default) or SignallingFixedBlock. Under fixed-block signalling, each edge is divided
+	// into signal sections of BlockLength (metres), or its own Sections, and a service
+	// may not enter a section another service's body lies in.
+	SignallingMode string  `json:"signalling_mode,omitempty"`
+	BlockLength    float64 `json:"block_length,omitempty"`
+	// Congestion, if set, slows services on edges with a Capacity as more services
+	// occupy them (see congestedLimit), a coarser alternative to movement authority for
+	// flow studies on large networks.
+	Congestion bool `json:"congestion,omitempty"`
+	// ConflictHorizon, if set, is how far ahead of its front (metres) a service looks along
+	// its path for other services' safety envelopes; 0 = as far as its next stop. It must
+	// exceed the farthest a service can run in a timestep, or services may run into one
+	// another.
+	ConflictHorizon float64 `json:"conflict_horizon,omitempty"`
+	// Regulation, if set, evens out the gaps between services by holding a service at a
+	// stop while the time since another last left it falls short, by more than
+	// RegulationThreshold (seconds), of the time the next service due there needs to
+	// reach it, rather than running to timetable alone.
+	Regulation          bool    `json:"regulation,omitempty"`
+	RegulationThreshold float64 `json:"regulation_threshold,omitempty"`
+	// ReportSeparations adds to the summary the least separation reached between each
+	// pair of services that ever had one ahead of the other; MinSeparation, if set,
+	// flags those pairs that came closer than it (metres).
+	ReportSeparations bool    `json:"report_separations,omitempty"`
+	MinSeparation     float64 `json:"min_separation,omitempty"`
+	// SubSteps, if above 1, splits a service's timestep into that many substeps while it
+	// approaches a stop or a lower speed limit, resolving where it stops more finely.
+	// The log still has one row per timestep.
+	SubSteps int `json:"sub_steps,omitempty"`
+	// LogMode selects which service logs the output keeps: LogModeFull (the default) or
+	// LogModeEvents.
+	LogMode string `json:"log_mode,omitempty"`
+	// LogVelocityThreshold is, in LogModeEvents, the change in velocity (in SpeedUnit) since a
+	// service was last logged that logs it again; 0 = velocity changes alone do not.
+	LogVelocityThreshold float64 `json:"log_velocity_threshold,omitempty"`
+	// LogInterval, if set, logs only the first timestep at or after each multiple of it
+	// (seconds), and the final timestep, however fine TimeStep is.
+	LogInterval float64 `json:"log_interval,omitempty"`
+	// OutputPrecision, if set, rounds the timestamps and service log figures in the
+	// output to that many decimal places; nil = full precision.
+	OutputPrecision *int `json:"output_precision,omitempty"`
+	// LogCoordinates adds to each service log the absolute coordinate of the service's
+	// front, interpolated between the locations of its edge's end nodes.
+	LogCoordinates bool `json:"log_coordinates,omitempty"`
+	// SpeedUnit is the unit of the speeds in the input and the velocities in the output:
+	// SpeedUnitMetresPerSecond (the default), SpeedUnitKilometresPerHour or
+	// SpeedUnitMilesPerHour. The engine itself works in m/s.
+	SpeedUnit string `json:"speed_unit,omitempty"`
+	// Seed seeds the random numbers drawn for DwellJitter and DepartureJitter, so that a
+	// run with the same input and seed is repeated exactly.
+	Seed int64 `json:"seed,omitempty"`
+	// DwellJitter, if set, draws the time each call with a non-zero dwell needs at random
+	// about its nominal value; DepartureJitter likewise each service's DepartureDelay.
+	DwellJitter     *Jitter `json:"dwell_jitter,omitempty"`
+	DepartureJitter *Jitter `json:"departure_jitter,omitempty"`
+}
+
+// Distributions for Jitter.Distribution.
+const (
+	DistributionNormal  = "normal"  // Spread is the standard deviation
+	DistributionUniform = "uniform" // within Spread either side
+)
+
+// Jitter perturbs a time at random about its nominal value, drawn from Distribution
+// with the given Spread. Times drawn below zero are taken as zero.
+type Jitter struct {
+	Distribution string  `json:"distribution"`
+	Spread       float64 `json:"spread"` // seconds
 }
 
 // SimulationInput is the JSON-serialisable input to the engine.
@@ -17,6 +97,48 @@ type SimulationInput struct {
 	Meta        SimulationMeta    `json:"simulation_meta"`
 	GraphData   graph.GraphData   `json:"graph_data"`
 	ServiceList []service.Service `json:"service_list"`
+	Events      []SimEvent        `json:"events,omitempty"`
+	TSRs        []TSR             `json:"tsrs,omitempty"`
+	// VehicleLibrary holds vehicle types by name, for services and units to refer to by
+	// that name rather than each repeating the whole definition.
+	VehicleLibrary map[string]service.Vehicle `json:"vehicle_library,omitempty"`
+	// RouteLibrary holds routes by ID, for services to refer to by that ID rather than
+	// each repeating the whole list of stops (see UnmarshalJSON).
+	RouteLibrary map[string][]service.RouteStop `json:"route_library,omitempty"`
+	// Frequencies are service patterns expanded into services on top of ServiceList.
+	Frequencies []FrequencySpec `json:"frequencies,omitempty"`
+}
+
+// TSR is a temporary speed restriction over an edge, or a Segment of one, in force from
+// StartTime until EndTime. Unlike an edge's own SpeedLimit it may cover part of the edge,
+// and unlike a temporary_speed_limit event it is known to services approaching it. On a
+// bidirectional edge it applies in both directions. Like edge limits, it applies while a
+// service's front is within it.
+type TSR struct {
+	EdgeID     graph.EdgeID   `json:"edge_id,omitempty"`    // the whole edge
+	Segment    *graph.Segment `json:"segment,omitempty"`    // or part of one
+	SpeedLimit float64        `json:"speed_limit"`          // in SpeedUnit
+	StartTime  float64        `json:"start_time,omitempty"` // seconds
+	EndTime    float64        `json:"end_time,omitempty"`   // seconds; 0 = to the end of the run
+}
+
+// Event types for SimEvent.Type.
+const (
+	EventHoldService         = "hold_service"          // hold ServiceID where it is; Duration 0 = until released
+	EventReleaseService      = "release_service"       // end every hold on ServiceID
+	EventTemporarySpeedLimit = "temporary_speed_limit" // restrict EdgeID to SpeedLimit
+	EventEdgeClosure         = "edge_closure"          // keep services off EdgeID
+)
+
+// SimEvent is a disruption injected into the run: it takes effect at the first timestep
+// at or after Time and lasts for Duration seconds.
+type SimEvent struct {
+	Type       string            `json:"type"`
+	Time       float64           `json:"time"`     // seconds
+	Duration   float64           `json:"duration"` // seconds
+	ServiceID  service.ServiceID `json:"service_id,omitempty"`
+	EdgeID     graph.EdgeID      `json:"edge_id,omitempty"`
+	SpeedLimit float64           `json:"speed_limit,omitempty"` // m/s
 }
 
 // SimulationLogRow is the state of all services at a single simulation timestep.
@@ -27,8 +149,25 @@ type SimulationLogRow struct {
 
 // SimulationLog is the complete output of a simulation run.
 type SimulationLog struct {
-	Meta   SimulationMeta     `json:"simulation_meta"`
-	Output []SimulationLogRow `json:"output"`
+	Meta           SimulationMeta     `json:"simulation_meta"`
+	Output         []SimulationLogRow `json:"output"`
+	TractionEnergy float64            `json:"traction_energy"` // all services, J
+	RegenEnergy    float64            `json:"regen_energy"`    // all services, J
+	// Summary holds aggregate figures for a completed run (see Summarise).
+	Summary *SimulationSummary `json:"summary,omitempty"`
+	// Journeys records each service's calls at stops, including those of a run that
+	// ended early.
+	Journeys []Journey `json:"journeys,omitempty"`
+	// Warnings lists problems with the input that did not stop it running (see
+	// TMS.Warnings).
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Journey is the record of a service's calls at stops over a run, in order. A service
+// still at a stop when the run ends has no departure from it.
+type Journey struct {
+	ServiceID service.ServiceID   `json:"service_id"`
+	Stops     []service.StopEvent `json:"stops"`
 }
 
 // movementAuthority is the distance ahead (metres) a service is authorised to travel.
@@ -37,15 +176,65 @@ type movementAuthority = float64
 // speedLimitInfo carries effective speed limit context derived from the graph for
 // a single service at a single timestep.
 type speedLimitInfo struct {
-	currentMax   float64 // effective speed limit on the current edge (min of vehicle VMax and edge limit)
-	distToChange float64 // distance remaining on the current edge (where the limit may change)
-	nextMax      float64 // effective speed limit on the next edge; 0 if the next stop ends the current edge
+	currentMax   float64 // effective speed limit at the front (min of the service's top speed, edge limit and TSRs)
+	distToChange float64 // distance to the lower limit ahead to brake for first, else to the end of the current edge
+	nextMax      float64 // that limit, else the next edge's; 0 if the next stop ends the current edge
+}
+
+// ProgressFunc reports simulation progress: the timestep just completed and the total
+// run time, both in simulation seconds.
+type ProgressFunc func(curTime, runTime float64)
+
+// RunOption configures a TMS built by RunJSONContext.
+type RunOption func(*TMS)
+
+// WithProgress sets the TMS's Progress callback.
+func WithProgress(fn ProgressFunc) RunOption {
+	return func(t *TMS) { t.Progress = fn }
 }
 
 // TMS simulation engine state.
 type TMS struct {
+	// Progress, if set, is called once after every timestep.
+	Progress ProgressFunc
+	// observers are told of every timestep (see WithObserver).
+	observers []StepObserver
+
 	meta     SimulationMeta
 	graph    *graph.Graph
-	services []*service.SimService
+	services []*service.SimService // by ServiceID, for the log
+	order    []*service.SimService // by descending Priority, then ServiceID, for movement
 	curTime  float64
+	// blockHolds records which service holds each block currently held.
+	blockHolds map[graph.BlockID]service.ServiceID
+	// junctionLocks records which service holds each locked junction node, and how it is
+	// passing through.
+	junctionLocks map[graph.NodeID]junctionLock
+	// mergeLocks records which service holds each locked merge node, by the edge out of
+	// it, and how it is passing through.
+	mergeLocks map[mergeKey]junctionLock
+	// trafficReach is how far upstream of a junction the services approaching it are
+	// looked for in the current timestep (see updateTrafficReach).
+	trafficReach float64
+	// events lists the injected events not yet in effect, by Time; active those in
+	// effect at the current timestep.
+	events []SimEvent
+	active []SimEvent
+	// tsrs holds the TSRs on each edge.
+	tsrs map[graph.EdgeID][]tsr
+	// stalled counts the consecutive timesteps in which no service has made progress.
+	stalled int
+	// rng draws the random numbers for jitter, in the order services move.
+	rng *rand.Rand
+	// occupancy indexes the services by the edges they may occupy.
+	occupancy occupancy
+	// entries records the last service to enter each edge, when MinHeadway is set.
+	entries map[graph.EdgeID]edgeEntry
+	// logged holds each service's last log kept in LogModeEvents.
+	logged map[service.ServiceID]service.ServiceLog
+	// separations holds the least separation yet between each pair of services, when
+	// ReportSeparations is set.
+	separations map[[2]service.ServiceID]Separation
+	// nextLog is the simulation time from which the next row is due under LogInterval.
+	nextLog float64
 }
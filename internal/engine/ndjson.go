@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// RunJSONStream runs a JSON-encoded SimulationInput and writes the log to w as
+// newline-delimited JSON; see RunJSONStreamContext.
+func RunJSONStream(jsonInput string, w io.Writer) error {
+	return RunJSONStreamContext(context.Background(), jsonInput, w)
+}
+
+// RunJSONStreamContext is RunJSONStream with cancellation and RunOptions, as for
+// RunJSONContext. It writes one JSON object per line: first {"simulation_meta": ...},
+// then each SimulationLogRow as soon as it is computed, and finally the run's
+// {"traction_energy": ..., "regen_energy": ...}, with any journeys and warnings, once it
+// completes. Rows already written stay written if the run fails part-way.
+func RunJSONStreamContext(ctx context.Context, jsonInput string, w io.Writer, opts ...RunOption) error {
+	tms, err := NewTMSFromJSON(jsonInput, opts...)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	enc := json.NewEncoder(w)
+	meta := struct {
+		Meta SimulationMeta `json:"simulation_meta"`
+	}{tms.meta}
+	if err := enc.Encode(meta); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	var writeErr error
+	rows, errc := tms.RunStream(ctx)
+	for row := range rows {
+		if writeErr != nil {
+			continue
+		}
+		if err := enc.Encode(row); err != nil {
+			writeErr = fmt.Errorf("writing output: %w", err)
+			cancel()
+		}
+	}
+	runErr := <-errc
+	if writeErr != nil {
+		return writeErr
+	}
+	if runErr != nil {
+		return runErr
+	}
+
+	var totals SimulationLog
+	tms.totalEnergy(&totals)
+	energy := struct {
+		TractionEnergy float64   `json:"traction_energy"`
+		RegenEnergy    float64   `json:"regen_energy"`
+		Journeys       []Journey `json:"journeys,omitempty"`
+		Warnings       []string  `json:"warnings,omitempty"`
+	}{totals.TractionEnergy, totals.RegenEnergy, tms.journeys(), tms.Warnings()}
+	if err := enc.Encode(energy); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
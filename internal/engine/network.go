@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+)
+
+// NetworkCache builds each distinct network once across a batch of inputs, such as
+// scenarios over the same lines, and shares it, frozen, between the simulations over
+// it. It is safe for concurrent use.
+type NetworkCache struct {
+	mu     sync.Mutex
+	graphs map[string]*graph.Graph
+}
+
+// NewNetworkCache returns an empty NetworkCache.
+func NewNetworkCache() *NetworkCache {
+	return &NetworkCache{graphs: make(map[string]*graph.Graph)}
+}
+
+// NewTMS is NewTMS, taking input's network from the cache if an earlier input had the
+// same one.
+func (c *NetworkCache) NewTMS(input SimulationInput) (*TMS, error) {
+	t, errs := buildWith(input, c.graph)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return t, nil
+}
+
+// graph returns the frozen graph built from data, building it if it is not yet cached.
+// Networks are told apart by their JSON, so two that list the same nodes and edges in
+// a different order are built separately.
+func (c *NetworkCache) graph(data graph.GraphData) (*graph.Graph, error) {
+	out, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	key := string(out)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if g, ok := c.graphs[key]; ok {
+		return g, nil
+	}
+	g, err := graph.NewGraph(data)
+	if err != nil {
+		return nil, err
+	}
+	g.Freeze()
+	c.graphs[key] = g
+	return g, nil
+}
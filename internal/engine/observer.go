@@ -0,0 +1,47 @@
+package engine
+
+import "github.com/cxd309/tms-engine/internal/service"
+
+// StepObserver is told of each timestep as the simulation runs, for instrumentation or
+// coupling to external models. Register one with WithObserver.
+type StepObserver interface {
+	// OnStep is called with each timestep's log row, in full whatever the log mode or
+	// interval, once the timestep is complete.
+	OnStep(row SimulationLogRow)
+	// OnServiceStateChange is called, before OnStep, for each service whose state at the
+	// end of the timestep differs from its state at the start; sl is its log in the row.
+	// A state entered and left within one timestep goes unseen. Services created during
+	// the timestep are not reported until they change state in a later one.
+	OnServiceStateChange(sl service.ServiceLog, old, new service.ServiceState)
+}
+
+// WithObserver adds obs to the TMS's observers, which are called in the order added.
+func WithObserver(obs StepObserver) RunOption {
+	return func(t *TMS) { t.observers = append(t.observers, obs) }
+}
+
+// statesBefore returns each service's state at the start of a timestep, or nil if the
+// TMS has no observers to tell of changes to them.
+func (t *TMS) statesBefore() map[service.ServiceID]service.ServiceState {
+	if len(t.observers) == 0 {
+		return nil
+	}
+	states := make(map[service.ServiceID]service.ServiceState, len(t.services))
+	for _, svc := range t.services {
+		states[svc.ServiceID] = svc.State
+	}
+	return states
+}
+
+// notify tells the observers of row, the log of the timestep just run, and of the
+// changes of state since before.
+func (t *TMS) notify(before map[service.ServiceID]service.ServiceState, row SimulationLogRow) {
+	for _, obs := range t.observers {
+		for _, sl := range row.ServiceLogs {
+			if old, ok := before[sl.ServiceID]; ok && old != sl.State {
+				obs.OnServiceStateChange(sl, old, sl.State)
+			}
+		}
+		obs.OnStep(row)
+	}
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// occupancy indexes services by the edges their occupied zones may lie on: the edge
+// under each one's front and those on its trail. It is brought up to date whenever a
+// service changes edge or trail, so the services on a stretch of track can be found
+// without visiting every service.
+type occupancy struct {
+	byEdge map[graph.EdgeID][]*service.SimService
+	edges  map[*service.SimService][]graph.EdgeID
+}
+
+func newOccupancy() occupancy {
+	return occupancy{
+		byEdge: make(map[graph.EdgeID][]*service.SimService),
+		edges:  make(map[*service.SimService][]graph.EdgeID),
+	}
+}
+
+// update re-indexes svc under its current edge and trail, or drops it once it has
+// finished.
+func (o occupancy) update(svc *service.SimService) {
+	for _, id := range o.edges[svc] {
+		o.byEdge[id] = slices.DeleteFunc(o.byEdge[id], func(s *service.SimService) bool { return s == svc })
+		if len(o.byEdge[id]) == 0 {
+			delete(o.byEdge, id)
+		}
+	}
+	delete(o.edges, svc)
+	if svc.Finished() {
+		return
+	}
+
+	edges := append([]graph.EdgeID{svc.CurrentPosition.Edge}, svc.Trail...)
+	slices.Sort(edges)
+	edges = slices.Compact(edges)
+	for _, id := range edges {
+		o.byEdge[id] = append(o.byEdge[id], svc)
+	}
+	o.edges[svc] = edges
+}
+
+// on returns the services indexed on any of edges, each once, by ServiceID.
+func (o occupancy) on(edges []graph.EdgeID) []*service.SimService {
+	var found []*service.SimService
+	seen := make(map[*service.SimService]bool)
+	for _, id := range edges {
+		for _, svc := range o.byEdge[id] {
+			if !seen[svc] {
+				seen[svc] = true
+				found = append(found, svc)
+			}
+		}
+	}
+	slices.SortFunc(found, func(a, b *service.SimService) int {
+		return strings.Compare(a.ServiceID, b.ServiceID)
+	})
+	return found
+}
@@ -0,0 +1,183 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// couplingGap is the largest gap (metres) across which a service drawn up behind its
+// partner couples to it.
+const couplingGap = 0.01
+
+// checkPortions reports every coupling on the services' routes without its counterpart:
+// a stop that couples a partner needs that partner to join the service at the same node,
+// and vice versa.
+func checkPortions(defs []service.Service) error {
+	byID := make(map[service.ServiceID]service.Service, len(defs))
+	for _, svc := range defs {
+		byID[svc.ServiceID] = svc
+	}
+	var errs []error
+	for _, svc := range defs {
+		for _, stop := range svc.Route {
+			if stop.Couple != "" && stop.Join != "" {
+				errs = append(errs, fmt.Errorf("service %q: stop %q both couples and joins", svc.ServiceID, stop.NodeID))
+				continue
+			}
+			partner, counterpart := stop.Couple, func(s service.RouteStop) service.ServiceID { return s.Join }
+			if stop.Join != "" {
+				partner, counterpart = stop.Join, func(s service.RouteStop) service.ServiceID { return s.Couple }
+			}
+			if partner == "" {
+				continue
+			}
+			other, ok := byID[partner]
+			if !ok {
+				errs = append(errs, fmt.Errorf("service %q: stop %q: unknown partner %q", svc.ServiceID, stop.NodeID, partner))
+				continue
+			}
+			if !slices.ContainsFunc(other.Route, func(s service.RouteStop) bool {
+				return s.NodeID == stop.NodeID && counterpart(s) == svc.ServiceID
+			}) {
+				errs = append(errs, fmt.Errorf("service %q: stop %q: partner %q has no matching stop", svc.ServiceID, stop.NodeID, partner))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// gapTo returns the distance from svc's front to other's rear along svc's path to its
+// next stop, and whether any of other's body lies ahead on that path.
+func (t *TMS) gapTo(svc, other *service.SimService) (float64, bool, error) {
+	ahead, err := t.corridor(svc)
+	if err != nil {
+		return 0, false, err
+	}
+	return t.gapAlong(ahead, other)
+}
+
+// gapAlong is gapTo along ahead, the corridor of the service behind.
+func (t *TMS) gapAlong(ahead map[graph.EdgeID]float64, other *service.SimService) (float64, bool, error) {
+	zone, err := t.occupiedZone(other, 0)
+	if err != nil {
+		return 0, false, err
+	}
+	gap, found := math.Inf(1), false
+	for _, seg := range zone {
+		if offset, ok := ahead[seg.Edge]; ok && offset+seg.End > 0 {
+			gap, found = math.Min(gap, offset+seg.Start), true
+		}
+	}
+	return math.Max(0, gap), found, nil
+}
+
+// distanceToPartner returns the distance from svc's front to the rear of the partner it
+// is to couple with at its next stop, if that partner is already waiting there for it.
+func (t *TMS) distanceToPartner(svc *service.SimService) (float64, bool, error) {
+	partner := t.service(svc.NextPartner())
+	if partner == nil || partner.Finished() {
+		return 0, false, nil
+	}
+	if awaiting, _ := partner.AwaitingPartner(); awaiting != svc.ServiceID {
+		return 0, false, nil
+	}
+	return t.gapTo(svc, partner)
+}
+
+// couplePortions couples every service waiting at a stop for a partner that has drawn
+// up right behind it; like arriving at a stop, drawing up brings the partner to a stand.
+// The service whose stop couples the other continues as the joined train, from the front.
+func (t *TMS) couplePortions() error {
+	for _, front := range t.order {
+		id, joins := front.AwaitingPartner()
+		rear := t.service(id)
+		if front.Finished() || rear == nil || rear.Finished() || rear.NextPartner() != front.ServiceID {
+			continue
+		}
+		gap, behind, err := t.gapTo(rear, front)
+		if err != nil {
+			return routingError(rear, fmt.Errorf("service %q coupling: %w", rear.ServiceID, err))
+		}
+		if !behind || gap > couplingGap {
+			continue
+		}
+
+		units := append(slices.Clone(front.UnitList()), rear.UnitList()...)
+		trail := coupledTrail(front, rear)
+		if !joins {
+			front.Trail = trail
+			if err := front.Absorb(rear, units); err != nil {
+				return kinematicsError(front, fmt.Errorf("service %q coupling %q: %w", front.ServiceID, rear.ServiceID, err))
+			}
+			t.occupancy.update(front)
+			t.releaseHolds(rear)
+			continue
+		}
+		rear.CurrentPosition, rear.Trail = front.CurrentPosition, trail
+		rear.ArriveAtStop(t.curTime, t.curTime)
+		if err := rear.Absorb(front, units); err != nil {
+			return kinematicsError(rear, fmt.Errorf("service %q coupling %q: %w", rear.ServiceID, front.ServiceID, err))
+		}
+		t.occupancy.update(rear)
+		t.releaseHolds(front)
+	}
+	return nil
+}
+
+// coupledTrail returns the trail of the train formed when rear couples on behind front:
+// rear's trail, then the track from rear's front up to front's current edge.
+func coupledTrail(front, rear *service.SimService) []graph.EdgeID {
+	trail := slices.Clone(rear.Trail)
+	if rear.CurrentPosition.Edge == front.CurrentPosition.Edge {
+		return trail
+	}
+	trail = append(trail, rear.CurrentPosition.Edge)
+	if i := slices.Index(front.Trail, rear.CurrentPosition.Edge); i >= 0 {
+		return append(trail, front.Trail[i+1:]...)
+	}
+	return append(trail, front.Trail...)
+}
+
+// divide carries out any division due at the stop svc has just arrived at, placing the
+// detached rear portion directly behind what remains of svc.
+func (t *TMS) divide(svc *service.SimService) error {
+	rear, err := svc.Divide(t.curTime)
+	if err != nil || rear == nil {
+		return err
+	}
+	back := svc.Vehicle.Length
+	edgeID, pos := svc.CurrentPosition.Edge, svc.CurrentPosition.DistanceAlongEdge
+	i := len(svc.Trail)
+	for back > pos && i > 0 {
+		back -= pos
+		i--
+		edgeID = svc.Trail[i]
+		e, err := t.graph.GetEdgeByID(edgeID)
+		if err != nil {
+			return err
+		}
+		pos = e.Length
+	}
+	rear.CurrentPosition = graph.Position{Edge: edgeID, DistanceAlongEdge: math.Max(0, pos-back)}
+	rear.Trail = slices.Clone(svc.Trail[:i])
+	t.addService(rear)
+	return nil
+}
+
+// addService adds svc, created mid-run, to the simulation.
+func (t *TMS) addService(svc *service.SimService) {
+	i, _ := slices.BinarySearchFunc(t.services, svc.ServiceID, func(s *service.SimService, id service.ServiceID) int {
+		return strings.Compare(s.ServiceID, id)
+	})
+	t.services = slices.Insert(t.services, i, svc)
+	t.order = slices.Clone(t.services)
+	slices.SortStableFunc(t.order, byPriority)
+	t.occupancy.update(svc)
+	t.jitterDwells(svc)
+}
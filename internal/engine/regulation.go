@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"math"
+	"slices"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// regulate holds svc, calling at a stop, for another dt seconds if Regulation is on and
+// its leading gap there falls short of its following gap by more than the
+// RegulationThreshold (see gaps), so that it waits for the two to even out. A service
+// with no other service ahead of it or behind it at the stop is not held.
+func (t *TMS) regulate(svc *service.SimService, dt float64) error {
+	if !t.meta.Regulation {
+		return nil
+	}
+	n := len(svc.Arrivals)
+	if n == 0 || !callingAt(svc, svc.Arrivals[n-1].NodeID) {
+		return nil // pausing at a hold point, not calling at a stop
+	}
+	leading, following, err := t.gaps(svc, svc.Arrivals[n-1].NodeID)
+	if err != nil {
+		return err
+	}
+	if !math.IsInf(following, 1) && leading+t.meta.RegulationThreshold < following {
+		svc.Hold(t.curTime + dt)
+	}
+	return nil
+}
+
+// gaps returns, for svc calling at stop node, its leading gap, the time since another
+// service last left node, and its following gap, the least time another service whose
+// route calls at node would take to reach it by the shortest path at its top speed.
+// Either is +Inf if there is no such service.
+func (t *TMS) gaps(svc *service.SimService, node graph.NodeID) (float64, float64, error) {
+	leading, following := math.Inf(1), math.Inf(1)
+	for _, other := range t.services {
+		if other == svc {
+			continue
+		}
+		for _, ev := range other.Arrivals {
+			if ev.NodeID == node && ev.Departure != nil {
+				leading = math.Min(leading, t.curTime-*ev.Departure)
+			}
+		}
+		if other.Finished() || callingAt(other, node) || !slices.ContainsFunc(other.Route, func(stop service.RouteStop) bool {
+			return stop.NodeID == node && stop.Calls()
+		}) {
+			continue
+		}
+		dist, err := t.distanceToNextStop(other)
+		if err != nil {
+			return 0, 0, err
+		}
+		path, err := t.graph.GetShortestPath(other.NextStop, node)
+		if err != nil {
+			continue // it cannot get there from here
+		}
+		dist += path.Length
+		if top := other.TopSpeed(); top > 0 {
+			following = math.Min(following, dist/top)
+		}
+	}
+	return leading, following, nil
+}
+
+// callingAt reports whether svc is calling at stop node: it has arrived there and not
+// yet left.
+func callingAt(svc *service.SimService, node graph.NodeID) bool {
+	n := len(svc.Arrivals)
+	return n > 0 && svc.Arrivals[n-1].NodeID == node && svc.Arrivals[n-1].Arrival != nil && svc.Arrivals[n-1].Departure == nil
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Kinds of error reported in ErrorInfo.Kind.
+const (
+	ErrorKindValidation = "validation" // see ValidationError
+	ErrorKindRouting    = "routing"    // see RoutingError
+	ErrorKindKinematics = "kinematics" // see KinematicsError
+	ErrorKindDeadlock   = "deadlock"   // see DeadlockError
+	ErrorKindCancelled  = "cancelled"  // the run was cancelled or timed out
+	ErrorKindInternal   = "internal"   // anything else
+)
+
+// RunResult is the outcome of a run as written by RunJSONResult: the log if it
+// succeeded, or else the error.
+type RunResult struct {
+	Log   *SimulationLog `json:"log,omitempty"`
+	Error *ErrorInfo     `json:"error,omitempty"`
+}
+
+// ErrorInfo describes an error from the engine for callers that cannot inspect it with
+// errors.As, such as the browser.
+type ErrorInfo struct {
+	Kind       string   `json:"kind"`
+	ServiceID  string   `json:"service_id,omitempty"`
+	ServiceIDs []string `json:"service_ids,omitempty"` // deadlocked services
+	EdgeID     string   `json:"edge_id,omitempty"`
+	Time       *float64 `json:"time,omitempty"` // timestep at which the run failed, seconds
+	Message    string   `json:"message"`
+}
+
+// NewErrorInfo describes err, which should come from the engine.
+func NewErrorInfo(err error) *ErrorInfo {
+	info := &ErrorInfo{Kind: ErrorKindInternal, Message: err.Error()}
+	var (
+		step       *StepError
+		validation *ValidationError
+		routing    *RoutingError
+		kinematics *KinematicsError
+		deadlock   *DeadlockError
+	)
+	if errors.As(err, &step) {
+		info.Time = &step.Time
+	}
+	switch {
+	case errors.As(err, &validation):
+		info.Kind, info.ServiceID, info.EdgeID = ErrorKindValidation, validation.ServiceID, validation.EdgeID
+	case errors.As(err, &routing):
+		info.Kind, info.ServiceID, info.EdgeID = ErrorKindRouting, routing.ServiceID, routing.EdgeID
+	case errors.As(err, &kinematics):
+		info.Kind, info.ServiceID = ErrorKindKinematics, kinematics.ServiceID
+	case errors.As(err, &deadlock):
+		info.Kind, info.ServiceIDs = ErrorKindDeadlock, deadlock.ServiceIDs
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		info.Kind = ErrorKindCancelled
+	}
+	return info
+}
+
+// RunJSONResult runs a JSON-encoded SimulationInput like RunJSON, but always returns
+// valid JSON: a RunResult holding either {"log": ...} or {"error": {...}}.
+func RunJSONResult(jsonInput string) string {
+	return RunJSONResultContext(context.Background(), jsonInput)
+}
+
+// RunJSONResultContext is RunJSONResult with cancellation and RunOptions, as for
+// RunJSONContext. A cancelled run reports an error, not the partial log.
+func RunJSONResultContext(ctx context.Context, jsonInput string, opts ...RunOption) string {
+	var result RunResult
+	simLog, err := RunInput(ctx, jsonInput, opts...)
+	if err != nil {
+		result.Error = NewErrorInfo(err)
+	} else {
+		result.Log = &simLog
+	}
+	out, err := json.Marshal(result)
+	if err != nil {
+		out, _ = json.Marshal(RunResult{Error: NewErrorInfo(fmt.Errorf("marshaling output: %w", err))})
+	}
+	return string(out)
+}
@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// pathAhead returns the edges svc will traverse after its current one to reach its next
+// stop: the shortest path through any Via nodes still to pass (see routeFrom), with a
+// specific track picked wherever parallel edges join consecutive nodes (see chooseEdge).
+func (t *TMS) pathAhead(svc *service.SimService) ([]graph.Edge, error) {
+	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
+	if err != nil {
+		return nil, err
+	}
+	if edge.V == svc.NextStop {
+		return nil, nil
+	}
+	path, err := t.routeFrom(svc, edge.V)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]graph.Edge, 0, len(path.EdgeList))
+	for _, shortest := range path.EdgeList {
+		e, err := t.chooseEdge(svc, shortest)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, e)
+	}
+	return edges, nil
+}
+
+// resolveStart checks the StartPosition of svc, if it has one, and sets its
+// InitialPosition, if not given, to the end of the edge it starts on.
+func resolveStart(g *graph.Graph, svc *service.Service) error {
+	pos := svc.StartPosition
+	if pos == nil {
+		return nil
+	}
+	edge, err := g.GetEdgeByID(pos.Edge)
+	if err != nil {
+		return err
+	}
+	switch {
+	case pos.DistanceAlongEdge < 0 || pos.DistanceAlongEdge >= edge.Length:
+		return fmt.Errorf("%v m is not within the %v m of edge %q", pos.DistanceAlongEdge, edge.Length, edge.ID)
+	case svc.InitialPosition == "":
+		svc.InitialPosition = edge.V
+	case svc.InitialPosition != edge.V:
+		return fmt.Errorf("initial_position %q is not the end of edge %q", svc.InitialPosition, edge.ID)
+	}
+	return nil
+}
+
+// checkInitialVelocity reports svc starting the run faster than the speed limit of the
+// edge it starts on.
+func (t *TMS) checkInitialVelocity(svc *service.SimService) error {
+	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
+	if err != nil {
+		return err
+	}
+	if limit := t.speedLimit(edge); svc.Velocity > limit {
+		return fmt.Errorf("initial_velocity %v exceeds the speed limit %v of edge %q", svc.Velocity, limit, edge.ID)
+	}
+	return nil
+}
+
+// routeFrom returns the shortest path from node u to svc's next stop through the Via
+// nodes of the stop it has yet to pass.
+func (t *TMS) routeFrom(svc *service.SimService, u graph.NodeID) (graph.PathInfo, error) {
+	via := svc.PendingVia()
+	if len(via) == 0 {
+		return t.graph.GetShortestPath(u, svc.NextStop)
+	}
+	waypoints := append(append([]graph.NodeID{u}, via...), svc.NextStop)
+	return t.graph.GetRouteThrough(waypoints)
+}
+
+// checkTrack reports any Track edge on svc's route that is not in the network, or that
+// does not join two consecutive nodes of the stop's route through its Via nodes from
+// any stop the service may come from: chooseEdge only honours a pin in place of an edge
+// parallel to it. Via nodes are checked with the route's other waypoints.
+func checkTrack(g *graph.Graph, svc service.Service) error {
+	var errs []error
+	for i, stop := range svc.Route {
+		if len(stop.Track) == 0 {
+			continue
+		}
+		// Pairs of consecutive nodes on the way to the stop from anywhere it is run to from.
+		joined := make(map[[2]graph.NodeID]bool)
+		for _, from := range approaches(svc, i) {
+			route, err := g.GetRouteThrough(append(append([]graph.NodeID{from}, stop.Via...), stop.NodeID))
+			if err != nil {
+				continue // reported with the route's waypoints
+			}
+			for j := 1; j < len(route.Route); j++ {
+				joined[[2]graph.NodeID{route.Route[j-1], route.Route[j]}] = true
+			}
+		}
+		for _, id := range stop.Track {
+			e, err := g.GetEdgeByID(id)
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("stop %q track: %w", stop.NodeID, err))
+			case stop.Calls() && !joined[[2]graph.NodeID{e.U, e.V}]:
+				errs = append(errs, fmt.Errorf("stop %q track: edge %q is not parallel to any edge on the way to the stop", stop.NodeID, id))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// approaches returns the nodes svc may run to stop i of its route from: the stop called
+// at before it, round the end of a looping route, the one after it on a shuttle route,
+// and its initial position if stop i is its first.
+func approaches(svc service.Service, i int) []graph.NodeID {
+	var from []graph.NodeID
+	if _, first, err := service.GetFirstStop(svc); err == nil && first == i {
+		from = append(from, svc.InitialPosition)
+	}
+	n := len(svc.Route)
+	step := func(dir int) {
+		for k := 1; k < n; k++ {
+			j := i + dir*k
+			if !svc.Loop && (j < 0 || j >= n) {
+				return
+			}
+			if stop := svc.Route[(j+n)%n]; stop.Calls() && stop.NodeID != svc.Route[i].NodeID {
+				from = append(from, stop.NodeID)
+				return
+			}
+		}
+	}
+	step(-1)
+	if svc.Shuttle {
+		step(1)
+	}
+	return from
+}
+
+// nextEdge returns the edge svc takes out of node u toward its next stop.
+func (t *TMS) nextEdge(svc *service.SimService, u graph.NodeID) (graph.Edge, error) {
+	path, err := t.routeFrom(svc, u)
+	if err != nil {
+		return graph.Edge{}, err
+	}
+	if len(path.EdgeList) == 0 {
+		return graph.Edge{}, fmt.Errorf("already at destination %q", svc.NextStop)
+	}
+	return t.chooseEdge(svc, path.EdgeList[0])
+}
+
+// chooseEdge returns the edge svc should take in place of shortest, an edge of its shortest
+// path. Where parallel edges join the same pair of nodes it takes the one pinned as the
+// Track to its next stop, if any, or else the one currently occupied by the fewest other
+// services, then the shortest, then the first declared. An edge whose block another
+// service holds, or that is closed, counts as occupied.
+func (t *TMS) chooseEdge(svc *service.SimService, shortest graph.Edge) (graph.Edge, error) {
+	edges := t.graph.GetEdges(shortest.U, shortest.V)
+	if len(edges) < 2 {
+		return shortest, nil
+	}
+	if track := svc.Track(); len(track) > 0 {
+		for _, e := range edges {
+			if slices.Contains(track, e.ID) {
+				return e, nil
+			}
+		}
+	}
+	var best graph.Edge
+	bestCount := -1
+	for _, e := range edges {
+		n, err := t.occupants(svc, e.ID)
+		if err != nil {
+			return graph.Edge{}, err
+		}
+		if bestCount < 0 || n < bestCount || (n == bestCount && e.Length < best.Length) {
+			best, bestCount = e, n
+		}
+	}
+	return best, nil
+}
+
+// occupants returns the number of services other than svc with some part of their body
+// on edge id or elsewhere in its block. A closed edge counts as one more.
+func (t *TMS) occupants(svc *service.SimService, id graph.EdgeID) (int, error) {
+	block, inBlock := t.graph.BlockOf(id)
+	n := 0
+	if t.closed(id) {
+		n++
+	}
+	if holder, held := t.blockHolds[block]; inBlock && held && holder != svc.ServiceID {
+		n++
+	}
+	edges := []graph.EdgeID{id}
+	if inBlock {
+		edges = t.graph.BlockEdges(block)
+	}
+	for _, other := range t.occupancy.on(edges) {
+		if other == svc || other.Finished() || (inBlock && t.blockHolds[block] == other.ServiceID) {
+			continue
+		}
+		zone, err := t.occupiedZone(other, 0)
+		if err != nil {
+			return 0, err
+		}
+		for _, seg := range zone {
+			if b, ok := t.graph.BlockOf(seg.Edge); seg.Edge == id || (inBlock && ok && b == block) {
+				n++
+				break
+			}
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// TestSafetyPassParallel checks that the safety pass comes out the same however many
+// goroutines it runs on.
+func TestSafetyPassParallel(t *testing.T) {
+	tms, err := NewTMS(busyLineInput(t, 300))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := tms.safetyPassOn(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, workers := range []int{2, 3, 8} {
+		got, err := tms.safetyPassOn(workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%d workers: safety pass differs from a serial one", workers)
+		}
+	}
+}
+
+// BenchmarkSafetyPass times the safety pass over a line busy with services, run serially
+// and fanned out over GOMAXPROCS goroutines, to place parallelSafetyServices. Run it with
+// -cpu to compare core counts.
+func BenchmarkSafetyPass(b *testing.B) {
+	for _, services := range []int{16, 64, 128, 256, 512, 1024, 2000} {
+		tms, err := NewTMS(busyLineInput(b, services))
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, mode := range []string{"serial", "parallel"} {
+			b.Run(fmt.Sprint(services, "/", mode), func(b *testing.B) {
+				workers := 1
+				if mode == "parallel" {
+					workers = runtime.GOMAXPROCS(0)
+				}
+				for b.Loop() {
+					if _, err := tms.safetyPassOn(workers); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// Separation is the closest two services came over a run: the least distance from the
+// front of the one behind to the rear of the one ahead, along the path of the one behind
+// to its next stop.
+type Separation struct {
+	Services [2]service.ServiceID `json:"services"` // in ServiceID order
+	Distance float64              `json:"distance"` // metres
+	Time     float64              `json:"time"`     // seconds; when first reached
+	// BelowMinimum is set if Distance is less than SimulationMeta.MinSeparation.
+	BelowMinimum bool `json:"below_minimum,omitempty"`
+}
+
+// recordSeparations notes any new least separation between each service and the others
+// ahead of it on its path to its next stop, projected onto that path as in the MA check.
+// Services yet to start are left out, as several may stand together at an origin.
+func (t *TMS) recordSeparations() error {
+	for _, svc := range t.services {
+		if svc.Finished() || svc.State == service.StateStationary {
+			continue
+		}
+		ahead, err := t.corridor(svc)
+		if err != nil {
+			return routingError(svc, err)
+		}
+		for _, other := range t.occupancy.on(slices.Collect(maps.Keys(ahead))) {
+			if other == svc || other.Finished() || other.State == service.StateStationary {
+				continue
+			}
+			gap, found, err := t.gapAlong(ahead, other)
+			if err != nil {
+				return routingError(svc, err)
+			}
+			if !found {
+				continue
+			}
+			pair := [2]service.ServiceID{svc.ServiceID, other.ServiceID}
+			slices.Sort(pair[:])
+			if sep, ok := t.separations[pair]; !ok || gap < sep.Distance {
+				t.separations[pair] = Separation{Services: pair, Distance: gap, Time: t.curTime}
+			}
+		}
+	}
+	return nil
+}
+
+// separationReport returns the least separations recorded, by pair, flagging those
+// below the MinSeparation.
+func (t *TMS) separationReport() []Separation {
+	report := slices.SortedFunc(maps.Values(t.separations), func(a, b Separation) int {
+		return cmp.Or(cmp.Compare(a.Services[0], b.Services[0]), cmp.Compare(a.Services[1], b.Services[1]))
+	})
+	for i := range report {
+		report[i].BelowMinimum = report[i].Distance < t.meta.MinSeparation
+	}
+	return report
+}
@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// runningAhead reports whether svc is under way toward its next stop, rather than
+// standing or setting back.
+func runningAhead(svc *service.SimService) bool {
+	return moving(svc.State) && svc.SettingBack == 0
+}
+
+// behindEdge is an edge of the track a service came by, with the distance from the
+// service's rear back to the edge's end; negative if the edge runs on under the service.
+type behindEdge struct {
+	graph.Edge
+	offset float64
+}
+
+// trackBehind returns the track svc came by, nearest first: its current edge and then
+// its trail, newest first.
+func (t *TMS) trackBehind(svc *service.SimService) ([]behindEdge, error) {
+	edge, err := t.graph.GetEdgeByID(svc.CurrentPosition.Edge)
+	if err != nil {
+		return nil, err
+	}
+	offset := svc.CurrentPosition.DistanceAlongEdge - edge.Length - svc.Vehicle.Length
+	track := []behindEdge{{edge, offset}}
+	for i := len(svc.Trail) - 1; i >= 0; i-- {
+		offset += track[len(track)-1].Length
+		e, err := t.graph.GetEdgeByID(svc.Trail[i])
+		if err != nil {
+			return nil, err
+		}
+		track = append(track, behindEdge{e, offset})
+	}
+	return track, nil
+}
+
+// setBack proposes, grants and applies the movement of svc, which is setting back (see
+// service.RouteStop.SetBack), over the next dt seconds, given each service's minimal
+// MA. The end of the set back is braked for as a stop is, and the limit setBackAllowed
+// grants as a hold point is. Once there, svc stands for a moment, as at a hold point,
+// before leaving for its next stop. It reports whether svc made progress.
+func (t *TMS) setBack(svc *service.SimService, dt float64, minMAs map[string]movementAuthority) (bool, error) {
+	m, err := t.motionModel(svc)
+	if err != nil {
+		return false, kinematicsError(svc, fmt.Errorf("service %q motion model: %w", svc.ServiceID, err))
+	}
+	limit, err := t.setBackLimit(svc)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q set back speed limit: %w", svc.ServiceID, err))
+	}
+	distToHold, err := t.setBackAllowed(svc, minMAs)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q set back check: %w", svc.ServiceID, err))
+	}
+	progress := false
+	if svc.HeldAt(t.curTime) {
+		// A held service stops as soon as it can, wherever that is.
+		distToHold = math.Min(distToHold, m.BrakingDistance(svc.Velocity))
+		progress = true // the hold will end
+	}
+
+	sl := speedLimitInfo{currentMax: limit, distToChange: math.Inf(1)}
+	proposedDist, newVelocity, newState := proposeMovement(svc, m, dt, svc.SettingBack, distToHold, sl)
+	braking := newVelocity < svc.Velocity && newState != service.StateAccelerating && newState != service.StateCoasting
+	if svc.Reacting(braking, dt) {
+		proposedDist, newVelocity, newState = svc.Velocity*dt, svc.Velocity, svc.State
+	}
+	grantedDist := math.Min(proposedDist, distToHold)
+	if grantedDist < proposedDist {
+		newVelocity, newState = constrainedKinematics(svc, m, grantedDist)
+	}
+
+	// A negative distance sets the service back.
+	v0 := svc.Velocity
+	reached, done, err := t.advancePosition(svc, -grantedDist)
+	if err != nil {
+		return false, routingError(svc, fmt.Errorf("service %q setting back: %w", svc.ServiceID, err))
+	}
+	svc.Odometer += reached
+	progress = progress || grantedDist > 0 || done
+
+	svc.Velocity, svc.State = newVelocity, newState
+	switch {
+	case done:
+		svc.Velocity, svc.State = 0, service.StateDwelling
+	case newState == service.StateDwelling && svc.HeldAt(t.curTime):
+		svc.State = service.StateHeld
+	}
+	svc.AddEnergy(m.EnergyDelta(v0, svc.Velocity, grantedDist, svc.Mass()))
+	return progress, nil
+}
+
+// retreat moves svc's front dist metres back along the track it came by: back along its
+// current edge and, past the edge's start, onto the last edge of its trail. It returns
+// the distance moved, which falls short of dist if svc reached the end of its set back,
+// and whether it did.
+func (t *TMS) retreat(svc *service.SimService, dist float64) (float64, bool, error) {
+	dist = math.Min(dist, svc.SettingBack)
+	moved := 0.0
+	for dist-moved > svc.CurrentPosition.DistanceAlongEdge {
+		moved += svc.CurrentPosition.DistanceAlongEdge
+		n := len(svc.Trail)
+		if n == 0 {
+			return 0, false, fmt.Errorf("no track behind edge %q to set back onto", svc.CurrentPosition.Edge)
+		}
+		prev, err := t.graph.GetEdgeByID(svc.Trail[n-1])
+		if err != nil {
+			return 0, false, err
+		}
+		svc.Trail = svc.Trail[:n-1]
+		svc.CurrentPosition = graph.Position{Edge: prev.ID, DistanceAlongEdge: prev.Length}
+		t.occupancy.update(svc)
+	}
+	svc.CurrentPosition.DistanceAlongEdge -= dist - moved
+	if svc.SettingBack -= dist; svc.SettingBack <= holdTolerance {
+		svc.SettingBack = 0
+		return dist, true, nil
+	}
+	return dist, false, nil
+}
+
+// setBackLimit returns the speed svc may set back at: its top speed, or the lowest speed
+// limit or TSR on any of the track its body covers over what is left of its set back,
+// if lower.
+func (t *TMS) setBackLimit(svc *service.SimService) (float64, error) {
+	track, err := t.trackBehind(svc)
+	if err != nil {
+		return 0, err
+	}
+	limit := svc.TopSpeed()
+	for _, e := range track {
+		if e.offset >= svc.SettingBack {
+			break
+		}
+		limit = math.Min(limit, t.speedLimit(e.Edge))
+		for _, r := range t.tsrs[e.ID] {
+			if r.inForce(t.curTime) {
+				limit = math.Min(limit, r.limit)
+			}
+		}
+	}
+	return limit, nil
+}
+
+// setBackAllowed returns the maximum distance svc may set back, +Inf if unlimited, without
+// its rear entering any other service's safety envelope (see computeMaxAllowedDistance),
+// on the same track in either direction, a block held against it (see blockHeldAgainst),
+// or a junction or merge another service has locked. It fails if the track svc came by, as far as its
+// trail records it, ends before its set back does.
+func (t *TMS) setBackAllowed(svc *service.SimService, minMAs map[string]movementAuthority) (float64, error) {
+	track, err := t.trackBehind(svc)
+	if err != nil {
+		return 0, err
+	}
+	last := track[len(track)-1]
+	if known := last.offset + last.Length; known < svc.SettingBack-holdTolerance {
+		return 0, fmt.Errorf("cannot set back %v m: only %v m of the track it came by lies behind it", svc.SettingBack, math.Max(0, known))
+	}
+
+	own := make(map[graph.EdgeID]behindEdge, len(track))
+	reverse := make(map[graph.EdgeID]behindEdge, len(track))
+	var edges []graph.EdgeID
+	for _, e := range track {
+		own[e.ID] = e
+		edges = append(edges, e.ID)
+		if r, err := t.graph.GetReverseEdge(e.ID); err == nil {
+			reverse[r.ID] = e
+			edges = append(edges, r.ID)
+		}
+	}
+
+	maxDist := math.Inf(1)
+	for _, other := range t.occupancy.on(edges) {
+		if other == svc || other.Finished() {
+			continue
+		}
+		zone, err := t.occupiedZone(other, minMAs[other.ServiceID]+t.meta.SafetyMargin)
+		if err != nil {
+			return 0, err
+		}
+		if other.CurrentPosition.DistanceAlongEdge == 0 {
+			// A front drawn up on a node is not in the zone, but nothing may pass it.
+			zone = append(zone, graph.Segment{Edge: other.CurrentPosition.Edge})
+		}
+		for _, seg := range zone {
+			// The distances from svc's rear back to the segment's nearer and further ends.
+			var near, far float64
+			if e, ok := own[seg.Edge]; ok {
+				near, far = e.offset+e.Length-seg.End, e.offset+e.Length-seg.Start
+			} else if e, ok := reverse[seg.Edge]; ok {
+				near, far = e.offset+seg.Start, e.offset+seg.End
+			} else {
+				continue
+			}
+			if far > 0 {
+				maxDist = math.Min(maxDist, near)
+			}
+		}
+	}
+
+	var blocks []graph.BlockID
+	for _, e := range track {
+		if b, ok := t.graph.BlockOf(e.ID); ok && e.offset >= 0 {
+			blocks = append(blocks, b)
+		}
+	}
+	occupied, err := t.othersOccupiedBlocks(svc, blocks)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range track {
+		if e.offset < 0 || e.offset >= maxDist {
+			continue // under svc already, or beyond what it may reach anyway
+		}
+		b, ok := t.graph.BlockOf(e.ID)
+		if ok && t.blockHeldAgainst(svc, b, occupied) || t.lockedAgainst(svc, e.V) {
+			maxDist = e.offset
+		}
+	}
+
+	return math.Max(0, maxDist), nil
+}
+
+// lockedAgainst reports whether node n is locked, as a junction or a merge, by a service
+// other than svc.
+func (t *TMS) lockedAgainst(svc *service.SimService, n graph.NodeID) bool {
+	if lock, ok := t.junctionLocks[n]; ok && lock.holder != svc.ServiceID {
+		return true
+	}
+	for key, lock := range t.mergeLocks {
+		if key.node == n && lock.holder != svc.ServiceID {
+			return true
+		}
+	}
+	return false
+}
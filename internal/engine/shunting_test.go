@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// setBackInput is a line A-B-C-D, with a branch B-E, over which S1 runs from A to C, sets
+// back setBack metres and runs on to D, and S2 runs from A, delay seconds later, to call
+// at B for a minute on its way to E.
+func setBackInput(setBack float64, delay float64) string {
+	return fmt.Sprintf(`{
+		"simulation_meta": {"simulation_id": "set-back", "run_time": 600, "time_step": 1},
+		"graph_data": {
+			"nodes": [{"node_id": "A"}, {"node_id": "B"}, {"node_id": "C"}, {"node_id": "D"}, {"node_id": "E"}],
+			"edges": [
+				{"edge_id": "AB", "u": "A", "v": "B", "length": 1000},
+				{"edge_id": "BC", "u": "B", "v": "C", "length": 400},
+				{"edge_id": "CD", "u": "C", "v": "D", "length": 1000},
+				{"edge_id": "BE", "u": "B", "v": "E", "length": 500}
+			]
+		},
+		"service_list": [
+			{"service_id": "S1", "initial_position": "A", "route": [{"node_id": "C", "min_dwell": 10, "set_back": %v}, {"node_id": "D", "min_dwell": 0}], "vehicle": "VEHICLE"},
+			{"service_id": "S2", "initial_position": "A", "departure_delay": %v, "route": [{"node_id": "B", "min_dwell": 60}, {"node_id": "E", "min_dwell": 0}], "vehicle": "VEHICLE"}
+		]
+	}`, setBack, delay)
+}
+
+// TestSetBack checks that a service sets back the given distance along the track it came
+// by, onto the edge before, logging a negative speed as it does, before running on.
+func TestSetBack(t *testing.T) {
+	tms := newTestTMS(t, setBackInput(600, 300))
+	lowest := graph.Position{Edge: "BC", DistanceAlongEdge: 400} // from the first backward step on
+	backward := false
+	err := runChecked(tms, func(row SimulationLogRow) {
+		l := row.ServiceLogs[0]
+		if l.Velocity < 0 {
+			backward = true
+			if l.SettingBack <= 0 {
+				t.Errorf("t=%v: S1 runs backward at %v m/s with nothing left to set back", row.Timestamp, l.Velocity)
+			}
+		}
+		if backward && l.CurrentPosition.Edge == "AB" && (lowest.Edge != "AB" || l.CurrentPosition.DistanceAlongEdge < lowest.DistanceAlongEdge) {
+			lowest = l.CurrentPosition
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !backward {
+		t.Error("S1 never logged a negative speed")
+	}
+	if want := (graph.Position{Edge: "AB", DistanceAlongEdge: 800}); math.Abs(lowest.DistanceAlongEdge-want.DistanceAlongEdge) > 1e-6 || lowest.Edge != want.Edge {
+		t.Errorf("S1 set back to %v, want %v", lowest, want)
+	}
+	for _, id := range []service.ServiceID{"S1", "S2"} {
+		if svc := tms.service(id); !svc.Finished() {
+			t.Errorf("%s did not finish: %s at %v", id, svc.State, svc.CurrentPosition)
+		}
+	}
+}
+
+// TestSetBackObstructed checks that a service setting back toward another standing on the
+// track behind it brakes short of it and waits for it to clear, rather than running into
+// it or being stopped dead.
+func TestSetBackObstructed(t *testing.T) {
+	tms := newTestTMS(t, setBackInput(600, 40))
+	var prev SimulationLogRow
+	err := runChecked(tms, func(row SimulationLogRow) {
+		checkNoCollision(t, tms, row.Timestamp)
+		if prev.ServiceLogs != nil {
+			checkNoFreeze(t, prev, row)
+		}
+		prev = row
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []service.ServiceID{"S1", "S2"} {
+		if svc := tms.service(id); !svc.Finished() {
+			t.Errorf("%s did not finish: %s at %v", id, svc.State, svc.CurrentPosition)
+		}
+	}
+}
+
+// TestSetBackTooFar checks that a set back longer than the track the service came by fails
+// the run, and that a negative one is refused outright.
+func TestSetBackTooFar(t *testing.T) {
+	tms := newTestTMS(t, setBackInput(2000, 300))
+	if err := runChecked(tms, nil); err == nil || !strings.Contains(err.Error(), "cannot set back") {
+		t.Errorf("setting back 2000 m: got error %v, want one saying it cannot set back", err)
+	}
+	if _, err := NewTMS(testInput(t, setBackInput(-100, 300))); err == nil {
+		t.Error("NewTMS accepted a negative set_back")
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// Signalling modes for SimulationMeta.SignallingMode.
+const (
+	SignallingMovingBlock = "moving_block" // keep apart by safety envelopes (the default)
+	SignallingFixedBlock  = "fixed_block"  // keep apart by signal sections; see sectionLength
+)
+
+// checkSignallingMode reports an unknown SimulationMeta.SignallingMode.
+func checkSignallingMode(mode string) error {
+	switch mode {
+	case "", SignallingMovingBlock, SignallingFixedBlock:
+		return nil
+	}
+	return fmt.Errorf("unknown signalling_mode %q", mode)
+}
+
+// fixedBlock reports whether services are kept apart by signal sections rather than by
+// safety envelopes.
+func (t *TMS) fixedBlock() bool { return t.meta.SignallingMode == SignallingFixedBlock }
+
+// sectionLength returns the length of the signal sections edge is divided into under
+// fixed-block signalling: its own Sections, else as many as BlockLength needs, else one,
+// all of equal length.
+func (t *TMS) sectionLength(edge graph.Edge) float64 {
+	n := edge.Sections
+	if n == 0 && t.meta.BlockLength > 0 {
+		n = int(math.Ceil(edge.Length / t.meta.BlockLength))
+	}
+	return edge.Length / float64(max(n, 1))
+}
+
+// distanceToOccupiedSection returns, under fixed-block signalling, the distance from svc's
+// front to the start of the first signal section on its path to its next stop that the
+// body of another service lies in, less the SafetyMargin, or +Inf if there is none. A
+// service already level with or behind svc's front is not in its way.
+func (t *TMS) distanceToOccupiedSection(svc *service.SimService) (float64, error) {
+	if !t.fixedBlock() {
+		return math.Inf(1), nil
+	}
+	ahead, err := t.corridor(svc)
+	if err != nil {
+		return 0, err
+	}
+
+	dist := math.Inf(1)
+	for _, other := range t.occupancy.on(slices.Collect(maps.Keys(ahead))) {
+		if other.ServiceID == svc.ServiceID || other.Finished() {
+			continue
+		}
+		zone, err := t.occupiedZone(other, 0)
+		if err != nil {
+			return 0, err
+		}
+		for _, seg := range zone {
+			offset, ok := ahead[seg.Edge]
+			if !ok || offset+seg.End <= 0 {
+				continue
+			}
+			edge, err := t.graph.GetEdgeByID(seg.Edge)
+			if err != nil {
+				return 0, err
+			}
+			section := t.sectionLength(edge)
+			start := math.Floor(seg.Start/section) * section
+			dist = math.Min(dist, offset+start-t.meta.SafetyMargin)
+		}
+	}
+	return math.Max(0, dist), nil
+}
@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// EngineState is the complete state of a simulation between timesteps, as captured by
+// Snapshot and continued from by Resume. It survives a round trip through JSON.
+type EngineState struct {
+	// Time is the simulation time of the next timestep to run, seconds.
+	Time          float64                                  `json:"time"`
+	Services      []service.Snapshot                       `json:"services"` // by ServiceID
+	BlockHolds    map[graph.BlockID]service.ServiceID      `json:"block_holds,omitempty"`
+	JunctionLocks map[graph.NodeID]JunctionLock            `json:"junction_locks,omitempty"`
+	MergeLocks    []MergeLock                              `json:"merge_locks,omitempty"` // by Node, then Out
+	ActiveEvents  []SimEvent                               `json:"active_events,omitempty"`
+	Stalled       int                                      `json:"stalled,omitempty"`
+	EdgeEntries   map[graph.EdgeID]EdgeEntry               `json:"edge_entries,omitempty"`
+	Logged        map[service.ServiceID]service.ServiceLog `json:"logged,omitempty"`
+	NextLog       float64                                  `json:"next_log,omitempty"`
+	Separations   []Separation                             `json:"separations,omitempty"`
+}
+
+// JunctionLock is the service holding a junction node and the edges it is passing
+// between.
+type JunctionLock struct {
+	Holder service.ServiceID `json:"holder"`
+	In     graph.EdgeID      `json:"in"`
+	Out    graph.EdgeID      `json:"out"`
+}
+
+// MergeLock is the service holding a merge node for the edge out of it, and the edges
+// it is passing between.
+type MergeLock struct {
+	Node graph.NodeID `json:"node"`
+	JunctionLock
+}
+
+// EdgeEntry is the last service whose front entered an edge, and when.
+type EdgeEntry struct {
+	ServiceID service.ServiceID `json:"service_id"`
+	Time      float64           `json:"time"` // seconds
+}
+
+// Snapshot returns the simulation's complete current state. Taken after a run, or
+// between timesteps of a stream, it can be passed to Resume to carry on from there.
+func (t *TMS) Snapshot() EngineState {
+	state := EngineState{
+		Time:          t.curTime,
+		BlockHolds:    make(map[graph.BlockID]service.ServiceID, len(t.blockHolds)),
+		JunctionLocks: make(map[graph.NodeID]JunctionLock, len(t.junctionLocks)),
+		ActiveEvents:  slices.Clone(t.active),
+		Stalled:       t.stalled,
+		EdgeEntries:   make(map[graph.EdgeID]EdgeEntry, len(t.entries)),
+		Logged:        make(map[service.ServiceID]service.ServiceLog, len(t.logged)),
+		NextLog:       t.nextLog,
+		Separations:   t.separationReport(),
+	}
+	for _, svc := range t.services {
+		state.Services = append(state.Services, svc.Snapshot())
+	}
+	for id, holder := range t.blockHolds {
+		state.BlockHolds[id] = holder
+	}
+	for id, lock := range t.junctionLocks {
+		state.JunctionLocks[id] = JunctionLock{Holder: lock.holder, In: lock.in, Out: lock.out}
+	}
+	for key, lock := range t.mergeLocks {
+		state.MergeLocks = append(state.MergeLocks, MergeLock{Node: key.node, JunctionLock: JunctionLock{Holder: lock.holder, In: lock.in, Out: lock.out}})
+	}
+	slices.SortFunc(state.MergeLocks, func(a, b MergeLock) int {
+		return cmp.Or(strings.Compare(a.Node, b.Node), strings.Compare(a.Out, b.Out))
+	})
+	for id, entry := range t.entries {
+		state.EdgeEntries[id] = EdgeEntry{ServiceID: entry.service, Time: entry.time}
+	}
+	for id, sl := range t.logged {
+		state.Logged[id] = sl
+	}
+	return state
+}
+
+// Resume constructs a TMS that continues from state, as captured by Snapshot, under
+// input. The graph, meta and events are taken from input and the services from state,
+// so input may differ from the original run to explore what-if continuations, as long
+// as its network still has every edge the services are on. Events in input due at or
+// before the last timestep state has run are taken to have been applied already;
+// state's events in effect carry on. Those still to come must name services in state.
+// Random draws for jitter start afresh from input's seed rather than carrying on from
+// the original run's.
+func Resume(state EngineState, input SimulationInput) (*TMS, error) {
+	// The events are checked against state's services once they are restored, not
+	// input's.
+	events := input.Events
+	input.Events = nil
+	t, err := NewTMS(input)
+	if err != nil {
+		return nil, err
+	}
+
+	t.services = t.services[:0]
+	t.occupancy = newOccupancy()
+	for _, snap := range state.Services {
+		svc := service.Restore(snap)
+		for _, id := range append([]graph.EdgeID{svc.CurrentPosition.Edge}, svc.Trail...) {
+			if _, err := t.graph.GetEdgeByID(id); err != nil {
+				return nil, invalid(svc.ServiceID, id, fmt.Errorf("service %q: %w", svc.ServiceID, err))
+			}
+		}
+		t.services = append(t.services, svc)
+		t.occupancy.update(svc)
+		t.jitterDwells(svc)
+	}
+	slices.SortFunc(t.services, func(a, b *service.SimService) int {
+		return strings.Compare(a.ServiceID, b.ServiceID)
+	})
+	for i := 1; i < len(t.services); i++ {
+		if t.services[i].ServiceID == t.services[i-1].ServiceID {
+			return nil, invalid(t.services[i].ServiceID, "", fmt.Errorf("duplicate service %q", t.services[i].ServiceID))
+		}
+	}
+	t.order = slices.Clone(t.services)
+	slices.SortStableFunc(t.order, byPriority)
+
+	t.curTime = state.Time
+	for id, holder := range state.BlockHolds {
+		t.blockHolds[id] = holder
+	}
+	for id, lock := range state.JunctionLocks {
+		t.junctionLocks[id] = junctionLock{holder: lock.Holder, movement: movement{in: lock.In, out: lock.Out}}
+	}
+	for _, lock := range state.MergeLocks {
+		t.mergeLocks[mergeKey{lock.Node, lock.Out}] = junctionLock{holder: lock.Holder, movement: movement{in: lock.In, out: lock.Out}}
+	}
+	t.active = slices.Clone(state.ActiveEvents)
+	t.stalled = state.Stalled
+	for id, entry := range state.EdgeEntries {
+		t.entries[id] = edgeEntry{service: entry.ServiceID, time: entry.Time}
+	}
+	for id, sl := range state.Logged {
+		t.logged[id] = sl
+	}
+	t.nextLog = state.NextLog
+	for _, sep := range state.Separations {
+		t.separations[sep.Services] = sep
+	}
+
+	last := state.Time - t.meta.TimeStep
+	for i, ev := range events {
+		if ev.Time <= last {
+			continue
+		}
+		if err := t.checkEvent(ev); err != nil {
+			return nil, invalid(ev.ServiceID, ev.EdgeID, fmt.Errorf("event %d (%s): %w", i, ev.Type, err))
+		}
+		t.events = append(t.events, ev)
+	}
+	slices.SortStableFunc(t.events, func(a, b SimEvent) int {
+		return cmp.Compare(a.Time, b.Time)
+	})
+	return t, nil
+}
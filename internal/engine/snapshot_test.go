@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// resumeInput is the Y-shaped merge of yMergeInput with events before, across and after
+// the middle of the run.
+func resumeInput(tb testing.TB) SimulationInput {
+	tb.Helper()
+	input := testInput(tb, yMergeInput(300, 5))
+	input.Events = []SimEvent{
+		{Type: EventHoldService, Time: 20, Duration: 10, ServiceID: "S2"},
+		{Type: EventTemporarySpeedLimit, Time: 60, Duration: 100, EdgeID: "MB", SpeedLimit: 10},
+		{Type: EventHoldService, Time: 200, ServiceID: "S1"},
+		{Type: EventReleaseService, Time: 210, ServiceID: "S1"},
+	}
+	return input
+}
+
+// TestResume checks that a run snapshotted part way, sent through JSON and resumed
+// carries on exactly as the same run made in one go.
+func TestResume(t *testing.T) {
+	input := resumeInput(t)
+	for _, at := range []float64{10, 25, 100, 150, 205} {
+		full, err := NewTMS(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := full.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		half := input
+		half.Meta.RunTime = at
+		first, err := NewTMS(half)
+		if err != nil {
+			t.Fatal(err)
+		}
+		done, err := first.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(first.Snapshot())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var state EngineState
+		if err := json.Unmarshal(data, &state); err != nil {
+			t.Fatal(err)
+		}
+		resumed, err := Resume(state, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := resumed.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(done.Output); !reflect.DeepEqual(got.Output, want.Output[n:]) {
+			t.Errorf("resumed at %v: output differs from the run made in one go", at)
+		}
+		if !reflect.DeepEqual(got.Journeys, want.Journeys) {
+			t.Errorf("resumed at %v: journeys %v, want %v", at, got.Journeys, want.Journeys)
+		}
+	}
+}
+
+// TestResumeEvents checks that the events given to Resume are checked against the
+// services restored from the snapshot rather than those of its input.
+func TestResumeEvents(t *testing.T) {
+	input := resumeInput(t)
+	half := input
+	half.Meta.RunTime = 100
+	first, err := NewTMS(half)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.Run(); err != nil {
+		t.Fatal(err)
+	}
+	state := first.Snapshot()
+
+	// S1 is in the snapshot but not the input.
+	only := input
+	only.ServiceList = input.ServiceList[1:]
+	if _, err := Resume(state, only); err != nil {
+		t.Errorf("event for a restored service: %v", err)
+	}
+
+	// S3 is in neither, so its event cannot be applied; one already past is ignored.
+	for _, ev := range []SimEvent{
+		{Type: EventHoldService, Time: 150, ServiceID: "S3"},
+		{Type: EventReleaseService, Time: 150, ServiceID: "S3"},
+	} {
+		unknown := input
+		unknown.Events = append(input.Events[:len(input.Events):len(input.Events)], ev)
+		if _, err := Resume(state, unknown); err == nil {
+			t.Errorf("%s for an unknown service: no error", ev.Type)
+		}
+	}
+	// S1 is in the input but not the snapshot, so its events cannot be applied.
+	lone := half
+	lone.ServiceList = input.ServiceList[1:]
+	lone.Events = input.Events[:2]
+	second, err := NewTMS(lone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Resume(second.Snapshot(), input); err == nil {
+		t.Error("event for a service not in the snapshot: no error")
+	}
+
+	past := input
+	past.ServiceList = input.ServiceList[1:]
+	past.Events = []SimEvent{{Type: EventHoldService, Time: 50, Duration: 5, ServiceID: "S9"}}
+	if _, err := Resume(state, past); err != nil {
+		t.Errorf("event already past for an unknown service: %v", err)
+	}
+}
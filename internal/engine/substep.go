@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// subSteps returns how many equal substeps svc's movement over the timestep dt is split
+// into: SubSteps while it is under way within a timestep's running of the point where it
+// must start braking for its next stop or the end of its set back, its creep speed short
+// of the stop, or a lower speed limit ahead, and otherwise 1.
+func (t *TMS) subSteps(svc *service.SimService, dt float64) (int, error) {
+	if t.meta.SubSteps <= 1 || !moving(svc.State) {
+		return 1, nil
+	}
+	m, err := t.motionModel(svc)
+	if err != nil {
+		return 0, kinematicsError(svc, fmt.Errorf("service %q motion model: %w", svc.ServiceID, err))
+	}
+	v := svc.Velocity
+	margin := svc.ReactionDistance() + v*dt
+	if svc.SettingBack > 0 {
+		if svc.SettingBack <= margin+m.BrakingDistance(v) {
+			return t.meta.SubSteps, nil
+		}
+		return 1, nil
+	}
+
+	distToStop, err := t.distanceToNextStop(svc)
+	if err != nil {
+		return 0, routingError(svc, fmt.Errorf("service %q distance to stop: %w", svc.ServiceID, err))
+	}
+	if distToStop <= margin+m.BrakingDistance(v) {
+		return t.meta.SubSteps, nil
+	}
+	if c := svc.Vehicle.CreepSpeed; c > 0 && v > c && distToStop-svc.Vehicle.CreepDistance <= margin+m.BrakingDistanceTo(v, c) {
+		return t.meta.SubSteps, nil
+	}
+	sl, err := t.getSpeedLimitInfo(svc)
+	if err != nil {
+		return 0, routingError(svc, fmt.Errorf("service %q speed limit info: %w", svc.ServiceID, err))
+	}
+	if v > sl.nextMax && sl.distToChange <= margin+m.BrakingDistanceTo(v, sl.nextMax) {
+		return t.meta.SubSteps, nil
+	}
+	return 1, nil
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"math"
+
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// SimulationSummary holds aggregate figures for a simulation run.
+type SimulationSummary struct {
+	Services      []ServiceSummary `json:"services"`       // in order of first appearance in the log
+	TotalDistance float64          `json:"total_distance"` // all services, metres
+	// Separations lists the least separation between each pair of services, by pair,
+	// when SimulationMeta.ReportSeparations is set. Summarise cannot tell it from the log.
+	Separations []Separation `json:"separations,omitempty"`
+}
+
+// ServiceSummary holds aggregate figures for one service over a run.
+type ServiceSummary struct {
+	ServiceID    service.ServiceID `json:"service_id"`
+	Distance     float64           `json:"distance"`      // metres
+	RunningTime  float64           `json:"running_time"`  // seconds spent on the move
+	AverageSpeed float64           `json:"average_speed"` // over RunningTime, in the log's speed unit; 0 if it never moved
+	MaxSpeed     float64           `json:"max_speed"`     // in the log's speed unit
+	DwellTime    float64           `json:"dwell_time"`    // seconds
+	StopsServed  int               `json:"stops_served"`  // arrivals at stops, including the last
+	Delay        *float64          `json:"delay,omitempty"`
+}
+
+// Summarise computes aggregate figures from log, which may be in either log mode. Each
+// interval between a service's consecutive logs counts towards the running time if the
+// service was on the move at the start of it. Distance is taken from the services'
+// odometers, and stops served and dwell time from the calls in log's Journeys. Delay is
+// the service's delay at the last timetabled stop it reached, if any.
+func Summarise(log SimulationLog) SimulationSummary {
+	var summary SimulationSummary
+	index := make(map[service.ServiceID]int)
+	type sample struct {
+		timestamp float64
+		log       service.ServiceLog
+	}
+	last := make(map[service.ServiceID]sample)
+
+	for _, row := range log.Output {
+		for _, sl := range row.ServiceLogs {
+			i, seen := index[sl.ServiceID]
+			if !seen {
+				i = len(summary.Services)
+				index[sl.ServiceID] = i
+				summary.Services = append(summary.Services, ServiceSummary{ServiceID: sl.ServiceID})
+			}
+			s := &summary.Services[i]
+			s.MaxSpeed = math.Max(s.MaxSpeed, math.Abs(sl.Velocity)) // negative while setting back
+			s.Delay = sl.Delay
+
+			if prev, ok := last[sl.ServiceID]; ok {
+				dt := row.Timestamp - prev.timestamp
+				s.Distance += sl.Odometer - prev.log.Odometer
+				if moving(prev.log.State) {
+					s.RunningTime += dt
+				}
+			}
+			last[sl.ServiceID] = sample{row.Timestamp, sl}
+		}
+	}
+
+	// A service also reads as dwelling when brought to a stand short of a hold point, so
+	// stops are counted from the calls recorded in the journeys instead.
+	for _, j := range log.Journeys {
+		i, seen := index[j.ServiceID]
+		if !seen {
+			continue
+		}
+		s, end := &summary.Services[i], last[j.ServiceID]
+		for _, stop := range j.Stops {
+			if stop.Arrival == nil {
+				continue // the origin
+			}
+			s.StopsServed++
+			switch {
+			case stop.Departure != nil:
+				s.DwellTime += *stop.Departure - *stop.Arrival
+			case end.log.State == service.StateDwelling:
+				s.DwellTime += end.timestamp - *stop.Arrival // still there when the log ends
+			}
+		}
+	}
+
+	for i := range summary.Services {
+		s := &summary.Services[i]
+		if s.RunningTime > 0 {
+			s.AverageSpeed = s.Distance / s.RunningTime / speedFactor(log.Meta.SpeedUnit)
+		}
+		summary.TotalDistance += s.Distance
+	}
+	return summary
+}
+
+// moving reports whether a service in state is on the move.
+func moving(state service.ServiceState) bool {
+	switch state {
+	case service.StateAccelerating, service.StateCruising, service.StateCoasting, service.StateDecelerating:
+		return true
+	}
+	return false
+}
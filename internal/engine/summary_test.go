@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// TestSummaryStops checks that stops served and dwell time count the calls at stops
+// only, not the times a service is brought to a stand by the service ahead.
+func TestSummaryStops(t *testing.T) {
+	tms := newTestTMS(t, `{
+		"simulation_meta": {"simulation_id": "summary", "run_time": 400, "time_step": 1},
+		"graph_data": {
+			"nodes": [{"node_id": "A"}, {"node_id": "C"}, {"node_id": "M"}, {"node_id": "B"}, {"node_id": "D"}],
+			"edges": [
+				{"edge_id": "AM", "u": "A", "v": "M", "length": 500},
+				{"edge_id": "CM", "u": "C", "v": "M", "length": 200},
+				{"edge_id": "MB", "u": "M", "v": "B", "length": 1500},
+				{"edge_id": "BD", "u": "B", "v": "D", "length": 500}
+			]
+		},
+		"service_list": [
+			{"service_id": "S1", "initial_position": "A", "route": [{"node_id": "B", "min_dwell": 30}, {"node_id": "D", "min_dwell": 0}], "vehicle": "VEHICLE"},
+			{"service_id": "S2", "initial_position": "C", "route": [{"node_id": "D", "min_dwell": 0}], "vehicle": "VEHICLE"}
+		]
+	}`)
+	log, err := tms.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// S1 must have been brought to a stand behind S2 for the test to tell.
+	stood := false
+	for _, row := range log.Output {
+		for _, sl := range row.ServiceLogs {
+			stood = stood || (sl.ServiceID == "S1" && sl.State == service.StateDwelling && sl.CurrentPosition.DistanceAlongEdge > 0)
+		}
+	}
+	if !stood {
+		t.Fatal("S1 never stood behind S2")
+	}
+
+	want := map[service.ServiceID]struct {
+		stops int
+		dwell float64
+	}{"S1": {2, 30}, "S2": {1, 0}}
+	for _, s := range log.Summary.Services {
+		w := want[s.ServiceID]
+		if s.StopsServed != w.stops {
+			t.Errorf("%s: stops_served %d, want %d", s.ServiceID, s.StopsServed, w.stops)
+		}
+		if math.Abs(s.DwellTime-w.dwell) > 1 {
+			t.Errorf("%s: dwell_time %v, want %v", s.ServiceID, s.DwellTime, w.dwell)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/kinematics"
+)
+
+// tsr is a TSR as the engine applies it: over part of one directed edge, so a
+// restriction on bidirectional track is held once for each direction.
+type tsr struct {
+	start, end  float64 // metres along the edge
+	limit       float64 // m/s
+	from, until float64 // seconds; until 0 = to the end of the run
+}
+
+// inForce reports whether r applies at time now.
+func (r tsr) inForce(now float64) bool {
+	return now >= r.from && (r.until == 0 || now < r.until)
+}
+
+// tsrSegment returns the stretch of track r covers, or why it cannot be applied.
+func (t *TMS) tsrSegment(r TSR) (graph.Segment, error) {
+	if (r.EdgeID == "") == (r.Segment == nil) {
+		return graph.Segment{}, fmt.Errorf("exactly one of edge_id and segment must be given")
+	}
+	seg := graph.Segment{Edge: r.EdgeID}
+	if r.Segment != nil {
+		seg = *r.Segment
+	}
+	edge, err := t.graph.GetEdgeByID(seg.Edge)
+	if err != nil {
+		return graph.Segment{}, err
+	}
+	if r.Segment == nil {
+		seg.End = edge.Length
+	}
+	switch {
+	case seg.Start < 0 || seg.End > edge.Length || seg.Start >= seg.End:
+		return graph.Segment{}, fmt.Errorf("segment [%v, %v] m is not within the %v m of edge %q", seg.Start, seg.End, edge.Length, edge.ID)
+	case r.SpeedLimit <= 0:
+		return graph.Segment{}, fmt.Errorf("speed limit %v must be positive", r.SpeedLimit)
+	case r.StartTime < 0:
+		return graph.Segment{}, fmt.Errorf("start_time %v must not be negative", r.StartTime)
+	case r.EndTime != 0 && r.EndTime <= r.StartTime:
+		return graph.Segment{}, fmt.Errorf("end_time %v must be after start_time %v", r.EndTime, r.StartTime)
+	}
+	return seg, nil
+}
+
+// addTSRs checks every TSR in rs and records them by edge, the reverse of a bidirectional
+// edge taking the same stretch of track measured from its own start.
+func (t *TMS) addTSRs(rs []TSR) []error {
+	var errs []error
+	for i, r := range rs {
+		seg, err := t.tsrSegment(r)
+		if err != nil {
+			edgeID := r.EdgeID
+			if r.Segment != nil {
+				edgeID = r.Segment.Edge
+			}
+			errs = append(errs, invalid("", edgeID, fmt.Errorf("tsr %d: %w", i, err)))
+			continue
+		}
+		if t.tsrs == nil {
+			t.tsrs = make(map[graph.EdgeID][]tsr)
+		}
+		entry := tsr{start: seg.Start, end: seg.End, limit: r.SpeedLimit, from: r.StartTime, until: r.EndTime}
+		t.tsrs[seg.Edge] = append(t.tsrs[seg.Edge], entry)
+		if rev, err := t.graph.GetReverseEdge(seg.Edge); err == nil {
+			entry.start, entry.end = rev.Length-seg.End, rev.Length-seg.Start
+			t.tsrs[rev.ID] = append(t.tsrs[rev.ID], entry)
+		}
+	}
+	return errs
+}
+
+// tsrLimit returns the lowest limit of the TSRs in force at d metres along edge id, or
+// +Inf if there is none.
+func (t *TMS) tsrLimit(id graph.EdgeID, d float64) float64 {
+	limit := math.Inf(1)
+	for _, r := range t.tsrs[id] {
+		if r.inForce(t.curTime) && r.start <= d && d < r.end {
+			limit = math.Min(limit, r.limit)
+		}
+	}
+	return limit
+}
+
+// limitChange is a speed limit that begins dist metres ahead of a service's front.
+type limitChange struct {
+	dist, limit float64
+}
+
+// tsrChanges appends to changes the TSRs in force on edge id that begin beyond d metres
+// along it, where that point is offset metres ahead of the front, each capped at top.
+func (t *TMS) tsrChanges(changes []limitChange, id graph.EdgeID, d, offset, top float64) []limitChange {
+	for _, r := range t.tsrs[id] {
+		if r.inForce(t.curTime) && r.start > d {
+			changes = append(changes, limitChange{dist: r.start - d + offset, limit: math.Min(top, r.limit)})
+		}
+	}
+	return changes
+}
+
+// mostUrgent returns the change in changes that a service at v under m must start braking
+// for first: the one with the least distance to spare beyond its braking distance.
+func mostUrgent(changes []limitChange, m kinematics.MotionModel, v float64) limitChange {
+	best, spare := changes[0], math.Inf(1)
+	for _, c := range changes {
+		if s := c.dist - m.BrakingDistanceTo(v, c.limit); s < spare {
+			best, spare = c, s
+		}
+	}
+	return best
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Speed units for SimulationMeta.SpeedUnit.
+const (
+	SpeedUnitMetresPerSecond   = "m/s" // the default
+	SpeedUnitKilometresPerHour = "km/h"
+	SpeedUnitMilesPerHour      = "mph"
+)
+
+// speedUnits gives the speed in m/s of one of each speed unit.
+var speedUnits = map[string]float64{
+	"":                         1,
+	SpeedUnitMetresPerSecond:   1,
+	SpeedUnitKilometresPerHour: 1 / 3.6,
+	SpeedUnitMilesPerHour:      0.44704,
+}
+
+// checkSpeedUnit reports an unknown SimulationMeta.SpeedUnit.
+func checkSpeedUnit(unit string) error {
+	if _, ok := speedUnits[unit]; !ok {
+		return fmt.Errorf("unknown speed_unit %q", unit)
+	}
+	return nil
+}
+
+// speedFactor returns the speed in m/s of one of unit, taking an unknown unit as m/s.
+func speedFactor(unit string) float64 {
+	if f, ok := speedUnits[unit]; ok {
+		return f
+	}
+	return 1
+}
+
+// inMetresPerSecond returns a copy of input with the speeds given in its SpeedUnit
+// converted to m/s: vehicle and service speeds, including initial velocities, and edge,
+// event and TSR speed limits.
+// input itself is left as it is.
+func (input SimulationInput) inMetresPerSecond() SimulationInput {
+	f := speedFactor(input.Meta.SpeedUnit)
+	if f == 1 {
+		return input
+	}
+
+	input.GraphData.Edges = slices.Clone(input.GraphData.Edges)
+	for i, e := range input.GraphData.Edges {
+		if e.SpeedLimit != nil {
+			limit := *e.SpeedLimit * f
+			input.GraphData.Edges[i].SpeedLimit = &limit
+		}
+	}
+
+	input.ServiceList = slices.Clone(input.ServiceList)
+	for i := range input.ServiceList {
+		svc := &input.ServiceList[i]
+		svc.Vehicle = svc.Vehicle.ScaleSpeeds(f)
+		svc.MaxSpeed *= f
+		svc.InitialVelocity *= f
+		svc.Units = slices.Clone(svc.Units)
+		for j := range svc.Units {
+			svc.Units[j].Unit = svc.Units[j].Unit.ScaleSpeeds(f)
+		}
+	}
+
+	input.Events = slices.Clone(input.Events)
+	for i := range input.Events {
+		input.Events[i].SpeedLimit *= f
+	}
+
+	input.TSRs = slices.Clone(input.TSRs)
+	for i := range input.TSRs {
+		input.TSRs[i].SpeedLimit *= f
+	}
+	return input
+}
+
+// inSpeedUnit converts the velocities in row, which are in m/s, to the output's speed
+// unit.
+func (t *TMS) inSpeedUnit(row SimulationLogRow) SimulationLogRow {
+	f := speedFactor(t.meta.SpeedUnit)
+	if f == 1 {
+		return row
+	}
+	for i := range row.ServiceLogs {
+		row.ServiceLogs[i].Velocity /= f
+	}
+	return row
+}
@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// GetShortestPathAStar returns the shortest path between start and end found by A*
+// search, using the straight-line distance between node coordinates as the heuristic.
+//
+// The heuristic is admissible, and the result therefore identical in length to
+// GetShortestPath, only if no edge is shorter than the straight-line distance between its
+// endpoints. Nodes without coordinates all sit at the origin, in which case the search
+// degrades gracefully toward Dijkstra. Results are not cached.
+func (g *Graph) GetShortestPathAStar(start, end NodeID) (PathInfo, error) {
+	key := pathKey(start, end)
+	if _, ok := g.nodeMap[start]; !ok {
+		return PathInfo{}, fmt.Errorf("node %q not found", start)
+	}
+	goal, ok := g.nodeMap[end]
+	if !ok {
+		return PathInfo{}, fmt.Errorf("node %q not found", end)
+	}
+	if start == end {
+		return PathInfo{ID: key, Route: []NodeID{start}, Length: 0}, nil
+	}
+
+	h := func(n NodeID) float64 {
+		loc := g.nodeMap[n].Loc
+		return math.Hypot(goal.Loc.X-loc.X, goal.Loc.Y-loc.Y)
+	}
+
+	tree := &shortestPathTree{
+		dist: map[NodeID]float64{start: 0},
+		prev: make(map[NodeID]NodeID),
+		via:  make(map[NodeID]Edge),
+	}
+	done := make(map[NodeID]bool)
+	pq := &nodeQueue{{node: start, dist: h(start)}}
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(nodeQueueItem)
+		if item.node == end {
+			route, edges := tree.reconstructPath(start, end)
+			return newPathInfo(key, route, edges, tree.dist[end]), nil
+		}
+		if done[item.node] {
+			continue
+		}
+		done[item.node] = true
+		for _, e := range g.outEdges[item.node] {
+			d := tree.dist[item.node] + e.Length
+			if cur, ok := tree.dist[e.V]; !ok || d < cur {
+				tree.dist[e.V] = d
+				tree.prev[e.V] = item.node
+				tree.via[e.V] = e
+				heap.Push(pq, nodeQueueItem{node: e.V, dist: d + h(e.V)})
+			}
+		}
+	}
+	return PathInfo{}, fmt.Errorf("no path from %q to %q", start, end)
+}
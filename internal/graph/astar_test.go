@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// gridGraph is a size × size grid of nodes 100 m apart, each joined to its neighbours
+// to the right and above by edges running both ways, a random length at least the
+// distance between their ends. Without coordinates, every node sits at the origin.
+func gridGraph(tb testing.TB, size int, coordinates bool, seed uint64) *Graph {
+	tb.Helper()
+	rng := rand.New(rand.NewPCG(seed, 0))
+	id := func(x, y int) NodeID { return fmt.Sprintf("%d,%d", x, y) }
+	var data GraphData
+	for x := range size {
+		for y := range size {
+			n := Node{ID: id(x, y)}
+			if coordinates {
+				n.Loc = Coordinate{X: float64(x) * 100, Y: float64(y) * 100}
+			}
+			data.Nodes = append(data.Nodes, n)
+			if x+1 < size {
+				data.Edges = append(data.Edges, Edge{ID: id(x, y) + ">" + id(x+1, y), U: id(x, y), V: id(x+1, y), Length: 100 * (1 + rng.Float64()), Bidirectional: true})
+			}
+			if y+1 < size {
+				data.Edges = append(data.Edges, Edge{ID: id(x, y) + "^" + id(x, y+1), U: id(x, y), V: id(x, y+1), Length: 100 * (1 + rng.Float64()), Bidirectional: true})
+			}
+		}
+	}
+	return newTestGraph(tb, data)
+}
+
+// checkPath fails the test if p is not a path from start to end of g whose edges add up
+// to its length.
+func checkPath(tb testing.TB, g *Graph, p PathInfo, start, end NodeID) {
+	tb.Helper()
+	if p.Route[0] != start || p.Route[len(p.Route)-1] != end || len(p.Edges) != len(p.Route)-1 {
+		tb.Fatalf("path %v by %v does not run from %s to %s", p.Route, p.Edges, start, end)
+	}
+	total := 0.0
+	for i, id := range p.Edges {
+		e, err := g.GetEdgeByID(id)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if e.U != p.Route[i] || e.V != p.Route[i+1] {
+			tb.Fatalf("path %v: edge %s does not join %s to %s", p.Route, id, p.Route[i], p.Route[i+1])
+		}
+		total += e.Length
+	}
+	if math.Abs(total-p.Length) > 1e-9 {
+		tb.Fatalf("path %v: edges add up to %v, length %v", p.Route, total, p.Length)
+	}
+}
+
+// TestAStarMatchesDijkstra checks that A* finds paths as short as GetShortestPath's
+// between every pair of nodes of a grid, with coordinates or without.
+func TestAStarMatchesDijkstra(t *testing.T) {
+	for _, coordinates := range []bool{true, false} {
+		for seed := range uint64(5) {
+			t.Run(fmt.Sprint(coordinates, "/", seed), func(t *testing.T) {
+				g := gridGraph(t, 5, coordinates, seed)
+				for _, u := range g.nodes {
+					for _, v := range g.nodes {
+						want, err := g.GetShortestPath(u.ID, v.ID)
+						if err != nil {
+							t.Fatal(err)
+						}
+						got, err := g.GetShortestPathAStar(u.ID, v.ID)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if got.ID != want.ID || math.Abs(got.Length-want.Length) > 1e-9 {
+							t.Fatalf("%s->%s: A* %s of %v m, want %s of %v m", u.ID, v.ID, got.ID, got.Length, want.ID, want.Length)
+						}
+						if u.ID != v.ID {
+							checkPath(t, g, got, u.ID, v.ID)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestAStarErrors checks that A* reports unknown nodes and unreachable ones.
+func TestAStarErrors(t *testing.T) {
+	g := newTestGraph(t, GraphData{
+		Nodes: []Node{{ID: "A"}, {ID: "B"}, {ID: "C"}},
+		Edges: []Edge{{ID: "AB", U: "A", V: "B", Length: 100}},
+	})
+	for _, ends := range [][2]NodeID{{"A", "X"}, {"X", "A"}, {"B", "A"}, {"A", "C"}} {
+		if _, err := g.GetShortestPathAStar(ends[0], ends[1]); err == nil {
+			t.Errorf("%s->%s: no error", ends[0], ends[1])
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package graph
+
+import "math"
+
+// straightTolerance is the turn angle (radians) below which consecutive edges are
+// treated as straight track.
+const straightTolerance = 1e-6
+
+// CurveSpeedLimit returns the maximum speed (m/s) at which a vehicle may pass from prev
+// onto next, limited by the graph's maximum lateral acceleration on the turn between them.
+//
+// The turn is modelled as a circular arc tangent to both edges, spanning half the shorter
+// edge on either side of the shared node, so R = (L/2) / tan(θ/2) and v = √(a_lat·R).
+// Returns +Inf when no limit applies: curve limits are disabled, the edges do not meet,
+// either edge has coincident endpoint coordinates, or the edges are straight.
+func (g *Graph) CurveSpeedLimit(prev, next Edge) float64 {
+	if g.maxLateralAccel <= 0 || prev.V != next.U {
+		return math.Inf(1)
+	}
+	u, mid, v := g.nodeMap[prev.U].Loc, g.nodeMap[prev.V].Loc, g.nodeMap[next.V].Loc
+	ax, ay := mid.X-u.X, mid.Y-u.Y
+	bx, by := v.X-mid.X, v.Y-mid.Y
+	na, nb := math.Hypot(ax, ay), math.Hypot(bx, by)
+	if na == 0 || nb == 0 {
+		return math.Inf(1)
+	}
+	cos := math.Max(-1, math.Min(1, (ax*bx+ay*by)/(na*nb)))
+	theta := math.Acos(cos)
+	if theta < straightTolerance {
+		return math.Inf(1)
+	}
+	radius := math.Min(prev.Length, next.Length) / 2 / math.Tan(theta/2)
+	return math.Sqrt(g.maxLateralAccel * radius)
+}
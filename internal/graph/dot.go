@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// nodeColours gives the fill colour of each node type in ToDOT's output.
+var nodeColours = map[NodeType]string{
+	NodeTypeMain:    "white",
+	NodeTypeStation: "lightblue",
+	NodeTypeSide:    "khaki",
+}
+
+// ToDOT writes the network to w as a GraphViz DOT digraph, for checking its topology by
+// eye: nodes filled by NodeType, junctions drawn as diamonds, and edges labelled with
+// their length (metres) and any speed limit (m/s). Unless every node is at the origin,
+// as when none has a location, each node is pinned at its location, in metres taken as
+// points, so that `neato -n` draws the network to scale.
+func (g *Graph) ToDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph network {")
+	fmt.Fprintln(bw, "\tnode [style=filled];")
+	pinned := slices.ContainsFunc(g.nodes, func(n Node) bool { return n.Loc != Coordinate{} })
+	for _, n := range g.nodes {
+		colour := nodeColours[n.Type]
+		if colour == "" {
+			colour = nodeColours[NodeTypeMain]
+		}
+		shape := "ellipse"
+		if n.Junction {
+			shape = "diamond"
+		}
+		pos := ""
+		if pinned {
+			pos = fmt.Sprintf(", pos=\"%s,%s!\"", dotNumber(n.Loc.X), dotNumber(n.Loc.Y))
+		}
+		fmt.Fprintf(bw, "\t%s [shape=%s, fillcolor=%s%s];\n", dotQuote(n.ID), shape, colour, pos)
+	}
+	for _, e := range g.edges {
+		label := dotNumber(e.Length) + " m"
+		if e.SpeedLimit != nil {
+			label += `\n` + dotNumber(*e.SpeedLimit) + " m/s"
+		}
+		fmt.Fprintf(bw, "\t%s -> %s [label=%s, tooltip=%s];\n",
+			dotQuote(e.U), dotQuote(e.V), dotQuote(label), dotQuote(e.ID))
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// dotQuote returns s as a DOT quoted string. Backslashes are kept, so that label escapes
+// such as \n work.
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// dotNumber formats v to one decimal place at most.
+func dotNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
@@ -0,0 +1,73 @@
+package graph
+
+// FeatureCollection is a GeoJSON FeatureCollection, which marshals to JSON ready for a
+// web map. Coordinates are the network's own, in metres, rather than longitude and
+// latitude, so a map shows them on a plain grid (e.g. Leaflet's CRS.Simple).
+type FeatureCollection struct {
+	Type     string    `json:"type"` // always "FeatureCollection"
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON Feature: a geometry and its properties.
+type Feature struct {
+	Type       string         `json:"type"` // always "Feature"
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry is a GeoJSON Point, with Coordinates [x, y], or LineString, with
+// Coordinates [[x, y], ...].
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// NewFeatureCollection returns a FeatureCollection of features.
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// PointFeature returns a Point feature at c with the given properties.
+func PointFeature(c Coordinate, properties map[string]any) Feature {
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "Point", Coordinates: [2]float64{c.X, c.Y}},
+		Properties: properties,
+	}
+}
+
+// ToGeoJSON returns the network as GeoJSON: each node a Point at its location, and each
+// edge a LineString between the locations of its ends. Bidirectional edges give a line
+// each way. Properties carry the node and edge fields, with lengths in metres and speed
+// limits in m/s.
+func (g *Graph) ToGeoJSON() FeatureCollection {
+	features := make([]Feature, 0, len(g.nodes)+len(g.edges))
+	for _, n := range g.nodes {
+		features = append(features, PointFeature(n.Loc, map[string]any{
+			"node_id":  n.ID,
+			"type":     n.Type,
+			"junction": n.Junction,
+		}))
+	}
+	for _, e := range g.edges {
+		u, v := g.nodeMap[e.U].Loc, g.nodeMap[e.V].Loc
+		props := map[string]any{
+			"edge_id": e.ID,
+			"u":       e.U,
+			"v":       e.V,
+			"length":  e.Length,
+		}
+		if e.SpeedLimit != nil {
+			props["speed_limit"] = *e.SpeedLimit
+		}
+		features = append(features, Feature{
+			Type:       "Feature",
+			Geometry:   Geometry{Type: "LineString", Coordinates: [][2]float64{{u.X, u.Y}, {v.X, v.Y}}},
+			Properties: props,
+		})
+	}
+	return NewFeatureCollection(features)
+}
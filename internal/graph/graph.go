@@ -4,13 +4,17 @@ package graph
 
 import (
 	"fmt"
+	"math"
+	"slices"
+	"sync"
 )
 
-// NodeID, EdgeID, PathID are string aliases used as identifiers.
+// NodeID, EdgeID, PathID, BlockID are string aliases used as identifiers.
 type (
-	NodeID = string
-	EdgeID = string
-	PathID = string
+	NodeID  = string
+	EdgeID  = string
+	PathID  = string
+	BlockID = string
 )
 
 // NodeType classifies a node in the network.
@@ -28,28 +32,103 @@ type Coordinate struct {
 	Y float64 `json:"y"` // metres
 }
 
-// Node is a point in the network graph.
+// Node is a point in the network graph. A Junction node is a set of points or a flat
+// crossing: services passing through it on different movements (pairs of arriving and
+// departing edges) conflict, so only one movement may use it at a time.
 type Node struct {
-	ID   NodeID     `json:"node_id"`
-	Loc  Coordinate `json:"loc"`
-	Type NodeType   `json:"type"`
+	ID       NodeID     `json:"node_id"`
+	Loc      Coordinate `json:"loc"`
+	Type     NodeType   `json:"type"`
+	Junction bool       `json:"junction,omitempty"`
 }
 
-// Edge is a directed connection between two nodes with a length in metres.
+// Edge is a directed connection between two nodes with a length in metres. Several
+// edges may join the same pair of nodes, e.g. parallel platform tracks or a passing loop.
 // SpeedLimit is optional: if nil the edge imposes no limit and the vehicle's
 // own VMax applies. Set it (in m/s) to restrict speed on a particular section.
+// Gradient is optional and defaults to flat track. Adhesion is optional: if nil the
+// simulation-wide default applies.
+//
+// A Bidirectional edge is added together with its reverse: an edge from V to U with ID
+// ReverseEdgeID(ID), the same length, speed limit and adhesion, and the opposite
+// gradient. Distances along the reverse edge are measured from its own U (this edge's
+// V), so DistanceAlongEdge d on one corresponds to Length − d on the other.
+//
+// Sections is optional: under fixed-block signalling it is the number of equal signal
+// sections the edge is divided into, in place of the simulation-wide block length.
+// Capacity is optional: with congestion modelled, it is the number of services at which
+// the edge is jammed and its speed at its least.
 type Edge struct {
 	ID         EdgeID   `json:"edge_id"`
 	U          NodeID   `json:"u"`
 	V          NodeID   `json:"v"`
 	Length     float64  `json:"length"`                // metres
 	SpeedLimit *float64 `json:"speed_limit,omitempty"` // m/s; nil = no restriction
+	Gradient   float64  `json:"gradient,omitempty"`    // per mille, positive = rising from U to V
+	Adhesion   *float64 `json:"adhesion,omitempty"`    // braking adhesion factor in (0, 1]; nil = default
+	Sections   int      `json:"sections,omitempty"`    // signal sections; 0 = from the block length
+	Capacity   int      `json:"capacity,omitempty"`    // services; 0 = never congested
+	// Bidirectional adds the reverse edge automatically; it is never set on the reverse itself.
+	Bidirectional bool `json:"bidirectional,omitempty"`
+}
+
+// ReverseEdgeID returns the ID given to the automatically created reverse of a
+// bidirectional edge.
+func ReverseEdgeID(id EdgeID) EdgeID { return id + ":reverse" }
+
+// Reverse returns the edge running the opposite way over the same track.
+func (e Edge) Reverse() Edge {
+	r := e
+	r.ID = ReverseEdgeID(e.ID)
+	r.U, r.V = e.V, e.U
+	r.Gradient = -e.Gradient
+	r.Bidirectional = false
+	return r
+}
+
+// Block is a section of track that only one service may occupy at a time, such as a
+// single-track line between passing loops. Listing one half of a bidirectional edge adds
+// its reverse too.
+//
+// Every bidirectional edge pair not listed in a declared block forms a block of its own,
+// identified by the forward edge's ID, so declared block IDs must not match an edge ID.
+type Block struct {
+	ID    BlockID  `json:"block_id"`
+	Edges []EdgeID `json:"edges"`
 }
 
 // GraphData is the serialisable input representation of a network graph.
+// MaxLateralAccel is optional: if set, turns between consecutive edges impose a speed
+// limit derived from node coordinates (see Graph.CurveSpeedLimit). LengthUnit is the
+// unit of the edge lengths and node coordinates, which NewGraph converts to metres.
+// MinLengthRatio is optional: if set, NewGraph warns of edges shorter than that fraction
+// of the distance between their ends (see Graph.LengthWarnings). With AutoLengths, edges
+// given no length take the straight-line distance between their ends.
 type GraphData struct {
-	Nodes []Node `json:"nodes"`
-	Edges []Edge `json:"edges"`
+	Nodes           []Node  `json:"nodes"`
+	Edges           []Edge  `json:"edges"`
+	Blocks          []Block `json:"blocks,omitempty"`
+	MaxLateralAccel float64 `json:"max_lateral_accel,omitempty"` // m/s²; 0 = no curve limits
+	LengthUnit      string  `json:"length_unit,omitempty"`       // see LengthUnitMetres; "" = metres
+	MinLengthRatio  float64 `json:"min_length_ratio,omitempty"`  // 0 = no check
+	AutoLengths     bool    `json:"auto_lengths,omitempty"`
+}
+
+// Length units for GraphData.LengthUnit.
+const (
+	LengthUnitMetres     = "m"
+	LengthUnitKilometres = "km"
+	LengthUnitMiles      = "mi"
+	LengthUnitFeet       = "ft"
+)
+
+// lengthUnits gives the length in metres of one of each length unit.
+var lengthUnits = map[string]float64{
+	"":                   1,
+	LengthUnitMetres:     1,
+	LengthUnitKilometres: 1000,
+	LengthUnitMiles:      1609.344,
+	LengthUnitFeet:       0.3048,
 }
 
 // Position is a point along a directed edge in the graph.
@@ -60,9 +139,12 @@ type Position struct {
 
 // PathInfo holds the result of a shortest-path computation.
 type PathInfo struct {
-	ID     PathID
-	Route  []NodeID // ordered node IDs from start to end
-	Length float64  // total path length in metres
+	ID    PathID
+	Route []NodeID // ordered node IDs from start to end
+	Edges []EdgeID // the edges joining consecutive nodes of Route, one fewer than Route
+	// EdgeList holds the edges themselves, as they were when the path was found.
+	EdgeList []Edge
+	Length   float64 // total path length in metres
 }
 
 // Segment is a contiguous stretch of a single edge, defined by start and end distances.
@@ -81,50 +163,209 @@ type Graph struct {
 	edges       []Edge
 	nodeMap     map[NodeID]Node
 	edgeMap     map[EdgeID]Edge
-	edgeByNodes map[NodeID]map[NodeID]Edge // u → v → edge
-	// Floyd-Warshall tables; nil until first needed.
-	dist     map[NodeID]map[NodeID]float64
-	nextNode map[NodeID]map[NodeID]NodeID
-	// Path cache; cleared whenever the graph topology changes.
+	edgeByNodes map[NodeID]map[NodeID][]Edge // u → v → parallel edges in insertion order
+	outEdges    map[NodeID][]Edge            // u → outgoing edges in insertion order
+	inEdges     map[NodeID][]EdgeID          // v → incoming edges in insertion order
+	reverse     map[EdgeID]EdgeID            // bidirectional edge ↔ its reverse
+	blockOf     map[EdgeID]BlockID           // edge → block it belongs to, declared or implicit
+	blocks      map[BlockID][]EdgeID         // declared blocks only
+	// Lateral acceleration limit for curve speed limits; 0 disables them.
+	maxLateralAccel float64
+	// Dijkstra trees by source node and the path cache; both are filled on demand and
+	// cleared whenever the graph topology changes. cacheMu guards them, so that a graph
+	// no longer being changed can be searched from several goroutines at once.
+	cacheMu   sync.Mutex
+	trees     map[NodeID]*shortestPathTree
 	pathCache map[PathID]PathInfo
+	// warnings lists the problems found by NewGraph that did not stop it.
+	warnings []string
+	// frozen is set by Freeze, after which the graph may no longer be changed.
+	frozen bool
 }
 
 // NewGraph builds a Graph from GraphData, returning an error if any node or edge
-// references are invalid.
+// references are invalid or, where both ends of an edge have distinct coordinates, its
+// length is wildly out of keeping with the distance between them (see checkLengths).
+// Lengths and coordinates in another LengthUnit are converted to metres.
 func NewGraph(data GraphData) (*Graph, error) {
+	scale, ok := lengthUnits[data.LengthUnit]
+	if !ok {
+		return nil, fmt.Errorf("unknown length_unit %q", data.LengthUnit)
+	}
+	if data.MinLengthRatio < 0 {
+		return nil, fmt.Errorf("min_length_ratio %v must not be negative", data.MinLengthRatio)
+	}
 	g := &Graph{
-		nodeMap:     make(map[NodeID]Node),
-		edgeMap:     make(map[EdgeID]Edge),
-		edgeByNodes: make(map[NodeID]map[NodeID]Edge),
-		pathCache:   make(map[PathID]PathInfo),
+		nodeMap:         make(map[NodeID]Node),
+		edgeMap:         make(map[EdgeID]Edge),
+		edgeByNodes:     make(map[NodeID]map[NodeID][]Edge),
+		outEdges:        make(map[NodeID][]Edge),
+		inEdges:         make(map[NodeID][]EdgeID),
+		reverse:         make(map[EdgeID]EdgeID),
+		blockOf:         make(map[EdgeID]BlockID),
+		blocks:          make(map[BlockID][]EdgeID),
+		trees:           make(map[NodeID]*shortestPathTree),
+		pathCache:       make(map[PathID]PathInfo),
+		maxLateralAccel: data.MaxLateralAccel,
 	}
 	for _, n := range data.Nodes {
+		n.Loc = Coordinate{X: n.Loc.X * scale, Y: n.Loc.Y * scale}
 		if err := g.AddNode(n); err != nil {
 			return nil, err
 		}
 	}
 	for _, e := range data.Edges {
+		e.Length *= scale
+		if e.Length == 0 && data.AutoLengths {
+			if e.Length = g.straightLine(e); e.Length == 0 {
+				return nil, fmt.Errorf("edge %q: no length given, and its ends are at the same location", e.ID)
+			}
+		}
 		if err := g.AddEdge(e); err != nil {
 			return nil, err
 		}
 	}
+	if err := g.checkLengths(); err != nil {
+		return nil, err
+	}
+	g.warnings = append(g.isolatedWarnings(), g.LengthWarnings(data.MinLengthRatio)...)
+	for _, b := range data.Blocks {
+		if err := g.AddBlock(b); err != nil {
+			return nil, err
+		}
+	}
 	return g, nil
 }
 
+// Freeze forbids any further change to the graph: once frozen, the methods that would
+// change it return an error instead. The graph is then only read, apart from its cache of
+// shortest paths, which is safe for concurrent use, so a frozen graph may be shared by
+// simulations running in several goroutines at once. Freezing cannot be undone.
+func (g *Graph) Freeze() { g.frozen = true }
+
+// Frozen reports whether the graph has been frozen.
+func (g *Graph) Frozen() bool { return g.frozen }
+
 // AddNode adds a node to the graph. Returns an error if the node ID already exists.
 func (g *Graph) AddNode(n Node) error {
+	if g.frozen {
+		return fmt.Errorf("adding node %q: graph is frozen", n.ID)
+	}
 	if _, exists := g.nodeMap[n.ID]; exists {
 		return fmt.Errorf("node %q already exists", n.ID)
 	}
 	g.nodes = append(g.nodes, n)
 	g.nodeMap[n.ID] = n
-	g.dist = nil // invalidate cached paths
+	g.invalidatePaths()
 	return nil
 }
 
-// AddEdge adds a directed edge to the graph. Returns an error if the edge ID already
-// exists or either endpoint node is missing.
+// AddEdge adds a directed edge to the graph, and its reverse if the edge is
+// bidirectional, in which case the pair forms a block of its own. Returns an error if the
+// edge ID (or its reverse ID) already exists or either endpoint node is missing.
 func (g *Graph) AddEdge(e Edge) error {
+	if g.frozen {
+		return fmt.Errorf("adding edge %q: graph is frozen", e.ID)
+	}
+	if err := g.addEdge(e); err != nil {
+		return err
+	}
+	if e.Bidirectional {
+		r := e.Reverse()
+		if err := g.addEdge(r); err != nil {
+			return fmt.Errorf("reverse of edge %q: %w", e.ID, err)
+		}
+		g.reverse[e.ID] = r.ID
+		g.reverse[r.ID] = e.ID
+		g.blockOf[e.ID] = e.ID
+		g.blockOf[r.ID] = e.ID
+	}
+	return nil
+}
+
+// AddBlock declares a block. Its edges, and the reverses of any bidirectional ones, leave
+// their implicit blocks. Returns an error if the block ID is already in use or clashes
+// with an edge ID, or an edge is missing or already in another declared block.
+func (g *Graph) AddBlock(b Block) error {
+	if g.frozen {
+		return fmt.Errorf("adding block %q: graph is frozen", b.ID)
+	}
+	if _, exists := g.blocks[b.ID]; exists {
+		return fmt.Errorf("block %q already exists", b.ID)
+	}
+	if _, clash := g.edgeMap[b.ID]; clash {
+		return fmt.Errorf("block %q: ID clashes with an edge", b.ID)
+	}
+	if len(b.Edges) == 0 {
+		return fmt.Errorf("block %q has no edges", b.ID)
+	}
+	var edges []EdgeID
+	for _, id := range b.Edges {
+		if _, ok := g.edgeMap[id]; !ok {
+			return fmt.Errorf("block %q: edge %q not found", b.ID, id)
+		}
+		edges = append(edges, id)
+		if r, ok := g.reverse[id]; ok {
+			edges = append(edges, r)
+		}
+	}
+	for _, id := range edges {
+		if cur, ok := g.blockOf[id]; ok && cur != b.ID {
+			if _, declared := g.blocks[cur]; declared {
+				return fmt.Errorf("block %q: edge %q is already in block %q", b.ID, id, cur)
+			}
+		}
+	}
+	for _, id := range edges {
+		g.blockOf[id] = b.ID
+	}
+	g.blocks[b.ID] = edges
+	return nil
+}
+
+// BlockOf returns the block edge id belongs to, if any.
+func (g *Graph) BlockOf(id EdgeID) (BlockID, bool) {
+	b, ok := g.blockOf[id]
+	return b, ok
+}
+
+// BlockEdges returns the edges in block b: those of a declared block, or a bidirectional
+// edge and its reverse for the block each forms by itself.
+func (g *Graph) BlockEdges(b BlockID) []EdgeID {
+	if edges, ok := g.blocks[b]; ok {
+		return slices.Clone(edges)
+	}
+	if r, ok := g.reverse[b]; ok && g.blockOf[b] == b {
+		return []EdgeID{b, r}
+	}
+	return nil
+}
+
+// HasBlocks reports whether any edge belongs to a block.
+func (g *Graph) HasBlocks() bool { return len(g.blockOf) > 0 }
+
+// IsJunction reports whether node id is a junction.
+func (g *Graph) IsJunction(id NodeID) bool { return g.nodeMap[id].Junction }
+
+// IsMerge reports whether two or more edges lead into node id, so that services arriving
+// by different edges may leave it by the same one.
+func (g *Graph) IsMerge(id NodeID) bool { return len(g.inEdges[id]) > 1 }
+
+// EdgesInto returns the IDs of the edges leading into node id in the order they were
+// added. The returned slice must not be modified.
+func (g *Graph) EdgesInto(id NodeID) []EdgeID { return g.inEdges[id] }
+
+// HasJunctions reports whether any node is a junction.
+func (g *Graph) HasJunctions() bool {
+	for _, n := range g.nodes {
+		if n.Junction {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Graph) addEdge(e Edge) error {
 	if _, exists := g.edgeMap[e.ID]; exists {
 		return fmt.Errorf("edge %q already exists", e.ID)
 	}
@@ -134,16 +375,98 @@ func (g *Graph) AddEdge(e Edge) error {
 	if _, ok := g.nodeMap[e.V]; !ok {
 		return fmt.Errorf("edge %q: target node %q not found", e.ID, e.V)
 	}
+	if e.Adhesion != nil && (*e.Adhesion <= 0 || *e.Adhesion > 1) {
+		return fmt.Errorf("edge %q: adhesion %v must be in (0, 1]", e.ID, *e.Adhesion)
+	}
+	if e.Sections < 0 {
+		return fmt.Errorf("edge %q: sections %d must not be negative", e.ID, e.Sections)
+	}
+	if e.Capacity < 0 {
+		return fmt.Errorf("edge %q: capacity %d must not be negative", e.ID, e.Capacity)
+	}
 	g.edges = append(g.edges, e)
 	g.edgeMap[e.ID] = e
 	if g.edgeByNodes[e.U] == nil {
-		g.edgeByNodes[e.U] = make(map[NodeID]Edge)
+		g.edgeByNodes[e.U] = make(map[NodeID][]Edge)
 	}
-	g.edgeByNodes[e.U][e.V] = e
-	g.dist = nil // invalidate cached paths
+	g.edgeByNodes[e.U][e.V] = append(g.edgeByNodes[e.U][e.V], e)
+	g.outEdges[e.U] = append(g.outEdges[e.U], e)
+	g.inEdges[e.V] = append(g.inEdges[e.V], e.ID)
+	g.invalidatePaths()
 	return nil
 }
 
+// UpdateEdgeLength sets the length of an existing edge (metres), and of its reverse if it
+// has one.
+//
+// Lengthening an edge can only change shortest paths that traverse it, so only those
+// cached paths and search trees are discarded. Shortening an edge may make it attractive
+// to any route, so it clears the whole cache.
+func (g *Graph) UpdateEdgeLength(id EdgeID, length float64) error {
+	if g.frozen {
+		return fmt.Errorf("updating edge %q: graph is frozen", id)
+	}
+	e, ok := g.edgeMap[id]
+	if !ok {
+		return fmt.Errorf("edge %q not found", id)
+	}
+	if length < 0 {
+		return fmt.Errorf("edge %q: length %v must not be negative", id, length)
+	}
+	old := e.Length
+	for _, e := range g.withReverse(e) {
+		e.Length = length
+		g.replaceEdge(e)
+		if length > old {
+			g.invalidatePathsThrough(e)
+		}
+	}
+	if length < old {
+		g.invalidatePaths()
+	}
+	return nil
+}
+
+// SetEdgeSpeedLimit sets or, with a nil limit, clears the speed limit (m/s) of an
+// existing edge, and of its reverse if it has one. Shortest paths depend only on length,
+// so only the cached paths that hold a copy of the edges are discarded.
+func (g *Graph) SetEdgeSpeedLimit(id EdgeID, limit *float64) error {
+	if g.frozen {
+		return fmt.Errorf("updating edge %q: graph is frozen", id)
+	}
+	e, ok := g.edgeMap[id]
+	if !ok {
+		return fmt.Errorf("edge %q not found", id)
+	}
+	for _, e := range g.withReverse(e) {
+		e.SpeedLimit = limit
+		g.replaceEdge(e)
+		g.invalidatePathsThrough(e)
+	}
+	return nil
+}
+
+// withReverse returns e and, if it is one of a bidirectional pair, the other edge of the
+// pair, which runs over the same track and so must be kept in step with it.
+func (g *Graph) withReverse(e Edge) []Edge {
+	if r, ok := g.reverse[e.ID]; ok {
+		return []Edge{e, g.edgeMap[r]}
+	}
+	return []Edge{e}
+}
+
+// replaceEdge overwrites every stored copy of the edge with e's ID.
+func (g *Graph) replaceEdge(e Edge) {
+	g.edgeMap[e.ID] = e
+	for _, list := range [][]Edge{g.edges, g.outEdges[e.U], g.edgeByNodes[e.U][e.V]} {
+		for i := range list {
+			if list[i].ID == e.ID {
+				list[i] = e
+			}
+		}
+	}
+}
+
 // pathKey returns a canonical string key for a start→end pair.
 func pathKey(start, end NodeID) PathID { return start + "->" + end }
 
@@ -156,14 +479,72 @@ func (g *Graph) GetEdgeByID(id EdgeID) (Edge, error) {
 	return e, nil
 }
 
-// GetEdge returns the directed edge from u to v.
+// GetReverseEdge returns the edge covering the same track as id in the opposite
+// direction, if id is one half of a bidirectional pair.
+func (g *Graph) GetReverseEdge(id EdgeID) (Edge, error) {
+	r, ok := g.reverse[id]
+	if !ok {
+		return Edge{}, fmt.Errorf("edge %q is not bidirectional", id)
+	}
+	return g.edgeMap[r], nil
+}
+
+// ReversePosition returns the same point on the track as pos, expressed along the
+// reverse of pos's edge.
+func (g *Graph) ReversePosition(pos Position) (Position, error) {
+	r, err := g.GetReverseEdge(pos.Edge)
+	if err != nil {
+		return Position{}, err
+	}
+	return Position{Edge: r.ID, DistanceAlongEdge: r.Length - pos.DistanceAlongEdge}, nil
+}
+
+// PositionCoordinate returns the coordinate of pos, interpolated in a straight line
+// between the locations of its edge's end nodes by the fraction of the edge covered.
+func (g *Graph) PositionCoordinate(pos Position) (Coordinate, error) {
+	e, err := g.GetEdgeByID(pos.Edge)
+	if err != nil {
+		return Coordinate{}, err
+	}
+	f := 0.0
+	if e.Length > 0 {
+		f = math.Max(0, math.Min(1, pos.DistanceAlongEdge/e.Length))
+	}
+	u, v := g.nodeMap[e.U].Loc, g.nodeMap[e.V].Loc
+	return Coordinate{X: u.X + f*(v.X-u.X), Y: u.Y + f*(v.Y-u.Y)}, nil
+}
+
+// straightLine returns the straight-line distance between the locations of e's ends, or
+// 0 if either is missing.
+func (g *Graph) straightLine(e Edge) float64 {
+	u, okU := g.nodeMap[e.U]
+	v, okV := g.nodeMap[e.V]
+	if !okU || !okV {
+		return 0
+	}
+	return math.Hypot(v.Loc.X-u.Loc.X, v.Loc.Y-u.Loc.Y)
+}
+
+// GetEdge returns the shortest directed edge from u to v; of parallel edges of equal
+// length, the first added wins. This is the edge shortest paths are measured over.
 func (g *Graph) GetEdge(u, v NodeID) (Edge, error) {
-	if m, ok := g.edgeByNodes[u]; ok {
-		if e, ok := m[v]; ok {
-			return e, nil
+	edges := g.edgeByNodes[u][v]
+	if len(edges) == 0 {
+		return Edge{}, fmt.Errorf("no edge from %q to %q", u, v)
+	}
+	best := edges[0]
+	for _, e := range edges[1:] {
+		if e.Length < best.Length {
+			best = e
 		}
 	}
-	return Edge{}, fmt.Errorf("no edge from %q to %q", u, v)
+	return best, nil
+}
+
+// GetEdges returns every directed edge from u to v in the order they were added.
+// The returned slice must not be modified.
+func (g *Graph) GetEdges(u, v NodeID) []Edge {
+	return g.edgeByNodes[u][v]
 }
 
 // GetNextEdge returns the first edge on the shortest path from u toward dest.
@@ -172,10 +553,10 @@ func (g *Graph) GetNextEdge(u, dest NodeID) (Edge, error) {
 	if err != nil {
 		return Edge{}, err
 	}
-	if len(path.Route) < 2 {
+	if len(path.EdgeList) == 0 {
 		return Edge{}, fmt.Errorf("already at destination %q", dest)
 	}
-	return g.GetEdge(path.Route[0], path.Route[1])
+	return path.EdgeList[0], nil
 }
 
 // GetPathStartPosition returns the Position at the start of the first edge on the
@@ -185,12 +566,8 @@ func (g *Graph) GetPathStartPosition(u, v NodeID) (Position, error) {
 	if err != nil {
 		return Position{}, err
 	}
-	if len(path.Route) < 2 {
+	if len(path.Edges) == 0 {
 		return Position{}, fmt.Errorf("no edges on path from %q to %q", u, v)
 	}
-	edge, err := g.GetEdge(path.Route[0], path.Route[1])
-	if err != nil {
-		return Position{}, err
-	}
-	return Position{Edge: edge.ID, DistanceAlongEdge: 0}, nil
+	return Position{Edge: path.Edges[0], DistanceAlongEdge: 0}, nil
 }
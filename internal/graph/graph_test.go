@@ -0,0 +1,76 @@
+package graph
+
+import "testing"
+
+// newTestGraph builds a graph from data, failing the test if it cannot be built.
+func newTestGraph(tb testing.TB, data GraphData) *Graph {
+	tb.Helper()
+	g, err := NewGraph(data)
+	if err != nil {
+		tb.Fatalf("NewGraph: %v", err)
+	}
+	return g
+}
+
+// TestUpdateBidirectionalEdge checks that changing either edge of a bidirectional pair
+// changes the other to match, and that shortest paths over the pair see the change.
+func TestUpdateBidirectionalEdge(t *testing.T) {
+	for _, id := range []EdgeID{"AB", ReverseEdgeID("AB")} {
+		t.Run(id, func(t *testing.T) {
+			g := newTestGraph(t, GraphData{
+				Nodes: []Node{{ID: "A"}, {ID: "B"}},
+				Edges: []Edge{{ID: "AB", U: "A", V: "B", Length: 100, Bidirectional: true}},
+			})
+			// Fill the path cache both ways.
+			for _, ends := range [][2]NodeID{{"A", "B"}, {"B", "A"}} {
+				if _, err := g.GetShortestPath(ends[0], ends[1]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			limit := 10.0
+			if err := g.UpdateEdgeLength(id, 250); err != nil {
+				t.Fatal(err)
+			}
+			if err := g.SetEdgeSpeedLimit(id, &limit); err != nil {
+				t.Fatal(err)
+			}
+			for _, ends := range [][2]NodeID{{"A", "B"}, {"B", "A"}} {
+				p, err := g.GetShortestPath(ends[0], ends[1])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if p.Length != 250 {
+					t.Errorf("path %s->%s length %v, want 250", ends[0], ends[1], p.Length)
+				}
+			}
+			for _, e := range []EdgeID{"AB", ReverseEdgeID("AB")} {
+				got, err := g.GetEdgeByID(e)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Length != 250 || got.SpeedLimit == nil || *got.SpeedLimit != limit {
+					t.Errorf("edge %s: length %v, speed limit %v; want 250 and %v", e, got.Length, got.SpeedLimit, limit)
+				}
+			}
+		})
+	}
+}
+
+// TestIsolatedNode checks that a node with no edges is reported as a warning rather than
+// stopping the graph being built or validated, unless a route leads to it.
+func TestIsolatedNode(t *testing.T) {
+	g := newTestGraph(t, GraphData{
+		Nodes: []Node{{ID: "A"}, {ID: "B"}, {ID: "X"}},
+		Edges: []Edge{{ID: "AB", U: "A", V: "B", Length: 100}},
+	})
+	if w := g.Warnings(); len(w) != 1 || w[0] != `node "X" is isolated` {
+		t.Errorf("warnings %q, want the isolated node", w)
+	}
+	if err := g.Validate(map[string][]NodeID{"S1": {"A", "B"}}); err != nil {
+		t.Errorf("route avoiding the isolated node: %v", err)
+	}
+	if err := g.Validate(map[string][]NodeID{"S1": {"A", "X"}}); err == nil {
+		t.Error("route to the isolated node: no error")
+	}
+}
@@ -0,0 +1,97 @@
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// GetKShortestPaths returns up to k loopless paths from start to end in order of
+// increasing length, using Yen's algorithm. The first is the shortest path; the rest are
+// the best alternatives, with IDs suffixed "#2", "#3", and so on. Fewer than k paths are
+// returned if no more exist. Returns an error if there is no path at all.
+func (g *Graph) GetKShortestPaths(start, end NodeID, k int) ([]PathInfo, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	first, err := g.GetShortestPath(start, end)
+	if err != nil {
+		return nil, err
+	}
+	paths := []PathInfo{first}
+	var candidates []PathInfo
+
+	for len(paths) < k {
+		prevPath := paths[len(paths)-1]
+		prev := prevPath.Route
+		for i := 0; i+1 < len(prev); i++ {
+			spur, root := prev[i], prev[:i+1]
+
+			// Forbid the next edge of every accepted path sharing this root, and every
+			// root node but the spur, so the spur path diverges and stays loopless.
+			blockedEdges := make(map[[2]NodeID]bool)
+			for _, p := range paths {
+				if len(p.Route) > i+1 && slices.Equal(p.Route[:i+1], root) {
+					blockedEdges[[2]NodeID{p.Route[i], p.Route[i+1]}] = true
+				}
+			}
+			blockedNodes := make(map[NodeID]bool, i)
+			for _, n := range root[:i] {
+				blockedNodes[n] = true
+			}
+			tree := g.computeShortestPathTree(spur, func(e Edge) bool {
+				return blockedEdges[[2]NodeID{e.U, e.V}] || blockedNodes[e.V]
+			})
+			spurLen, ok := tree.dist[end]
+			if !ok {
+				continue
+			}
+
+			rootLen, err := g.routeLength(root)
+			if err != nil {
+				return nil, err
+			}
+			spurRoute, spurEdges := tree.reconstructPath(spur, end)
+			route := append(slices.Clone(root), spurRoute[1:]...)
+			edges := append(slices.Clone(prevPath.EdgeList[:i]), spurEdges...)
+			candidate := newPathInfo("", route, edges, rootLen+spurLen)
+			if !containsRoute(paths, route) && !containsRoute(candidates, route) {
+				candidates = append(candidates, candidate)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+
+		slices.SortStableFunc(candidates, func(a, b PathInfo) int {
+			if c := cmp.Compare(a.Length, b.Length); c != 0 {
+				return c
+			}
+			return strings.Compare(strings.Join(a.Route, "\x00"), strings.Join(b.Route, "\x00"))
+		})
+		best := candidates[0]
+		candidates = candidates[1:]
+		best.ID = fmt.Sprintf("%s#%d", pathKey(start, end), len(paths)+1)
+		paths = append(paths, best)
+	}
+	return paths, nil
+}
+
+// routeLength returns the total length of the edges joining consecutive nodes of route.
+func (g *Graph) routeLength(route []NodeID) (float64, error) {
+	total := 0.0
+	for i := 0; i+1 < len(route); i++ {
+		e, err := g.GetEdge(route[i], route[i+1])
+		if err != nil {
+			return 0, err
+		}
+		total += e.Length
+	}
+	return total, nil
+}
+
+// containsRoute reports whether any of paths follows exactly route.
+func containsRoute(paths []PathInfo, route []NodeID) bool {
+	return slices.ContainsFunc(paths, func(p PathInfo) bool { return slices.Equal(p.Route, route) })
+}
@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+// TestKShortestPaths checks the paths found on a small network with four loopless paths
+// from A to D, two of them of the same length, for every k up to more than there are.
+func TestKShortestPaths(t *testing.T) {
+	g := newTestGraph(t, GraphData{
+		Nodes: []Node{{ID: "A"}, {ID: "B"}, {ID: "C"}, {ID: "D"}},
+		Edges: []Edge{
+			{ID: "AB", U: "A", V: "B", Length: 3},
+			{ID: "AC", U: "A", V: "C", Length: 2},
+			{ID: "AD", U: "A", V: "D", Length: 10},
+			{ID: "BC", U: "B", V: "C", Length: 1},
+			{ID: "BD", U: "B", V: "D", Length: 4},
+			{ID: "CD", U: "C", V: "D", Length: 6},
+		},
+	})
+	want := []struct {
+		route  []NodeID
+		length float64
+	}{
+		{[]NodeID{"A", "B", "D"}, 7},
+		{[]NodeID{"A", "C", "D"}, 8},
+		{[]NodeID{"A", "B", "C", "D"}, 10},
+		{[]NodeID{"A", "D"}, 10},
+	}
+	for k := 1; k <= len(want)+2; k++ {
+		paths, err := g.GetKShortestPaths("A", "D", k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := min(k, len(want)); len(paths) != n {
+			t.Fatalf("k=%d: %d paths, want %d", k, len(paths), n)
+		}
+		for i, p := range paths {
+			if !slices.Equal(p.Route, want[i].route) || p.Length != want[i].length {
+				t.Errorf("k=%d: path %d is %v of %v m, want %v of %v m", k, i+1, p.Route, p.Length, want[i].route, want[i].length)
+			}
+			id := "A->D"
+			if i > 0 {
+				id = fmt.Sprintf("A->D#%d", i+1)
+			}
+			if p.ID != id {
+				t.Errorf("k=%d: path %d has ID %q, want %q", k, i+1, p.ID, id)
+			}
+			checkPath(t, g, p, "A", "D")
+		}
+	}
+
+	if _, err := g.GetKShortestPaths("A", "D", 0); err == nil {
+		t.Error("k=0: no error")
+	}
+	if _, err := g.GetKShortestPaths("D", "A", 3); err == nil {
+		t.Error("no path: no error")
+	}
+}
+
+// simplePathLengths returns the length of every loopless path from start to end of g,
+// found by exhaustive search, in increasing order.
+func simplePathLengths(g *Graph, start, end NodeID) []float64 {
+	var lengths []float64
+	visited := map[NodeID]bool{start: true}
+	var walk func(n NodeID, length float64)
+	walk = func(n NodeID, length float64) {
+		if n == end {
+			lengths = append(lengths, length)
+			return
+		}
+		for _, e := range g.outEdges[n] {
+			if !visited[e.V] {
+				visited[e.V] = true
+				walk(e.V, length+e.Length)
+				visited[e.V] = false
+			}
+		}
+	}
+	walk(start, 0)
+	slices.Sort(lengths)
+	return lengths
+}
+
+// TestKShortestPathsGrid checks, over random grids, that the paths found are loopless,
+// distinct, in order of length and as short as any, against an exhaustive search.
+func TestKShortestPathsGrid(t *testing.T) {
+	for seed := range uint64(5) {
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			g := gridGraph(t, 3, true, seed)
+			start, end := NodeID("0,0"), NodeID("2,2")
+			all := simplePathLengths(g, start, end)
+			paths, err := g.GetKShortestPaths(start, end, len(all)+5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(paths) != len(all) {
+				t.Fatalf("%d paths, want all %d", len(paths), len(all))
+			}
+			for i, p := range paths {
+				checkPath(t, g, p, start, end)
+				route := slices.Clone(p.Route)
+				slices.Sort(route)
+				if len(slices.Compact(route)) != len(p.Route) {
+					t.Errorf("path %d %v has a loop", i+1, p.Route)
+				}
+				for _, q := range paths[:i] {
+					if slices.Equal(p.Route, q.Route) {
+						t.Errorf("path %d %v found twice", i+1, p.Route)
+					}
+				}
+				if math.Abs(p.Length-all[i]) > 1e-9 {
+					t.Errorf("path %d of %v m, want %v m", i+1, p.Length, all[i])
+				}
+			}
+		})
+	}
+}
@@ -1,83 +1,182 @@
 package graph
 
 import (
+	"container/heap"
 	"fmt"
 	"math"
+	"slices"
+	"strings"
 )
 
-// computeShortestPaths runs Floyd-Warshall over all nodes and edges.
-func (g *Graph) computeShortestPaths() {
-	nodeIDs := make([]NodeID, len(g.nodes))
-	for i, n := range g.nodes {
-		nodeIDs[i] = n.ID
-	}
+// shortestPathTree holds single-source Dijkstra results from one origin node.
+type shortestPathTree struct {
+	dist map[NodeID]float64 // metres from the origin to each reachable node
+	prev map[NodeID]NodeID  // predecessor of each reachable node on its shortest path
+	via  map[NodeID]Edge    // edge from the predecessor by which each node is reached
+}
 
-	dist := make(map[NodeID]map[NodeID]float64, len(nodeIDs))
-	next := make(map[NodeID]map[NodeID]NodeID, len(nodeIDs))
-	for _, i := range nodeIDs {
-		dist[i] = make(map[NodeID]float64, len(nodeIDs))
-		next[i] = make(map[NodeID]NodeID, len(nodeIDs))
-		for _, j := range nodeIDs {
-			dist[i][j] = math.Inf(1)
-		}
-		dist[i][i] = 0
-	}
-	for _, e := range g.edges {
-		dist[e.U][e.V] = e.Length
-		next[e.U][e.V] = e.V
+// computeShortestPathTree runs Dijkstra from source over the graph's edges, ignoring any
+// edge for which skip (if non-nil) returns true. Outgoing edges are relaxed in insertion
+// order so ties resolve deterministically.
+func (g *Graph) computeShortestPathTree(source NodeID, skip func(Edge) bool) *shortestPathTree {
+	tree := &shortestPathTree{
+		dist: map[NodeID]float64{source: 0},
+		prev: make(map[NodeID]NodeID),
+		via:  make(map[NodeID]Edge),
 	}
-	for _, k := range nodeIDs {
-		for _, i := range nodeIDs {
-			for _, j := range nodeIDs {
-				if d := dist[i][k] + dist[k][j]; d < dist[i][j] {
-					dist[i][j] = d
-					next[i][j] = next[i][k]
-				}
+	done := make(map[NodeID]bool)
+	pq := &nodeQueue{{node: source, dist: 0}}
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(nodeQueueItem)
+		if done[item.node] {
+			continue
+		}
+		done[item.node] = true
+		for _, e := range g.outEdges[item.node] {
+			if skip != nil && skip(e) {
+				continue
+			}
+			d := item.dist + e.Length
+			if cur, ok := tree.dist[e.V]; !ok || d < cur {
+				tree.dist[e.V] = d
+				tree.prev[e.V] = item.node
+				tree.via[e.V] = e
+				heap.Push(pq, nodeQueueItem{node: e.V, dist: d})
 			}
 		}
 	}
+	return tree
+}
+
+// shortestPathTree returns the cached tree rooted at source, computing it on first use.
+// The caller must hold g.cacheMu.
+func (g *Graph) shortestPathTree(source NodeID) *shortestPathTree {
+	if tree, ok := g.trees[source]; ok {
+		return tree
+	}
+	tree := g.computeShortestPathTree(source, nil)
+	g.trees[source] = tree
+	return tree
+}
 
-	g.dist = dist
-	g.nextNode = next
-	g.pathCache = make(map[PathID]PathInfo) // clear stale cache
+// invalidatePaths discards all cached shortest-path results after a topology change.
+func (g *Graph) invalidatePaths() {
+	g.cacheMu.Lock()
+	defer g.cacheMu.Unlock()
+	g.trees = make(map[NodeID]*shortestPathTree)
+	g.pathCache = make(map[PathID]PathInfo)
 }
 
-func (g *Graph) ensureShortestPaths() {
-	if g.dist == nil {
-		g.computeShortestPaths()
+// invalidatePathsThrough discards only the cached paths and search trees that route over
+// edge e, leaving the rest of the cache intact.
+func (g *Graph) invalidatePathsThrough(e Edge) {
+	g.cacheMu.Lock()
+	defer g.cacheMu.Unlock()
+	for source, tree := range g.trees {
+		if via, ok := tree.via[e.V]; ok && via.ID == e.ID {
+			delete(g.trees, source)
+		}
+	}
+	for key, path := range g.pathCache {
+		if slices.Contains(path.Edges, e.ID) {
+			delete(g.pathCache, key)
+		}
 	}
 }
 
-func (g *Graph) reconstructPath(u, v NodeID) []NodeID {
-	route := []NodeID{u}
-	for u != v {
-		n, ok := g.nextNode[u][v]
-		if !ok || n == "" {
-			return nil // no path
+// reconstructPath returns the nodes of the tree's path from u to v and the edges joining
+// them, or nil for both if there is none.
+func (tree *shortestPathTree) reconstructPath(u, v NodeID) ([]NodeID, []Edge) {
+	route := []NodeID{v}
+	var edges []Edge
+	for v != u {
+		p, ok := tree.prev[v]
+		if !ok {
+			return nil, nil // no path
 		}
-		u = n
-		route = append(route, u)
+		edges = append(edges, tree.via[v])
+		v = p
+		route = append(route, v)
 	}
-	return route
+	slices.Reverse(route)
+	slices.Reverse(edges)
+	return route, edges
+}
+
+// newPathInfo returns the PathInfo for the path along route over edges.
+func newPathInfo(id PathID, route []NodeID, edges []Edge, length float64) PathInfo {
+	ids := make([]EdgeID, len(edges))
+	for i, e := range edges {
+		ids[i] = e.ID
+	}
+	return PathInfo{ID: id, Route: route, Edges: ids, EdgeList: edges, Length: length}
 }
 
 // GetShortestPath returns the shortest path between start and end, using a cache.
-// Returns an error if no path exists.
+// Paths are computed on demand with Dijkstra, one source node at a time, so only the
+// origins actually queried are ever searched. Returns an error if no path exists.
 func (g *Graph) GetShortestPath(start, end NodeID) (PathInfo, error) {
 	if start == end {
 		return PathInfo{ID: pathKey(start, end), Route: []NodeID{start}, Length: 0}, nil
 	}
 	key := pathKey(start, end)
+	g.cacheMu.Lock()
+	defer g.cacheMu.Unlock()
 	if p, ok := g.pathCache[key]; ok {
 		return p, nil
 	}
-	g.ensureShortestPaths()
-	d, ok := g.dist[start][end]
+	tree := g.shortestPathTree(start)
+	d, ok := tree.dist[end]
 	if !ok || math.IsInf(d, 1) {
 		return PathInfo{}, fmt.Errorf("no path from %q to %q", start, end)
 	}
-	route := g.reconstructPath(start, end)
-	p := PathInfo{ID: key, Route: route, Length: d}
+	route, edges := tree.reconstructPath(start, end)
+	p := newPathInfo(key, route, edges, d)
 	g.pathCache[key] = p
 	return p, nil
 }
+
+// GetRouteThrough returns the shortest route visiting waypoints in order, formed by
+// concatenating the shortest path of each consecutive leg. Its ID joins the waypoints
+// with "->". Returns an error naming the first unreachable leg.
+func (g *Graph) GetRouteThrough(waypoints []NodeID) (PathInfo, error) {
+	if len(waypoints) == 0 {
+		return PathInfo{}, fmt.Errorf("no waypoints")
+	}
+	if _, ok := g.nodeMap[waypoints[0]]; !ok {
+		return PathInfo{}, fmt.Errorf("node %q not found", waypoints[0])
+	}
+	route := PathInfo{ID: strings.Join(waypoints, "->"), Route: []NodeID{waypoints[0]}}
+	for i := 0; i+1 < len(waypoints); i++ {
+		leg, err := g.GetShortestPath(waypoints[i], waypoints[i+1])
+		if err != nil {
+			return PathInfo{}, fmt.Errorf("leg %d: %w", i+1, err)
+		}
+		route.Route = append(route.Route, leg.Route[1:]...)
+		route.Edges = append(route.Edges, leg.Edges...)
+		route.EdgeList = append(route.EdgeList, leg.EdgeList...)
+		route.Length += leg.Length
+	}
+	return route, nil
+}
+
+// nodeQueueItem is a node in a search frontier, keyed by tentative distance (Dijkstra)
+// or estimated total cost (A*).
+type nodeQueueItem struct {
+	node NodeID
+	dist float64
+}
+
+// nodeQueue is a min-heap of nodeQueueItems.
+type nodeQueue []nodeQueueItem
+
+func (q nodeQueue) Len() int           { return len(q) }
+func (q nodeQueue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q nodeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x any)        { *q = append(*q, x.(nodeQueueItem)) }
+func (q *nodeQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Validate checks the graph for problems that would otherwise only surface mid-run.
+// It flags, for each named route, every consecutive pair of nodes with no path between
+// them. routes maps a label used in
+// messages (e.g. a service ID) to an ordered list of nodes to visit.
+//
+// All problems are reported together, joined with errors.Join; nil means none were found.
+func (g *Graph) Validate(routes map[string][]NodeID) error {
+	var errs []error
+	names := make([]string, 0, len(routes))
+	for name := range routes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		route := routes[name]
+		for _, n := range route {
+			if _, ok := g.nodeMap[n]; !ok {
+				errs = append(errs, fmt.Errorf("%s: node %q not found", name, n))
+			}
+		}
+		for i := 0; i+1 < len(route); i++ {
+			u, v := route[i], route[i+1]
+			if _, ok := g.nodeMap[u]; !ok {
+				continue
+			}
+			if _, ok := g.nodeMap[v]; !ok {
+				continue
+			}
+			if _, err := g.GetShortestPath(u, v); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// maxLengthRatio is the most an edge's length may differ, by ratio either way, from the
+// straight-line distance between its ends' coordinates. Curves and detours make the
+// length somewhat longer; a difference this large means lengths and coordinates are in
+// different units.
+const maxLengthRatio = 100
+
+// checkLengths reports every edge whose length differs from the distance between its
+// ends by more than maxLengthRatio. Edges whose ends share a location, as when nodes
+// have no coordinates, are not checked. All problems are joined with errors.Join.
+func (g *Graph) checkLengths() error {
+	var errs []error
+	g.eachSpan(func(e Edge, dist float64) {
+		if e.Length*maxLengthRatio < dist || e.Length > dist*maxLengthRatio {
+			errs = append(errs, fmt.Errorf("edge %q: length %v m is out of keeping with the %v m between its ends; are lengths and coordinates in the same unit?", e.ID, e.Length, dist))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// LengthWarnings describes every edge shorter than minRatio times the straight-line
+// distance between its ends. Track cannot be shorter than the straight line, though it
+// is often longer, so such an edge's length or coordinates are likely to be wrong, and
+// services on it will appear to jump when plotted. Edges whose ends share a location are
+// not checked, nor any if minRatio is not positive.
+func (g *Graph) LengthWarnings(minRatio float64) []string {
+	if minRatio <= 0 {
+		return nil
+	}
+	var warnings []string
+	g.eachSpan(func(e Edge, dist float64) {
+		if e.Length < minRatio*dist {
+			warnings = append(warnings, fmt.Sprintf("edge %q: length %v m is less than %v times the %v m straight line between its ends", e.ID, e.Length, minRatio, dist))
+		}
+	})
+	return warnings
+}
+
+// isolatedWarnings describes every node with no edges in or out. Such a node does no
+// harm unless a service is routed to it, which Validate catches, but it is often a
+// mistyped node ID on an edge.
+func (g *Graph) isolatedWarnings() []string {
+	connected := make(map[NodeID]bool, len(g.nodes))
+	for _, e := range g.edges {
+		connected[e.U] = true
+		connected[e.V] = true
+	}
+	var warnings []string
+	for _, n := range g.nodes {
+		if !connected[n.ID] {
+			warnings = append(warnings, fmt.Sprintf("node %q is isolated", n.ID))
+		}
+	}
+	return warnings
+}
+
+// Warnings returns the problems NewGraph found with the network that do not stop it
+// being built: isolated nodes, and edges too short for the distance between their ends
+// (see GraphData.MinLengthRatio).
+func (g *Graph) Warnings() []string { return g.warnings }
+
+// eachSpan calls fn for every edge whose ends are at different locations, with the
+// straight-line distance between them. The reverse of a bidirectional edge is skipped,
+// being the same track.
+func (g *Graph) eachSpan(fn func(e Edge, dist float64)) {
+	for _, e := range g.edges {
+		if fwd, ok := g.reverse[e.ID]; ok && ReverseEdgeID(fwd) == e.ID {
+			continue
+		}
+		if dist := g.straightLine(e); dist > 0 {
+			fn(e, dist)
+		}
+	}
+}
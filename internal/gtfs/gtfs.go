@@ -0,0 +1,308 @@
+// Package gtfs builds a simulation input from a GTFS feed: stops become station nodes,
+// each pair of consecutive stops on a trip an edge, and each trip a service following
+// its published stop times.
+package gtfs
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cxd309/tms-engine/internal/engine"
+	"github.com/cxd309/tms-engine/internal/graph"
+	"github.com/cxd309/tms-engine/internal/service"
+)
+
+// earthRadius is the mean radius of the Earth, metres.
+const earthRadius = 6_371_000
+
+// Load reads the GTFS feed in fsys (stops.txt, routes.txt, trips.txt and stop_times.txt,
+// and shapes.txt if present) and returns a SimulationInput running every trip in it,
+// under meta, with vehicle.
+//
+// Stop coordinates are projected to metres about the feed's mean latitude and
+// longitude. An edge joins each pair of stops consecutive on some trip, named "u->v";
+// its length is measured along the shape of the first trip with one to run over it, or
+// is otherwise the straight-line distance between the stops. Each trip is a service of
+// the same ID, starting at its first stop and calling at the rest, with each stop's
+// arrival and departure times as its scheduled times and the time between them as its
+// minimum dwell. Simulation time is taken as seconds since midnight of the service day,
+// as GTFS times are. If meta has no RunTime, the run lasts until the last arrival. The
+// vehicle is put in the input's vehicle library and every service refers to it.
+func Load(fsys fs.FS, meta engine.SimulationMeta, vehicle service.Vehicle) (engine.SimulationInput, error) {
+	stops, err := readTable(fsys, "stops.txt", "stop_id", "stop_lat", "stop_lon")
+	if err != nil {
+		return engine.SimulationInput{}, err
+	}
+	routes, err := readTable(fsys, "routes.txt", "route_id")
+	if err != nil {
+		return engine.SimulationInput{}, err
+	}
+	trips, err := readTable(fsys, "trips.txt", "route_id", "trip_id")
+	if err != nil {
+		return engine.SimulationInput{}, err
+	}
+	stopTimes, err := readTable(fsys, "stop_times.txt", "trip_id", "stop_id", "stop_sequence", "arrival_time", "departure_time")
+	if err != nil {
+		return engine.SimulationInput{}, err
+	}
+	shapes, err := readShapes(fsys)
+	if err != nil {
+		return engine.SimulationInput{}, err
+	}
+
+	locs, err := stopLocations(stops)
+	if err != nil {
+		return engine.SimulationInput{}, err
+	}
+	origin := meanOf(locs)
+	input := engine.SimulationInput{
+		Meta:      meta,
+		GraphData: graph.GraphData{AutoLengths: true},
+	}
+	for _, s := range stops {
+		id := s.get("stop_id")
+		input.GraphData.Nodes = append(input.GraphData.Nodes, graph.Node{
+			ID:   id,
+			Loc:  origin.project(locs[id]),
+			Type: graph.NodeTypeStation,
+		})
+	}
+
+	calls, err := tripCalls(stopTimes, locs)
+	if err != nil {
+		return engine.SimulationInput{}, err
+	}
+	routeIDs := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		routeIDs[r.get("route_id")] = true
+	}
+
+	name := cmp.Or(vehicle.Name, "vehicle")
+	input.VehicleLibrary = map[string]service.Vehicle{name: vehicle}
+	edges := make(map[graph.EdgeID]int)
+	lastArrival := 0.0
+	for _, trip := range trips {
+		id := trip.get("trip_id")
+		if !routeIDs[trip.get("route_id")] {
+			return engine.SimulationInput{}, fmt.Errorf("trips.txt line %d: route %q not in routes.txt", trip.line, trip.get("route_id"))
+		}
+		seq := calls[id]
+		if len(seq) < 2 {
+			continue // nowhere to go
+		}
+
+		var lengths []float64
+		if shapeID := trip.get("shape_id"); shapeID != "" {
+			shape, ok := shapes[shapeID]
+			if !ok {
+				return engine.SimulationInput{}, fmt.Errorf("trips.txt line %d: shape %q not in shapes.txt", trip.line, shapeID)
+			}
+			lengths = shape.legLengths(origin, seq)
+		}
+		for i := 1; i < len(seq); i++ {
+			u, v := seq[i-1].stop, seq[i].stop
+			edgeID := u + "->" + v
+			j, seen := edges[edgeID]
+			if !seen {
+				j = len(input.GraphData.Edges)
+				edges[edgeID] = j
+				input.GraphData.Edges = append(input.GraphData.Edges, graph.Edge{ID: edgeID, U: u, V: v})
+			}
+			if lengths != nil && input.GraphData.Edges[j].Length == 0 {
+				input.GraphData.Edges[j].Length = lengths[i-1]
+			}
+		}
+
+		svc := service.Service{
+			ServiceID:       id,
+			InitialPosition: seq[0].stop,
+			Vehicle:         service.Vehicle{Ref: name},
+		}
+		for _, c := range seq {
+			stop := service.RouteStop{NodeID: c.stop, MinDwell: c.departure - c.arrival}
+			stop.ScheduledArrival, stop.ScheduledDeparture = &c.arrival, &c.departure
+			svc.Route = append(svc.Route, stop)
+		}
+		input.ServiceList = append(input.ServiceList, svc)
+		lastArrival = math.Max(lastArrival, seq[len(seq)-1].arrival)
+	}
+	if input.Meta.RunTime == 0 {
+		input.Meta.RunTime = lastArrival
+	}
+	return input, nil
+}
+
+// call is a trip's call at a stop, with its times in seconds since midnight.
+type call struct {
+	stop               graph.NodeID
+	loc                latLon
+	seq                int
+	arrival, departure float64
+}
+
+// tripCalls returns the calls of each trip in stopTimes, in stop_sequence order. A call
+// given only one of its arrival and departure times takes it for both; one given neither
+// is left out, as the engine needs times to run to.
+func tripCalls(stopTimes []row, locs map[string]latLon) (map[string][]call, error) {
+	calls := make(map[string][]call)
+	for _, st := range stopTimes {
+		stop := st.get("stop_id")
+		loc, ok := locs[stop]
+		if !ok {
+			return nil, fmt.Errorf("stop_times.txt line %d: stop %q not in stops.txt", st.line, stop)
+		}
+		seq, err := strconv.Atoi(st.get("stop_sequence"))
+		if err != nil {
+			return nil, fmt.Errorf("stop_times.txt line %d: stop_sequence: %w", st.line, err)
+		}
+		arr, err := parseTime(cmp.Or(st.get("arrival_time"), st.get("departure_time")))
+		if err != nil {
+			return nil, fmt.Errorf("stop_times.txt line %d: arrival_time: %w", st.line, err)
+		}
+		dep, err := parseTime(cmp.Or(st.get("departure_time"), st.get("arrival_time")))
+		if err != nil {
+			return nil, fmt.Errorf("stop_times.txt line %d: departure_time: %w", st.line, err)
+		}
+		if math.IsNaN(arr) {
+			continue
+		}
+		if dep < arr {
+			return nil, fmt.Errorf("stop_times.txt line %d: departure_time before arrival_time", st.line)
+		}
+		trip := st.get("trip_id")
+		calls[trip] = append(calls[trip], call{stop: stop, loc: loc, seq: seq, arrival: arr, departure: dep})
+	}
+	for _, seq := range calls {
+		slices.SortFunc(seq, func(a, b call) int { return cmp.Compare(a.seq, b.seq) })
+	}
+	return calls, nil
+}
+
+// parseTime parses a GTFS time, HH:MM:SS past midnight of the service day, into seconds;
+// hours may pass 24 for trips running after midnight. An empty time is NaN.
+func parseTime(s string) (float64, error) {
+	if s == "" {
+		return math.NaN(), nil
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("time %q is not HH:MM:SS", s)
+	}
+	var hms [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || (i > 0 && n >= 60) {
+			return 0, fmt.Errorf("time %q is not HH:MM:SS", s)
+		}
+		hms[i] = n
+	}
+	return float64(hms[0]*3600 + hms[1]*60 + hms[2]), nil
+}
+
+// latLon is a WGS 84 position in degrees.
+type latLon struct{ lat, lon float64 }
+
+// project returns p's position in metres east and north of origin, on a plane tangent
+// at origin, which is accurate enough across a city's network.
+func (origin latLon) project(p latLon) graph.Coordinate {
+	rad := math.Pi / 180
+	return graph.Coordinate{
+		X: earthRadius * (p.lon - origin.lon) * rad * math.Cos(origin.lat*rad),
+		Y: earthRadius * (p.lat - origin.lat) * rad,
+	}
+}
+
+// stopLocations returns the position of each stop in stops.
+func stopLocations(stops []row) (map[string]latLon, error) {
+	locs := make(map[string]latLon, len(stops))
+	for _, s := range stops {
+		p, err := parseLatLon(s.get("stop_lat"), s.get("stop_lon"))
+		if err != nil {
+			return nil, fmt.Errorf("stops.txt line %d: %w", s.line, err)
+		}
+		locs[s.get("stop_id")] = p
+	}
+	return locs, nil
+}
+
+func parseLatLon(lat, lon string) (latLon, error) {
+	var p latLon
+	var err error
+	if p.lat, err = strconv.ParseFloat(lat, 64); err != nil {
+		return latLon{}, fmt.Errorf("latitude: %w", err)
+	}
+	if p.lon, err = strconv.ParseFloat(lon, 64); err != nil {
+		return latLon{}, fmt.Errorf("longitude: %w", err)
+	}
+	return p, nil
+}
+
+// meanOf returns the mean of the positions in locs.
+func meanOf(locs map[string]latLon) latLon {
+	var sum latLon
+	for _, p := range locs {
+		sum.lat += p.lat
+		sum.lon += p.lon
+	}
+	n := float64(max(len(locs), 1))
+	return latLon{sum.lat / n, sum.lon / n}
+}
+
+// row is a record of a GTFS table, by column name, and its line in the file.
+type row struct {
+	line   int
+	fields map[string]string
+}
+
+// get returns the value in column col, or "" if the table has no such column.
+func (r row) get(col string) string { return r.fields[col] }
+
+// readTable reads the GTFS table name from fsys, which must have the columns required.
+func readTable(fsys fs.FS, name string, required ...string) ([]row, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading header: %w", name, err)
+	}
+	for i, col := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))
+	}
+	for _, col := range required {
+		if !slices.Contains(header, col) {
+			return nil, fmt.Errorf("%s: no %s column", name, col)
+		}
+	}
+
+	var rows []row
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		fields := make(map[string]string, len(header))
+		for i, v := range record {
+			if i < len(header) {
+				fields[header[i]] = strings.TrimSpace(v)
+			}
+		}
+		rows = append(rows, row{line: line, fields: fields})
+	}
+}
@@ -0,0 +1,95 @@
+package gtfs
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/cxd309/tms-engine/internal/graph"
+)
+
+// shape is a trip's path as drawn, a polyline of positions in order.
+type shape []latLon
+
+// readShapes reads shapes.txt from fsys, if it has one, into shapes by shape_id.
+func readShapes(fsys fs.FS) (map[string]shape, error) {
+	rows, err := readTable(fsys, "shapes.txt", "shape_id", "shape_pt_lat", "shape_pt_lon", "shape_pt_sequence")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type point struct {
+		seq int
+		p   latLon
+	}
+	points := make(map[string][]point)
+	for _, r := range rows {
+		p, err := parseLatLon(r.get("shape_pt_lat"), r.get("shape_pt_lon"))
+		if err != nil {
+			return nil, fmt.Errorf("shapes.txt line %d: %w", r.line, err)
+		}
+		seq, err := strconv.Atoi(r.get("shape_pt_sequence"))
+		if err != nil {
+			return nil, fmt.Errorf("shapes.txt line %d: shape_pt_sequence: %w", r.line, err)
+		}
+		id := r.get("shape_id")
+		points[id] = append(points[id], point{seq, p})
+	}
+	shapes := make(map[string]shape, len(points))
+	for id, pts := range points {
+		slices.SortFunc(pts, func(a, b point) int { return cmp.Compare(a.seq, b.seq) })
+		for _, pt := range pts {
+			shapes[id] = append(shapes[id], pt.p)
+		}
+	}
+	return shapes, nil
+}
+
+// legLengths returns the distance (metres) along s between each pair of consecutive
+// calls, projected about origin. Each stop is placed at the point of s nearest it, no
+// earlier along s than the stop before, so that a shape passing a stop twice is followed
+// in order. A shape of fewer than two points gives no lengths.
+func (s shape) legLengths(origin latLon, calls []call) []float64 {
+	if len(s) < 2 {
+		return nil
+	}
+	pts := make([]graph.Coordinate, len(s))
+	for i, p := range s {
+		pts[i] = origin.project(p)
+	}
+	// cum[i] is the distance along the shape to its i-th point.
+	cum := make([]float64, len(pts))
+	for i := 1; i < len(pts); i++ {
+		cum[i] = cum[i-1] + math.Hypot(pts[i].X-pts[i-1].X, pts[i].Y-pts[i-1].Y)
+	}
+
+	lengths := make([]float64, 0, len(calls)-1)
+	seg, prev := 0, 0.0
+	for i, c := range calls {
+		at, nearest := 0.0, math.Inf(1)
+		stop := origin.project(c.loc)
+		for j := seg; j < len(pts)-1; j++ {
+			a, b := pts[j], pts[j+1]
+			dx, dy := b.X-a.X, b.Y-a.Y
+			t := 0.0
+			if l2 := dx*dx + dy*dy; l2 > 0 {
+				t = math.Max(0, math.Min(1, ((stop.X-a.X)*dx+(stop.Y-a.Y)*dy)/l2))
+			}
+			if d := math.Hypot(a.X+t*dx-stop.X, a.Y+t*dy-stop.Y); d < nearest {
+				nearest, at, seg = d, cum[j]+t*(cum[j+1]-cum[j]), j
+			}
+		}
+		if i > 0 {
+			lengths = append(lengths, math.Max(0, at-prev))
+		}
+		prev = at
+	}
+	return lengths
+}
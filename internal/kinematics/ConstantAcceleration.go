@@ -1,18 +1,106 @@
 package kinematics
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 // ConstantModelName is the JSON discriminator string for the Constant model.
 const ConstantModelName = "constant"
 
+func init() { Register(ConstantModelName, parse[ConstantAcceleration]) }
+
+// ModelName returns ConstantModelName.
+func (c ConstantAcceleration) ModelName() string { return ConstantModelName }
+
 // ConstantAcceleration implements MotionModel using fixed acceleration and deceleration rates.
 // This is the default and simplest kinematics model.
 //
+// If Power and Mass are both set, traction is power-limited: above the base speed
+// power/(mass·a_acc) the acceleration tapers as power/(mass·v), giving a long tail
+// toward v_max.
+//
 // JSON discriminator: "model": "constant"
 type ConstantAcceleration struct {
-	AAcc    float64 `json:"a_acc"` // traction acceleration, m/s²
-	ADcc    float64 `json:"a_dcc"` // service braking deceleration, m/s² (positive)
-	VMaxVal float64 `json:"v_max"` // maximum speed, m/s
+	AAcc    float64 `json:"a_acc"`           // traction acceleration, m/s²
+	ADcc    float64 `json:"a_dcc"`           // service braking deceleration, m/s² (positive)
+	VMaxVal float64 `json:"v_max"`           // maximum speed, m/s
+	Power   float64 `json:"power,omitempty"` // traction power at the wheel, W; 0 = unlimited
+	Mass    float64 `json:"mass,omitempty"`  // vehicle mass, kg; required with power
+}
+
+func (c ConstantAcceleration) Validate() error {
+	if err := checkRates(c.AAcc, c.ADcc, c.VMaxVal); err != nil {
+		return err
+	}
+	if c.Power < 0 {
+		return fmt.Errorf("power %v must not be negative", c.Power)
+	}
+	if c.Power > 0 && c.Mass <= 0 {
+		return fmt.Errorf("mass %v must be positive with power", c.Mass)
+	}
+	return nil
+}
+
+// WithLoad returns a copy of the model with a_acc and a_dcc scaled by
+// emptyMass/(emptyMass+loadMass) and any power-limiting mass increased to match.
+func (c ConstantAcceleration) WithLoad(emptyMass, loadMass float64) MotionModel {
+	return c.withLoad(emptyMass, loadMass)
+}
+
+func (c ConstantAcceleration) withLoad(emptyMass, loadMass float64) ConstantAcceleration {
+	f := emptyMass / (emptyMass + loadMass)
+	c.AAcc *= f
+	c.ADcc *= f
+	c.Mass /= f
+	return c
+}
+
+// Couple returns the model for the consist of c, of the given mass, and other, which must
+// also be a constant model. Traction power adds up and, if any, acts on the consist mass.
+func (c ConstantAcceleration) Couple(other MotionModel, mass, otherMass float64) (MotionModel, error) {
+	o, ok := other.(ConstantAcceleration)
+	if !ok {
+		return nil, mismatchError(c, other)
+	}
+	return c.couple(o, mass, otherMass), nil
+}
+
+func (c ConstantAcceleration) couple(o ConstantAcceleration, mass, otherMass float64) ConstantAcceleration {
+	f := mass / (mass + otherMass)
+	c.AAcc = mix(c.AAcc, o.AAcc, f)
+	c.ADcc = mix(c.ADcc, o.ADcc, f)
+	c.VMaxVal = math.Min(c.VMaxVal, o.VMaxVal)
+	c.Power += o.Power
+	c.Mass = 0
+	if c.Power > 0 {
+		c.Mass = mass + otherMass
+	}
+	return c
+}
+
+// WithAdhesion returns a copy of the model with a_dcc scaled by the adhesion factor.
+func (c ConstantAcceleration) WithAdhesion(factor float64) MotionModel {
+	c.ADcc *= factor
+	return c
+}
+
+// ScaleSpeeds returns a copy of the model with v_max multiplied by factor.
+func (c ConstantAcceleration) ScaleSpeeds(factor float64) MotionModel {
+	c.VMaxVal *= factor
+	return c
+}
+
+// powerLimited reports whether traction tapers above the base speed.
+func (c ConstantAcceleration) powerLimited() bool { return c.Power > 0 && c.Mass > 0 }
+
+// BaseSpeed returns the speed above which traction is power-limited, m/s.
+// Returns +Inf if the model is not power-limited.
+func (c ConstantAcceleration) BaseSpeed() float64 {
+	if !c.powerLimited() || c.AAcc <= 0 {
+		return math.Inf(1)
+	}
+	return c.Power / (c.Mass * c.AAcc)
 }
 
 func (c ConstantAcceleration) VMax() float64 { return c.VMaxVal }
@@ -42,9 +130,16 @@ func (c ConstantAcceleration) VelocityAfterBraking(v0, dist float64) float64 {
 }
 
 func (c ConstantAcceleration) AccelerateStep(v, targetV, dt float64) (float64, float64) {
-	if c.AAcc <= 0 || v >= targetV {
+	if v >= targetV {
 		return targetV * dt, targetV
 	}
+	if c.AAcc <= 0 {
+		// No traction: the vehicle can at best hold speed.
+		return v * dt, v
+	}
+	if vb := c.BaseSpeed(); targetV > vb {
+		return c.powerLimitedStep(v, vb, targetV, dt)
+	}
 	tToTarget := (targetV - v) / c.AAcc
 	if tToTarget <= dt {
 		// Reaches targetV mid-step: accelerate, then cruise for the remainder.
@@ -70,3 +165,30 @@ func (c ConstantAcceleration) DecelerateStep(v, targetV, dt float64) (float64, f
 	newV := v - c.ADcc*dt
 	return math.Max(0, v*dt-0.5*c.ADcc*dt*dt), newV
 }
+
+func (c ConstantAcceleration) EnergyDelta(v0, v1, _, mass float64) float64 {
+	return kineticEnergyDelta(v0, v1, mass)
+}
+
+// powerLimitedStep accelerates toward a targetV above the base speed vb: at a_acc up to
+// vb, then at constant power, where v² grows linearly at 2·power/mass.
+func (c ConstantAcceleration) powerLimitedStep(v, vb, targetV, dt float64) (float64, float64) {
+	dist := 0.0
+	if v < vb {
+		tToBase := (vb - v) / c.AAcc
+		if tToBase >= dt {
+			return v*dt + 0.5*c.AAcc*dt*dt, v + c.AAcc*dt
+		}
+		dist = v*tToBase + 0.5*c.AAcc*tToBase*tToBase
+		dt -= tToBase
+		v = vb
+	}
+	k := 2 * c.Power / c.Mass
+	powerDist := func(v0, v1 float64) float64 { return 2 * (v1*v1*v1 - v0*v0*v0) / (3 * k) }
+	if tToTarget := (targetV*targetV - v*v) / k; tToTarget <= dt {
+		// Reaches targetV mid-step: accelerate, then cruise for the remainder.
+		return dist + powerDist(v, targetV) + targetV*(dt-tToTarget), targetV
+	}
+	newV := math.Sqrt(v*v + k*dt)
+	return dist + powerDist(v, newV), newV
+}
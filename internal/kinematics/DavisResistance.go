@@ -0,0 +1,182 @@
+package kinematics
+
+import (
+	"fmt"
+	"math"
+)
+
+// DavisModelName is the JSON discriminator string for the Davis model.
+const DavisModelName = "davis"
+
+func init() { Register(DavisModelName, parse[DavisResistance]) }
+
+// ModelName returns DavisModelName.
+func (d DavisResistance) ModelName() string { return DavisModelName }
+
+// davisSubStep is the time sub-step used when integrating motion under resistance, seconds.
+const davisSubStep = 0.01
+
+// davisVelocitySteps is the number of velocity intervals used when integrating braking distance.
+const davisVelocitySteps = 200
+
+// DavisResistance implements MotionModel with fixed tractive and braking rates opposed by
+// velocity-dependent running resistance given by the Davis equation:
+//
+//	r(v) = A + B·v + C·v²
+//
+// Resistance is expressed per unit mass (m/s²) so no vehicle mass is needed. It is
+// subtracted from traction when accelerating, adds to braking when decelerating, and
+// alone slows the vehicle when coasting. Because the net rate varies with speed, motion
+// is integrated numerically rather than in closed form.
+//
+// JSON discriminator: "model": "davis"
+type DavisResistance struct {
+	AAcc    float64 `json:"a_acc"` // tractive acceleration before resistance, m/s²
+	ADcc    float64 `json:"a_dcc"` // braking deceleration before resistance, m/s² (positive)
+	VMaxVal float64 `json:"v_max"` // maximum speed, m/s
+	A       float64 `json:"a"`     // constant resistance term, m/s²
+	B       float64 `json:"b"`     // linear resistance term, 1/s
+	C       float64 `json:"c"`     // quadratic resistance term, 1/m
+}
+
+func (d DavisResistance) Validate() error {
+	if err := checkRates(d.AAcc, d.ADcc, d.VMaxVal); err != nil {
+		return err
+	}
+	if d.A < 0 || d.B < 0 || d.C < 0 {
+		return fmt.Errorf("resistance terms a, b and c must not be negative")
+	}
+	return nil
+}
+
+// Resistance returns the running resistance deceleration at velocity v, m/s².
+func (d DavisResistance) Resistance(v float64) float64 {
+	return d.A + d.B*v + d.C*v*v
+}
+
+// Couple returns the model for the consist of d, of the given mass, and other, which must
+// also be a Davis model. Resistance terms, being per unit mass, are averaged like the rates.
+func (d DavisResistance) Couple(other MotionModel, mass, otherMass float64) (MotionModel, error) {
+	o, ok := other.(DavisResistance)
+	if !ok {
+		return nil, mismatchError(d, other)
+	}
+	f := mass / (mass + otherMass)
+	d.AAcc = mix(d.AAcc, o.AAcc, f)
+	d.ADcc = mix(d.ADcc, o.ADcc, f)
+	d.VMaxVal = math.Min(d.VMaxVal, o.VMaxVal)
+	d.A = mix(d.A, o.A, f)
+	d.B = mix(d.B, o.B, f)
+	d.C = mix(d.C, o.C, f)
+	return d, nil
+}
+
+// WithAdhesion returns a copy of the model with a_dcc scaled by the adhesion factor.
+// Running resistance is unaffected.
+func (d DavisResistance) WithAdhesion(factor float64) MotionModel {
+	d.ADcc *= factor
+	return d
+}
+
+// ScaleSpeeds returns a copy of the model with v_max multiplied by factor. The
+// resistance terms are always in SI units.
+func (d DavisResistance) ScaleSpeeds(factor float64) MotionModel {
+	d.VMaxVal *= factor
+	return d
+}
+
+func (d DavisResistance) VMax() float64 { return d.VMaxVal }
+
+func (d DavisResistance) BrakingDistance(v float64) float64 {
+	return d.BrakingDistanceTo(v, 0)
+}
+
+// BrakingDistanceTo integrates ds = v / (a_dcc + r(v)) dv from targetV up to v.
+func (d DavisResistance) BrakingDistanceTo(v, targetV float64) float64 {
+	if d.ADcc <= 0 {
+		return math.Inf(1)
+	}
+	if v <= targetV {
+		return 0
+	}
+	dv := (v - targetV) / davisVelocitySteps
+	dist := 0.0
+	for i := range davisVelocitySteps {
+		u := targetV + (float64(i)+0.5)*dv
+		dist += u / (d.ADcc + d.Resistance(u)) * dv
+	}
+	return dist
+}
+
+func (d DavisResistance) VelocityAfterBraking(v0, dist float64) float64 {
+	if d.ADcc <= 0 {
+		return v0
+	}
+	if v0 <= 0 {
+		return 0
+	}
+	// Step down in velocity, accumulating distance until dist is used up.
+	dv := v0 / davisVelocitySteps
+	u := v0
+	for u > 0 {
+		mid := math.Max(0, u-dv/2)
+		ds := mid / (d.ADcc + d.Resistance(mid)) * dv
+		if ds >= dist {
+			return u - dv*dist/ds
+		}
+		dist -= ds
+		u -= dv
+	}
+	return 0
+}
+
+func (d DavisResistance) AccelerateStep(v, targetV, dt float64) (float64, float64) {
+	if v >= targetV {
+		return targetV * dt, targetV
+	}
+	return d.integrate(v, targetV, dt, func(u float64) float64 { return d.AAcc - d.Resistance(u) })
+}
+
+func (d DavisResistance) DecelerateStep(v, targetV, dt float64) (float64, float64) {
+	if d.ADcc <= 0 || v <= targetV {
+		return targetV * dt, targetV
+	}
+	return d.integrate(v, targetV, dt, func(u float64) float64 { return -(d.ADcc + d.Resistance(u)) })
+}
+
+// CoastStep lets resistance alone slow the vehicle over dt seconds.
+func (d DavisResistance) CoastStep(v, dt float64) (float64, float64) {
+	return d.integrate(v, 0, dt, func(u float64) float64 { return -d.Resistance(u) })
+}
+
+// EnergyDelta includes the work done against running resistance at the mean velocity.
+func (d DavisResistance) EnergyDelta(v0, v1, dist, mass float64) float64 {
+	return kineticEnergyDelta(v0, v1, mass) + mass*d.Resistance((v0+v1)/2)*dist
+}
+
+// integrate advances a vehicle at velocity v over dt seconds under the velocity-dependent
+// acceleration accel, in fixed sub-steps. If targetV is crossed mid-step the vehicle holds
+// targetV for the remainder. Velocity never falls below zero.
+func (d DavisResistance) integrate(v, targetV, dt float64, accel func(v float64) float64) (float64, float64) {
+	dist := 0.0
+	for t := 0.0; t < dt; t += davisSubStep {
+		h := math.Min(davisSubStep, dt-t)
+		a := accel(v)
+		newV := v + a*h
+		if (a > 0 && v < targetV && newV >= targetV) || (a < 0 && v > targetV && newV <= targetV) {
+			tt := (targetV - v) / a
+			dist += v*tt + 0.5*a*tt*tt + targetV*(dt-t-tt)
+			return dist, targetV
+		}
+		if newV <= 0 {
+			if a == 0 {
+				return dist, 0 // standing, with nothing to move it
+			}
+			tt := -v / a
+			return dist + v*tt + 0.5*a*tt*tt, 0
+		}
+		dist += v*h + 0.5*a*h*h
+		v = newV
+	}
+	return dist, v
+}
@@ -0,0 +1,46 @@
+package kinematics
+
+import (
+	"math"
+	"testing"
+)
+
+// TestDavisStanding checks that a standing vehicle with nothing to move it stays put,
+// rather than coming out at a NaN distance.
+func TestDavisStanding(t *testing.T) {
+	tests := []struct {
+		name string
+		d    DavisResistance
+	}{
+		{"no resistance", DavisResistance{AAcc: 1, ADcc: 1, VMaxVal: 20}},
+		{"resistance", DavisResistance{AAcc: 1, ADcc: 1, VMaxVal: 20, A: 0.01, B: 0.001, C: 0.0001}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := map[string]func() (float64, float64){
+				"coast":      func() (float64, float64) { return tt.d.CoastStep(0, 1) },
+				"decelerate": func() (float64, float64) { return tt.d.DecelerateStep(0, 0, 1) },
+				"accelerate": func() (float64, float64) { return tt.d.AccelerateStep(0, 0, 1) },
+			}
+			for name, step := range steps {
+				dist, v := step()
+				if math.IsNaN(dist) || math.IsNaN(v) || dist != 0 || v != 0 {
+					t.Errorf("%s from a stand: got (%v, %v), want (0, 0)", name, dist, v)
+				}
+			}
+		})
+	}
+}
+
+// TestDavisCoastToStand checks that coasting under resistance alone comes to a stand and
+// stays there.
+func TestDavisCoastToStand(t *testing.T) {
+	d := DavisResistance{AAcc: 1, ADcc: 1, VMaxVal: 20, A: 0.5}
+	dist, v := d.CoastStep(1, 10)
+	if want := 1.0; math.Abs(dist-want) > 1e-9 || v != 0 {
+		t.Errorf("CoastStep(1, 10) = (%v, %v), want (%v, 0)", dist, v, want)
+	}
+	if dist, v := d.CoastStep(v, 10); dist != 0 || v != 0 {
+		t.Errorf("CoastStep(0, 10) = (%v, %v), want (0, 0)", dist, v)
+	}
+}
@@ -0,0 +1,114 @@
+package kinematics
+
+import "math"
+
+// GradientModelName is the JSON discriminator string for the GradientAware model.
+const GradientModelName = "gradient"
+
+func init() { Register(GradientModelName, parse[GradientAwareAcceleration]) }
+
+// ModelName returns GradientModelName.
+func (g GradientAwareAcceleration) ModelName() string { return GradientModelName }
+
+// StandardGravity is the acceleration due to gravity, m/s².
+const StandardGravity = 9.80665
+
+// GradientAwareAcceleration implements MotionModel using fixed base acceleration and
+// deceleration rates adjusted for the gradient of the track under the vehicle.
+// On a rising gradient traction is reduced and braking is assisted by g·sin(θ); on a
+// falling gradient the reverse applies, so stopping distances grow on descents.
+//
+// The base rates are the flat-track values. Gradient is set per timestep by the engine
+// via AtGradient and is never read from JSON.
+//
+// JSON discriminator: "model": "gradient"
+type GradientAwareAcceleration struct {
+	ConstantAcceleration
+	Gradient float64 `json:"-"` // per mille, positive = rising in the direction of travel
+}
+
+// AtGradient returns a copy of the model evaluated on the given gradient.
+func (g GradientAwareAcceleration) AtGradient(gradient float64) MotionModel {
+	g.Gradient = gradient
+	return g
+}
+
+// WithLoad returns a copy of the model with its flat-track rates scaled for the load.
+func (g GradientAwareAcceleration) WithLoad(emptyMass, loadMass float64) MotionModel {
+	g.ConstantAcceleration = g.ConstantAcceleration.withLoad(emptyMass, loadMass)
+	return g
+}
+
+// Couple returns the model for the consist of g, of the given mass, and other, which must
+// also be a gradient model, combining their flat-track rates as for the constant model.
+func (g GradientAwareAcceleration) Couple(other MotionModel, mass, otherMass float64) (MotionModel, error) {
+	o, ok := other.(GradientAwareAcceleration)
+	if !ok {
+		return nil, mismatchError(g, other)
+	}
+	g.ConstantAcceleration = g.ConstantAcceleration.couple(o.ConstantAcceleration, mass, otherMass)
+	return g, nil
+}
+
+// WithAdhesion returns a copy of the model with its flat-track a_dcc scaled by the
+// adhesion factor.
+func (g GradientAwareAcceleration) WithAdhesion(factor float64) MotionModel {
+	g.ADcc *= factor
+	return g
+}
+
+// ScaleSpeeds returns a copy of the model with v_max multiplied by factor.
+func (g GradientAwareAcceleration) ScaleSpeeds(factor float64) MotionModel {
+	g.VMaxVal *= factor
+	return g
+}
+
+// sinTheta returns the sine of the gradient angle.
+func (g GradientAwareAcceleration) sinTheta() float64 {
+	rise := g.Gradient / 1000
+	return rise / math.Sqrt(1+rise*rise)
+}
+
+// effective returns the constant-rate model equivalent to g on its current gradient.
+func (g GradientAwareAcceleration) effective() ConstantAcceleration {
+	c := g.ConstantAcceleration
+	gs := StandardGravity * g.sinTheta()
+	c.AAcc -= gs
+	c.ADcc += gs
+	return c
+}
+
+func (g GradientAwareAcceleration) BrakingDistance(v float64) float64 {
+	return g.effective().BrakingDistance(v)
+}
+
+func (g GradientAwareAcceleration) BrakingDistanceTo(v, targetV float64) float64 {
+	return g.effective().BrakingDistanceTo(v, targetV)
+}
+
+func (g GradientAwareAcceleration) VelocityAfterBraking(v0, dist float64) float64 {
+	return g.effective().VelocityAfterBraking(v0, dist)
+}
+
+func (g GradientAwareAcceleration) AccelerateStep(v, targetV, dt float64) (float64, float64) {
+	c := g.effective()
+	if c.AAcc <= 0 && v < targetV {
+		// The gradient exceeds the available traction: the vehicle can at best hold speed.
+		return v * dt, v
+	}
+	return c.AccelerateStep(v, targetV, dt)
+}
+
+func (g GradientAwareAcceleration) DecelerateStep(v, targetV, dt float64) (float64, float64) {
+	c := g.effective()
+	if c.ADcc <= 0 && v > targetV {
+		// The descent exceeds the available braking: the vehicle cannot shed speed.
+		return v * dt, v
+	}
+	return c.DecelerateStep(v, targetV, dt)
+}
+
+// EnergyDelta includes the change in potential energy climbing or descending the gradient.
+func (g GradientAwareAcceleration) EnergyDelta(v0, v1, dist, mass float64) float64 {
+	return kineticEnergyDelta(v0, v1, mass) + mass*StandardGravity*g.sinTheta()*dist
+}
@@ -0,0 +1,218 @@
+package kinematics
+
+import (
+	"fmt"
+	"math"
+)
+
+// JerkModelName is the JSON discriminator string for the JerkLimited model.
+const JerkModelName = "jerk"
+
+func init() { Register(JerkModelName, parse[JerkLimited]) }
+
+// ModelName returns JerkModelName.
+func (j JerkLimited) ModelName() string { return JerkModelName }
+
+// jerkSubStep is the time sub-step used when integrating jerk-limited motion, seconds.
+const jerkSubStep = 0.01
+
+// jerkBisections is the number of bisection rounds used to invert the braking curve.
+const jerkBisections = 60
+
+// JerkLimited implements MotionModel with acceleration and braking rates that ramp
+// between zero and their maximum at a limited jerk, producing S-curve velocity profiles
+// instead of the instantaneous rate changes of ConstantAcceleration.
+//
+// The acceleration currently applied is carried between timesteps in a State bound via
+// WithState; an unbound model behaves as if starting each step from zero acceleration.
+//
+// JSON discriminator: "model": "jerk"
+type JerkLimited struct {
+	AAcc    float64 `json:"a_acc"` // maximum traction acceleration, m/s²
+	ADcc    float64 `json:"a_dcc"` // maximum service braking deceleration, m/s² (positive)
+	VMaxVal float64 `json:"v_max"` // maximum speed, m/s
+	JAcc    float64 `json:"j_acc"` // traction jerk limit, m/s³
+	JDcc    float64 `json:"j_dcc"` // braking jerk limit, m/s³
+	state   *State
+}
+
+func (j JerkLimited) Validate() error {
+	if err := checkRates(j.AAcc, j.ADcc, j.VMaxVal); err != nil {
+		return err
+	}
+	switch {
+	case j.JAcc < 0:
+		return fmt.Errorf("j_acc %v must not be negative", j.JAcc)
+	case j.JDcc < 0:
+		return fmt.Errorf("j_dcc %v must not be negative", j.JDcc)
+	}
+	return nil
+}
+
+// WithState returns a copy of the model bound to s.
+func (j JerkLimited) WithState(s *State) MotionModel {
+	j.state = s
+	return j
+}
+
+// Couple returns the model for the consist of j, of the given mass, and other, which must
+// also be a jerk model. The jerk limits are averaged like the rates.
+func (j JerkLimited) Couple(other MotionModel, mass, otherMass float64) (MotionModel, error) {
+	o, ok := other.(JerkLimited)
+	if !ok {
+		return nil, mismatchError(j, other)
+	}
+	f := mass / (mass + otherMass)
+	j.AAcc = mix(j.AAcc, o.AAcc, f)
+	j.ADcc = mix(j.ADcc, o.ADcc, f)
+	j.VMaxVal = math.Min(j.VMaxVal, o.VMaxVal)
+	j.JAcc = mix(j.JAcc, o.JAcc, f)
+	j.JDcc = mix(j.JDcc, o.JDcc, f)
+	return j, nil
+}
+
+// WithAdhesion returns a copy of the model with a_dcc scaled by the adhesion factor.
+func (j JerkLimited) WithAdhesion(factor float64) MotionModel {
+	j.ADcc *= factor
+	return j
+}
+
+// ScaleSpeeds returns a copy of the model with v_max multiplied by factor.
+func (j JerkLimited) ScaleSpeeds(factor float64) MotionModel {
+	j.VMaxVal *= factor
+	return j
+}
+
+func (j JerkLimited) VMax() float64 { return j.VMaxVal }
+
+// braking returns the braking deceleration currently applied (positive, 0 if not braking).
+func (j JerkLimited) braking() float64 {
+	if j.state == nil {
+		return 0
+	}
+	return math.Max(0, -j.state.Acceleration)
+}
+
+func (j JerkLimited) BrakingDistance(v float64) float64 {
+	return j.BrakingDistanceTo(v, 0)
+}
+
+// BrakingDistanceTo accounts for ramping the braking rate up from its current value to
+// a_dcc at j_dcc before braking at the full rate.
+func (j JerkLimited) BrakingDistanceTo(v, targetV float64) float64 {
+	if j.ADcc <= 0 {
+		return math.Inf(1)
+	}
+	if v <= targetV {
+		return 0
+	}
+	b0 := math.Min(j.braking(), j.ADcc)
+	if j.JDcc <= 0 || b0 >= j.ADcc {
+		return (v*v - targetV*targetV) / (2 * j.ADcc)
+	}
+	// Ramp phase: b(t) = b0 + J·t, v(t) = v − b0·t − J·t²/2.
+	t1 := (j.ADcc - b0) / j.JDcc
+	rampDist := func(t float64) float64 { return v*t - b0*t*t/2 - j.JDcc*t*t*t/6 }
+	v1 := v - b0*t1 - j.JDcc*t1*t1/2
+	if v1 <= targetV {
+		// Target reached before full braking is applied.
+		t := (-b0 + math.Sqrt(b0*b0+2*j.JDcc*(v-targetV))) / j.JDcc
+		return rampDist(t)
+	}
+	return rampDist(t1) + (v1*v1-targetV*targetV)/(2*j.ADcc)
+}
+
+// VelocityAfterBraking inverts BrakingDistanceTo by bisection.
+func (j JerkLimited) VelocityAfterBraking(v0, dist float64) float64 {
+	if j.ADcc <= 0 {
+		return v0
+	}
+	if dist >= j.BrakingDistance(v0) {
+		return 0
+	}
+	lo, hi := 0.0, v0
+	for range jerkBisections {
+		mid := (lo + hi) / 2
+		if j.BrakingDistanceTo(v0, mid) > dist {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+func (j JerkLimited) AccelerateStep(v, targetV, dt float64) (float64, float64) {
+	if v >= targetV {
+		j.setAcceleration(0)
+		return targetV * dt, targetV
+	}
+	if j.AAcc <= 0 {
+		// No traction: the vehicle can at best hold speed.
+		j.setAcceleration(0)
+		return v * dt, v
+	}
+	return j.integrate(v, targetV, dt, j.AAcc, j.JAcc, true)
+}
+
+func (j JerkLimited) DecelerateStep(v, targetV, dt float64) (float64, float64) {
+	if j.ADcc <= 0 || v <= targetV {
+		j.setAcceleration(0)
+		return targetV * dt, targetV
+	}
+	// Braking to a stand holds the full rate to the end; otherwise the rate is eased off
+	// so the vehicle settles onto targetV.
+	return j.integrate(v, targetV, dt, j.ADcc, j.JDcc, targetV > 0)
+}
+
+func (j JerkLimited) EnergyDelta(v0, v1, _, mass float64) float64 {
+	return kineticEnergyDelta(v0, v1, mass)
+}
+
+// integrate advances the vehicle toward targetV over dt seconds, ramping the rate toward
+// aMax at jerk jerk (an infinite jerk if jerk ≤ 0), and easing it back to zero on the
+// approach to targetV when easeOut is set.
+func (j JerkLimited) integrate(v, targetV, dt, aMax, jerk float64, easeOut bool) (float64, float64) {
+	sign := 1.0
+	if targetV < v {
+		sign = -1
+	}
+	a := 0.0 // rate magnitude in the direction of travel toward targetV
+	if j.state != nil {
+		a = math.Max(0, sign*j.state.Acceleration)
+	}
+
+	dist := 0.0
+	for t := 0.0; t < dt; t += jerkSubStep {
+		h := math.Min(jerkSubStep, dt-t)
+		gap := sign * (targetV - v)
+		switch {
+		case jerk <= 0:
+			a = aMax
+		case easeOut && gap <= a*a/(2*jerk):
+			a = math.Max(0, a-jerk*h)
+		default:
+			a = math.Min(aMax, a+jerk*h)
+		}
+		newV := v + sign*a*h
+		if sign*(targetV-newV) <= 0 || (easeOut && a == 0 && gap < 1e-6) {
+			tt := h
+			if a > 0 {
+				tt = math.Min(h, gap/a)
+			}
+			dist += (v+targetV)/2*tt + targetV*(dt-t-tt)
+			j.setAcceleration(0)
+			return dist, targetV
+		}
+		dist += (v + newV) / 2 * h
+		v = newV
+	}
+	j.setAcceleration(sign * a)
+	return dist, v
+}
+
+func (j JerkLimited) setAcceleration(a float64) {
+	if j.state != nil {
+		j.state.Acceleration = a
+	}
+}
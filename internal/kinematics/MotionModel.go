@@ -2,10 +2,12 @@
 // physics, along with built-in implementations.
 //
 // Adding a new physics model requires only implementing MotionModel and registering it
-// in the JSON discriminator in the service package — the simulation engine itself
-// never needs to change.
+// under its JSON discriminator with Register — neither the service package nor the
+// simulation engine needs to change.
 package kinematics
 
+import "fmt"
+
 // MotionModel is the physics contract every kinematics implementation must satisfy.
 // All distance values are in metres, velocities in m/s, and time in seconds.
 type MotionModel interface {
@@ -34,4 +36,139 @@ type MotionModel interface {
 	// vehicle cruises at targetV for the remainder.
 	// Returns (distance travelled, new velocity).
 	DecelerateStep(v, targetV, dt float64) (dist, newV float64)
+
+	// EnergyDelta returns the energy (J) needed at the wheel to take a vehicle of the given
+	// mass (kg) from v0 to v1 over dist metres. Negative values are energy recoverable by
+	// regenerative braking.
+	EnergyDelta(v0, v1, dist, mass float64) float64
+}
+
+// GradientSensitive is implemented by models whose performance depends on the gradient
+// of the track under the vehicle. The engine evaluates a service's model at the gradient
+// of the edge under its front before every step, coasting included, so implementing it
+// is all a model needs to respond to gradients; other models run as on the flat.
+type GradientSensitive interface {
+	// AtGradient returns the model evaluated on the given gradient
+	// (per mille, positive = rising in the direction of travel).
+	AtGradient(gradient float64) MotionModel
+}
+
+// AtGradient returns m evaluated on the given gradient. Models that do not implement
+// GradientSensitive are returned unchanged.
+func AtGradient(m MotionModel, gradient float64) MotionModel {
+	if gs, ok := m.(GradientSensitive); ok {
+		return gs.AtGradient(gradient)
+	}
+	return m
+}
+
+// LoadSensitive is implemented by models whose performance depends on the load carried.
+type LoadSensitive interface {
+	// WithLoad returns the model for a vehicle of emptyMass (kg) carrying loadMass (kg).
+	WithLoad(emptyMass, loadMass float64) MotionModel
+}
+
+// WithLoad returns m for a vehicle of emptyMass carrying loadMass. Models that do not
+// implement LoadSensitive, and empty or massless vehicles, are returned unchanged.
+func WithLoad(m MotionModel, emptyMass, loadMass float64) MotionModel {
+	if ls, ok := m.(LoadSensitive); ok && emptyMass > 0 && loadMass > 0 {
+		return ls.WithLoad(emptyMass, loadMass)
+	}
+	return m
+}
+
+// AdhesionLimited is implemented by models whose braking is limited by wheel–rail adhesion.
+type AdhesionLimited interface {
+	// WithAdhesion returns the model with braking scaled by the adhesion factor (0, 1].
+	WithAdhesion(factor float64) MotionModel
+}
+
+// WithAdhesion returns m with braking scaled by the adhesion factor. Models that do not
+// implement AdhesionLimited, and full adhesion (factor 1), return m unchanged.
+func WithAdhesion(m MotionModel, factor float64) MotionModel {
+	if al, ok := m.(AdhesionLimited); ok && factor != 1 {
+		return al.WithAdhesion(factor)
+	}
+	return m
+}
+
+// SpeedScaler is implemented by models with speed parameters, so that they can be read
+// in units other than m/s.
+type SpeedScaler interface {
+	// ScaleSpeeds returns the model with its speed parameters multiplied by factor.
+	ScaleSpeeds(factor float64) MotionModel
+}
+
+// ScaleSpeeds returns m with its speed parameters multiplied by factor. Models that do
+// not implement SpeedScaler, and a factor of 1, return m unchanged.
+func ScaleSpeeds(m MotionModel, factor float64) MotionModel {
+	if ss, ok := m.(SpeedScaler); ok && factor != 1 {
+		return ss.ScaleSpeeds(factor)
+	}
+	return m
+}
+
+// Coupler is implemented by models that can describe a consist of coupled units.
+type Coupler interface {
+	// Couple returns the model for this unit, of the given mass (kg), coupled to other, a
+	// unit of the same model of otherMass kg. Each unit's traction and braking act on the
+	// whole consist, so rates are averaged weighted by mass; the lower speed limit applies.
+	Couple(other MotionModel, mass, otherMass float64) (MotionModel, error)
+}
+
+// Couple returns the model for a unit of model m and the given mass (kg) coupled to a
+// unit of model other and otherMass kg. Both must be the same model, one that implements
+// Coupler.
+func Couple(m MotionModel, mass float64, other MotionModel, otherMass float64) (MotionModel, error) {
+	c, ok := m.(Coupler)
+	if !ok {
+		return nil, fmt.Errorf("%T units cannot be coupled", m)
+	}
+	if mass+otherMass <= 0 {
+		return nil, fmt.Errorf("coupled units need a positive total mass")
+	}
+	return c.Couple(other, mass, otherMass)
+}
+
+// mismatchError reports an attempt to couple units of different models.
+func mismatchError(m, other MotionModel) error {
+	return fmt.Errorf("cannot couple %T and %T units", m, other)
+}
+
+// mix returns the average of a and b weighted f : 1-f.
+func mix(a, b, f float64) float64 { return f*a + (1-f)*b }
+
+// Coaster is implemented by models that can coast: run with neither traction nor
+// braking applied, so that running resistance alone slows the vehicle.
+type Coaster interface {
+	// CoastStep advances the vehicle over dt seconds with no traction or braking.
+	// Returns (distance travelled, new velocity).
+	CoastStep(v, dt float64) (dist, newV float64)
+}
+
+// State is per-vehicle scratch state carried between timesteps for models whose motion
+// depends on more than the current velocity.
+type State struct {
+	Acceleration float64 `json:"acceleration"` // m/s², negative when braking
+}
+
+// Stateful is implemented by models that carry State between timesteps.
+type Stateful interface {
+	// WithState returns the model bound to s; steps taken on the bound model read and
+	// update s.
+	WithState(s *State) MotionModel
+}
+
+// WithState returns m bound to s. Models that do not implement Stateful are returned
+// unchanged.
+func WithState(m MotionModel, s *State) MotionModel {
+	if st, ok := m.(Stateful); ok {
+		return st.WithState(s)
+	}
+	return m
+}
+
+// kineticEnergyDelta returns the change in kinetic energy (J) of mass kg going from v0 to v1.
+func kineticEnergyDelta(v0, v1, mass float64) float64 {
+	return 0.5 * mass * (v1*v1 - v0*v0)
 }
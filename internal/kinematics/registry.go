@@ -0,0 +1,51 @@
+package kinematics
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cxd309/tms-engine/internal/registry"
+)
+
+// Factory builds a model from its JSON parameters: the kinematics object of a vehicle,
+// including its "model" discriminator.
+type Factory = registry.Factory[MotionModel]
+
+// Named is implemented by models that can name their JSON discriminator, so that they
+// can be written back out as JSON. All the built-in models implement it.
+type Named = registry.Named
+
+var models = registry.New[MotionModel]("kinematics")
+
+// Register makes a model available under the JSON discriminator name. The built-in
+// models register themselves; others may be registered from an init function before any
+// input is read. Register panics if name is already registered or factory is nil.
+func Register(name string, factory Factory) {
+	models.Register(name, factory)
+}
+
+// Lookup returns the factory registered under name, if any.
+func Lookup(name string) (Factory, bool) {
+	return models.Lookup(name)
+}
+
+// parse is a Factory for models read straight from their JSON parameters, checked if the
+// model is a registry.Validator.
+func parse[M MotionModel](raw json.RawMessage) (MotionModel, error) {
+	return registry.Parse[MotionModel, M](raw)
+}
+
+// checkRates checks the parameters every built-in model has: the traction and braking
+// rates and maximum speed must all be positive, lest a service never be able to move or
+// stop.
+func checkRates(aAcc, aDcc, vMax float64) error {
+	switch {
+	case aAcc <= 0:
+		return fmt.Errorf("a_acc %v must be positive", aAcc)
+	case aDcc <= 0:
+		return fmt.Errorf("a_dcc %v must be positive", aDcc)
+	case vMax <= 0:
+		return fmt.Errorf("v_max %v must be positive", vMax)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package registry holds the models a package makes available under the "model"
+// discriminator of their JSON objects, such as the kinematics and dwell models.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Factory builds a model of type T from its JSON parameters, including its "model"
+// discriminator.
+type Factory[T any] func(json.RawMessage) (T, error)
+
+// Named is implemented by models that can name their JSON discriminator, so that they
+// can be written back out as JSON.
+type Named interface {
+	ModelName() string
+}
+
+// Validator is implemented by models that can check their parameters.
+type Validator interface {
+	Validate() error
+}
+
+// Registry maps JSON discriminators to the factories for models of type T. It is safe
+// for concurrent use.
+type Registry[T any] struct {
+	pkg       string
+	mu        sync.RWMutex
+	factories map[string]Factory[T]
+}
+
+// New returns an empty registry for the models of package pkg, which names it in its
+// panics.
+func New[T any](pkg string) *Registry[T] {
+	return &Registry[T]{pkg: pkg, factories: make(map[string]Factory[T])}
+}
+
+// Register makes a model available under the JSON discriminator name. Register panics if
+// name is already registered or factory is nil.
+func (r *Registry[T]) Register(name string, factory Factory[T]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if factory == nil {
+		panic(fmt.Sprintf("%s: Register of model %q with nil factory", r.pkg, name))
+	}
+	if _, dup := r.factories[name]; dup {
+		panic(fmt.Sprintf("%s: Register called twice for model %q", r.pkg, name))
+	}
+	r.factories[name] = factory
+}
+
+// Lookup returns the factory registered under name, if any.
+func (r *Registry[T]) Lookup(name string) (Factory[T], bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	factory, ok := r.factories[name]
+	return factory, ok
+}
+
+// Parse is a Factory for models of type M read straight from their JSON parameters,
+// checked if M is a Validator, and returned as a T. M must implement T.
+func Parse[T, M any](raw json.RawMessage) (T, error) {
+	var m M
+	if err := json.Unmarshal(raw, &m); err != nil {
+		var zero T
+		return zero, err
+	}
+	if v, ok := any(m).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			var zero T
+			return zero, err
+		}
+	}
+	return any(m).(T), nil
+}
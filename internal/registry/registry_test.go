@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type model interface{ Speed() float64 }
+
+type fixed struct {
+	V float64 `json:"v"`
+}
+
+func (f fixed) Speed() float64 { return f.V }
+
+func (f fixed) Validate() error {
+	if f.V <= 0 {
+		return errors.New("v must be positive")
+	}
+	return nil
+}
+
+// TestRegistry checks that a registered factory is found under its name, parses and
+// validates its parameters, and that a name cannot be registered twice.
+func TestRegistry(t *testing.T) {
+	r := New[model]("test")
+	r.Register("fixed", Parse[model, fixed])
+	if _, ok := r.Lookup("other"); ok {
+		t.Error("found a factory under an unregistered name")
+	}
+	factory, ok := r.Lookup("fixed")
+	if !ok {
+		t.Fatal("no factory under its registered name")
+	}
+	m, err := factory(json.RawMessage(`{"model": "fixed", "v": 3}`))
+	if err != nil || m.Speed() != 3 {
+		t.Errorf("parsed %v, %v; want speed 3", m, err)
+	}
+	if _, err := factory(json.RawMessage(`{"model": "fixed", "v": 0}`)); err == nil {
+		t.Error("invalid parameters: no error")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice: no panic")
+		}
+	}()
+	r.Register("fixed", Parse[model, fixed])
+}
@@ -3,9 +3,13 @@
 package service
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"math"
+	"slices"
 
+	"github.com/cxd309/tms-engine/internal/dwell"
 	"github.com/cxd309/tms-engine/internal/graph"
 	"github.com/cxd309/tms-engine/internal/kinematics"
 )
@@ -22,22 +26,191 @@ const (
 	StateAccelerating ServiceState = "accelerating"
 	StateDecelerating ServiceState = "decelerating"
 	StateCruising     ServiceState = "cruising"
+	// StateCoasting runs with neither traction nor braking, letting running resistance
+	// slow the service.
+	StateCoasting ServiceState = "coasting"
+	// StateHeld is brought to a stand, away from a stop, by a hold on the service, until
+	// the hold ends or it is released.
+	StateHeld ServiceState = "held"
+	// StateFinished is terminal: the service has reached the last stop of a route that
+	// does not loop and takes no further part in the simulation.
+	StateFinished ServiceState = "finished"
 )
 
-// RouteStop is a node on a service's route with a required dwell time.
+// RouteStop is a node on a service's route. MinDwell is the time physically needed at
+// the stop (e.g. for boarding). ScheduledArrival and ScheduledDeparture optionally tie
+// the stop to a timetable, in simulation seconds: a service dwells for at least MinDwell
+// and never departs before its scheduled departure, so any timetabled dwell beyond
+// MinDwell is recovery time a late service can claw back. Lateness against either time
+// is reported as its delay. On a looping route they apply to the first pass only.
+//
+// Boarders and Alighters optionally give the passenger demand at the stop. They change
+// the load on board, and with a DoorFlowRate the dwell lengthens to the time needed to
+// exchange them if that is longer than MinDwell.
+//
+// A service runs through a stop without calling there if Skip is set, as on an express
+// pattern, or if it is a RequestStop with nobody to board or alight.
+//
+// Couple, Join and Divide arrange portion working, on the first pass only. Couple names
+// a service that couples on here and ends, this one continuing as the joined train; Join
+// names the service this one joins here, ending itself. Each needs the other on the
+// partner's route: whichever of the two arrives first waits for the other, which draws
+// up behind it. Divide splits the service here (see Division).
+//
+// Reverse makes the service change direction on arriving at the stop, as a shuttle does
+// at its termini, so that it sets back the way it came: for shunting, or a locomotive
+// running round its train. The track under the service must be bidirectional.
+//
+// SetBack makes the service, once its dwell at the stop is over, set back that many
+// metres along the track it came by without turning round, then stand before leaving for
+// its next stop: to draw clear of points, say, when shunting. It cannot set back further
+// than its body and the track it last ran over reach.
+//
+// DwellModel, if set, times the call in place of MinDwell and the passenger exchange
+// (see dwell.DwellModel), overriding any the service has.
+//
+// Via and Track pin the way the service runs to the stop, from wherever it last called,
+// in place of the shortest path: it passes through the Via nodes in order, taking the
+// shortest path between them, and wherever it runs between the ends of a Track edge it
+// takes that edge over any parallel to it. They have no effect on a stop not called at.
 type RouteStop struct {
-	NodeID graph.NodeID `json:"node_id"`
-	TDwell float64      `json:"t_dwell"` // seconds
+	NodeID             graph.NodeID   `json:"node_id"`
+	MinDwell           float64        `json:"min_dwell"`                     // seconds
+	ScheduledArrival   *float64       `json:"scheduled_arrival,omitempty"`   // seconds
+	ScheduledDeparture *float64       `json:"scheduled_departure,omitempty"` // seconds
+	Boarders           int            `json:"boarders,omitempty"`
+	Alighters          int            `json:"alighters,omitempty"`
+	DoorFlowRate       float64        `json:"door_flow_rate,omitempty"` // passengers per second
+	Skip               bool           `json:"skip,omitempty"`
+	RequestStop        bool           `json:"request_stop,omitempty"`
+	Couple             ServiceID      `json:"couple,omitempty"`
+	Join               ServiceID      `json:"join,omitempty"`
+	Divide             *Division      `json:"divide,omitempty"`
+	Reverse            bool           `json:"reverse,omitempty"`
+	SetBack            float64        `json:"set_back,omitempty"` // metres
+	DwellModel         *dwell.Spec    `json:"dwell_model,omitempty"`
+	Via                []graph.NodeID `json:"via,omitempty"`
+	Track              []graph.EdgeID `json:"track,omitempty"`
+}
+
+// Division splits a service at a stop: its rearmost Units units are detached as a new
+// service, which waits for the stop's MinDwell and then runs Route, or terminates there
+// if Route is empty. It inherits the service's priority.
+type Division struct {
+	ServiceID ServiceID   `json:"service_id"`
+	Units     int         `json:"units"`
+	Route     []RouteStop `json:"route,omitempty"`
+}
+
+// Calls reports whether a service stops here rather than running through.
+func (r RouteStop) Calls() bool {
+	return !r.Skip && (!r.RequestStop || r.Boarders > 0 || r.Alighters > 0)
+}
+
+// UnmarshalJSON accepts the legacy "t_dwell" field as an alias for "min_dwell".
+func (r *RouteStop) UnmarshalJSON(data []byte) error {
+	type plain RouteStop
+	var raw struct {
+		plain
+		TDwell *float64 `json:"t_dwell"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = RouteStop(raw.plain)
+	if raw.TDwell != nil && r.MinDwell == 0 {
+		r.MinDwell = *raw.TDwell
+	}
+	return nil
 }
 
 // Vehicle holds the static parameters of a vehicle type.
 // The physics of acceleration and braking are encapsulated by the Kinem field;
 // adding a new model only requires implementing kinematics.MotionModel and registering
-// it in UnmarshalJSON below — no engine code changes needed.
+// it with kinematics.Register — no service or engine code changes needed.
 type Vehicle struct {
-	Name   string            `json:"name"`
-	Length float64           `json:"length"` // vehicle length, metres
-	Kinem  kinematics.MotionModel `json:"-"` // set by UnmarshalJSON
+	Name   string  `json:"name"`
+	Length float64 `json:"length"` // vehicle length, metres
+	// MassEmpty and MassPerPassenger are optional; they drive energy accounting and,
+	// for load-sensitive kinematics models, performance under load.
+	MassEmpty        float64 `json:"mass_empty"`         // kg
+	MassPerPassenger float64 `json:"mass_per_passenger"` // kg
+	// ReactionTime is how long the driver takes to start braking once it is needed.
+	ReactionTime float64 `json:"reaction_time,omitempty"` // seconds
+	// CoastSpeed and ResumeSpeed set an energy-saving driving style for kinematics models
+	// that can coast: traction is cut on reaching CoastSpeed (or the line speed, if lower)
+	// and reapplied once the speed falls to ResumeSpeed. Zero CoastSpeed = never coast.
+	CoastSpeed  float64 `json:"coast_speed,omitempty"`  // m/s
+	ResumeSpeed float64 `json:"resume_speed,omitempty"` // m/s
+	// CreepSpeed and CreepDistance set how a service draws into a stop: it slows to
+	// CreepSpeed by CreepDistance short of the stop, and creeps the rest of the way before
+	// braking to a stand. Zero CreepSpeed = brake straight to a stand.
+	CreepSpeed    float64                `json:"creep_speed,omitempty"`    // m/s
+	CreepDistance float64                `json:"creep_distance,omitempty"` // metres
+	Kinem         kinematics.MotionModel `json:"-"`                        // set by UnmarshalJSON
+	// Ref is the name of a vehicle in the input's vehicle library, when the vehicle is
+	// given in JSON as that name alone; it is resolved before the run, and the other
+	// fields are then left unset.
+	Ref string `json:"-"`
+}
+
+// Mass returns the vehicle's total mass (kg) carrying the given number of passengers.
+func (v Vehicle) Mass(passengers int) float64 {
+	return v.MassEmpty + float64(passengers)*v.MassPerPassenger
+}
+
+// ScaleSpeeds returns a copy of the vehicle with its speeds, including those of its
+// kinematics model, multiplied by factor.
+func (v Vehicle) ScaleSpeeds(factor float64) Vehicle {
+	if v.Kinem != nil {
+		v.Kinem = kinematics.ScaleSpeeds(v.Kinem, factor)
+	}
+	v.CoastSpeed *= factor
+	v.ResumeSpeed *= factor
+	v.CreepSpeed *= factor
+	return v
+}
+
+// UnitRef is a number of identical units of one vehicle type, coupled in a consist.
+type UnitRef struct {
+	Unit  Vehicle `json:"unit"`
+	Count int     `json:"count,omitempty"` // default 1
+}
+
+// Consist returns the vehicle formed by coupling units in order. Lengths and masses add
+// up, and the units' kinematics, which must all be of one model, are coupled weighted by
+// mass (see kinematics.Coupler); every unit type must then set its mass_empty. The
+// leading unit's driving settings apply, and its name is given to the consist.
+func Consist(units []UnitRef) (Vehicle, error) {
+	if len(units) == 0 {
+		return Vehicle{}, fmt.Errorf("consist has no units")
+	}
+	consist := units[0].Unit
+	consist.Length, consist.MassEmpty, consist.MassPerPassenger = 0, 0, 0
+	total := 0
+	for i, ref := range units {
+		n := cmp.Or(ref.Count, 1)
+		if n < 0 {
+			return Vehicle{}, fmt.Errorf("unit %q: count %d must be positive", ref.Unit.Name, ref.Count)
+		}
+		if i > 0 {
+			if ref.Unit.MassEmpty <= 0 || consist.MassEmpty <= 0 {
+				return Vehicle{}, fmt.Errorf("unit %q: mass_empty must be set to couple units of different types", ref.Unit.Name)
+			}
+			k, err := kinematics.Couple(consist.Kinem, consist.MassEmpty, ref.Unit.Kinem, float64(n)*ref.Unit.MassEmpty)
+			if err != nil {
+				return Vehicle{}, fmt.Errorf("unit %q: %w", ref.Unit.Name, err)
+			}
+			consist.Kinem = k
+		}
+		consist.Length += float64(n) * ref.Unit.Length
+		consist.MassEmpty += float64(n) * ref.Unit.MassEmpty
+		consist.MassPerPassenger += float64(n) * ref.Unit.MassPerPassenger
+		total += n
+	}
+	// Passengers spread over the whole consist, so each adds the units' average mass.
+	consist.MassPerPassenger /= float64(total)
+	return consist, nil
 }
 
 // kinematicsDisc is the minimum JSON structure needed to read the model discriminator.
@@ -47,25 +220,62 @@ type kinematicsDisc struct {
 
 // vehicleJSON is the raw JSON shape of a Vehicle, before the kinematics model is resolved.
 type vehicleJSON struct {
-	Name   string          `json:"name"`
-	Length float64         `json:"length"`
-	Kinem  json.RawMessage `json:"kinematics"`
+	Name             string          `json:"name"`
+	Length           float64         `json:"length"`
+	MassEmpty        float64         `json:"mass_empty"`
+	MassPerPassenger float64         `json:"mass_per_passenger"`
+	ReactionTime     float64         `json:"reaction_time"`
+	CoastSpeed       float64         `json:"coast_speed"`
+	ResumeSpeed      float64         `json:"resume_speed"`
+	CreepSpeed       float64         `json:"creep_speed,omitempty"`
+	CreepDistance    float64         `json:"creep_distance,omitempty"`
+	Kinem            json.RawMessage `json:"kinematics"`
 }
 
 // UnmarshalJSON implements json.Unmarshaler for Vehicle.
 // The "kinematics" field must contain a "model" discriminator key that selects
-// the concrete implementation; the rest of the kinematics object is forwarded to
-// that implementation's own unmarshaler.
+// the concrete implementation from those registered with kinematics.Register; the
+// kinematics object is passed to that model's factory.
 //
-// Supported models:
+// Built-in models:
 //   - "constant": fixed a_acc / a_dcc rates.
+//   - "gradient": fixed flat-track a_acc / a_dcc rates adjusted for edge gradient.
+//   - "davis": fixed a_acc / a_dcc rates opposed by Davis running resistance.
+//   - "jerk": a_acc / a_dcc rates ramped at jerk limits j_acc / j_dcc.
+//
+// A JSON string in place of the object is a reference to a vehicle library entry of that
+// name, and sets Ref alone.
 func (v *Vehicle) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*v = Vehicle{}
+		return json.Unmarshal(data, &v.Ref)
+	}
 	var aux vehicleJSON
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
 	}
 	v.Name = aux.Name
 	v.Length = aux.Length
+	v.MassEmpty = aux.MassEmpty
+	v.MassPerPassenger = aux.MassPerPassenger
+	v.ReactionTime = aux.ReactionTime
+	v.CoastSpeed = aux.CoastSpeed
+	v.ResumeSpeed = aux.ResumeSpeed
+	v.CreepSpeed = aux.CreepSpeed
+	v.CreepDistance = aux.CreepDistance
+
+	if v.ReactionTime < 0 {
+		return fmt.Errorf("vehicle %q: reaction_time %v must not be negative", v.Name, v.ReactionTime)
+	}
+	if v.CoastSpeed > 0 && (v.ResumeSpeed < 0 || v.ResumeSpeed >= v.CoastSpeed) {
+		return fmt.Errorf("vehicle %q: resume_speed %v must be in [0, coast_speed)", v.Name, v.ResumeSpeed)
+	}
+	if v.CreepSpeed < 0 {
+		return fmt.Errorf("vehicle %q: creep_speed %v must not be negative", v.Name, v.CreepSpeed)
+	}
+	if v.CreepSpeed > 0 && v.CreepDistance <= 0 {
+		return fmt.Errorf("vehicle %q: creep_distance %v must be positive with creep_speed", v.Name, v.CreepDistance)
+	}
 
 	if len(aux.Kinem) == 0 {
 		return fmt.Errorf("vehicle %q: missing \"kinematics\" field", v.Name)
@@ -75,30 +285,108 @@ func (v *Vehicle) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(aux.Kinem, &disc); err != nil {
 		return fmt.Errorf("vehicle %q: reading kinematics model discriminator: %w", v.Name, err)
 	}
-
-	switch disc.Model {
-	case kinematics.ConstantModelName:
-		var k kinematics.ConstantAcceleration
-		if err := json.Unmarshal(aux.Kinem, &k); err != nil {
-			return fmt.Errorf("vehicle %q: parsing constant kinematics: %w", v.Name, err)
-		}
-		v.Kinem = k
-	default:
+	factory, ok := kinematics.Lookup(disc.Model)
+	if !ok {
 		return fmt.Errorf("vehicle %q: unknown kinematics model %q", v.Name, disc.Model)
 	}
+	k, err := factory(aux.Kinem)
+	if err != nil {
+		return fmt.Errorf("vehicle %q: parsing %s kinematics: %w", v.Name, disc.Model, err)
+	}
+	v.Kinem = k
 	return nil
 }
 
+// MarshalJSON implements json.Marshaler for Vehicle, writing the kinematics model with
+// its "model" discriminator so that the output reads back through UnmarshalJSON. The
+// model must implement kinematics.Named. An unresolved reference is written as its name.
+func (v Vehicle) MarshalJSON() ([]byte, error) {
+	if v.Kinem == nil && v.Ref != "" {
+		return json.Marshal(v.Ref)
+	}
+	named, ok := v.Kinem.(kinematics.Named)
+	if !ok {
+		return nil, fmt.Errorf("vehicle %q: cannot marshal kinematics model %T", v.Name, v.Kinem)
+	}
+	params, err := json.Marshal(v.Kinem)
+	if err != nil {
+		return nil, fmt.Errorf("vehicle %q: marshalling kinematics: %w", v.Name, err)
+	}
+	var kinem map[string]json.RawMessage
+	if err := json.Unmarshal(params, &kinem); err != nil {
+		return nil, fmt.Errorf("vehicle %q: marshalling kinematics: %w", v.Name, err)
+	}
+	kinem["model"], _ = json.Marshal(named.ModelName())
+	raw, err := json.Marshal(kinem)
+	if err != nil {
+		return nil, fmt.Errorf("vehicle %q: marshalling kinematics: %w", v.Name, err)
+	}
+	return json.Marshal(vehicleJSON{
+		Name:             v.Name,
+		Length:           v.Length,
+		MassEmpty:        v.MassEmpty,
+		MassPerPassenger: v.MassPerPassenger,
+		ReactionTime:     v.ReactionTime,
+		CoastSpeed:       v.CoastSpeed,
+		ResumeSpeed:      v.ResumeSpeed,
+		CreepSpeed:       v.CreepSpeed,
+		CreepDistance:    v.CreepDistance,
+		Kinem:            raw,
+	})
+}
+
 // Service is the static definition of a scheduled service.
 type Service struct {
 	ServiceID       ServiceID    `json:"service_id"`
 	InitialPosition graph.NodeID `json:"initial_position"`
-	Route           []RouteStop  `json:"route"`
-	Vehicle         Vehicle      `json:"vehicle"`
+	// StartPosition, if set, places the service partway along an edge at the start of
+	// the run rather than at InitialPosition, which must then be the edge's end node, or
+	// be left empty to take it. The service heads for that node first, and has not
+	// served a stop there.
+	StartPosition *graph.Position `json:"start_position,omitempty"`
+	Route         []RouteStop     `json:"route"`
+	// Vehicle is the service's vehicle; alternatively Units lists the units coupled to
+	// form it (see Consist).
+	Vehicle Vehicle   `json:"vehicle"`
+	Units   []UnitRef `json:"units,omitempty"`
 	// DepartureDelay is the number of simulation-seconds the service waits
 	// stationary before beginning to move. Use this to model staggered timetabled
 	// departures (e.g. service B departs 120 s after service A). Zero = immediate.
 	DepartureDelay float64 `json:"departure_delay,omitempty"` // seconds
+	// InitialPassengers is the number of passengers on board at the start of the run.
+	InitialPassengers int `json:"initial_passengers,omitempty"`
+	// Priority decides which of two conflicting services goes first: higher wins, and
+	// equal priorities fall back to ServiceID order. Zero by default.
+	Priority int `json:"priority,omitempty"`
+	// Loop makes the route repeat indefinitely, returning from the last stop to the
+	// first. Shuttle instead makes the service reverse at each end of the route and serve
+	// its stops in the opposite order, which needs bidirectional track at both termini.
+	// With neither, the service finishes on arrival at its last stop.
+	Loop    bool `json:"loop,omitempty"`
+	Shuttle bool `json:"shuttle,omitempty"`
+	// MaxSpeed caps the service's speed below its vehicle's v_max and the line speed, as
+	// for a degraded unit or on a driver's instruction; zero = no cap. A portion divided
+	// off the service is not capped.
+	MaxSpeed float64 `json:"max_speed,omitempty"` // m/s
+	// DwellModel, if set, times the service's calls at stops that do not set their own.
+	DwellModel *dwell.Spec `json:"dwell_model,omitempty"`
+	// InitialVelocity and InitialState, if set, start the service already on the move,
+	// as when it enters the modelled area at the start of the run. InitialState is
+	// cruising by default and may be any moving state, or StateStationary at zero
+	// InitialVelocity; of the moving states, only accelerating may start from rest. A
+	// service on the move has already departed, so its DepartureDelay is ignored.
+	InitialVelocity float64      `json:"initial_velocity,omitempty"` // m/s
+	InitialState    ServiceState `json:"initial_state,omitempty"`
+}
+
+// StopEvent records a service's call at a stop: when it actually arrived and departed,
+// and, on the first pass of its route, when it was timetabled to.
+type StopEvent struct {
+	NodeID             graph.NodeID `json:"node_id"`
+	Arrival            *float64     `json:"arrival,omitempty"`             // seconds; nil at the service's origin
+	Departure          *float64     `json:"departure,omitempty"`           // seconds; nil if it has not departed
+	ScheduledArrival   *float64     `json:"scheduled_arrival,omitempty"`   // seconds
+	ScheduledDeparture *float64     `json:"scheduled_departure,omitempty"` // seconds
 }
 
 // SimService is a Service enriched with live simulation state.
@@ -109,83 +397,660 @@ type SimService struct {
 	Velocity        float64        `json:"velocity"`        // m/s
 	RemainingDwell  float64        `json:"remaining_dwell"` // seconds
 	NextStop        graph.NodeID   `json:"next_stop"`
-	nextStopIndex   int
+	TractionEnergy  float64        `json:"traction_energy"` // cumulative, J
+	RegenEnergy     float64        `json:"regen_energy"`    // cumulative recoverable braking energy, J
+	// Odometer is the distance the service's front has travelled since the start of the
+	// run, however its route loops or reverses; a divided-off portion starts with the
+	// reading of the train it came from.
+	Odometer   float64 `json:"odometer"`   // metres
+	Passengers int     `json:"passengers"` // currently on board
+	// Delay is the lateness (negative if early) at the most recent timetabled arrival
+	// or departure; nil until the service has passed one.
+	Delay *float64 `json:"delay,omitempty"` // seconds
+	// Reversed is set while a shuttle service is serving its route backwards.
+	Reversed bool `json:"reversed,omitempty"`
+	// SettingBack is the distance the service has still to set back from the stop it
+	// last called at (see RouteStop.SetBack), or 0 if it is not setting back.
+	SettingBack float64 `json:"setting_back,omitempty"` // metres
+	// ArrivalTime is the exact simulation time the service arrived at a stop within the
+	// latest timestep, or nil if it did not. The engine clears it at each timestep.
+	ArrivalTime *float64 `json:"arrival_time,omitempty"` // seconds
+	// Arrivals records the service's calls at stops so far, in order.
+	Arrivals []StopEvent `json:"arrivals,omitempty"`
+	// Trail lists the edges most recently left, oldest first, as far back as needed to
+	// trace the track the service still protects behind its front.
+	Trail []graph.EdgeID `json:"trail,omitempty"`
+	// KinemState is scratch state for kinematics models that carry it between timesteps.
+	KinemState    kinematics.State `json:"kinematics_state"`
+	nextStopIndex int
+	viaPassed     int        // Via nodes of the next stop passed so far
+	lap           int        // completed passes of the route, in either direction
+	minDwellLeft  float64    // seconds of MinDwell still to serve at the current stop
+	departureDue  *float64   // scheduled departure from the stop being dwelt at, if any
+	heldUntil     float64    // simulation time before which the service may not move off
+	holding       bool       // whether the service is held until released
+	reacted       float64    // seconds the driver has spent reacting to the current need to brake
+	awaiting      ServiceID  // partner to couple with before leaving the current stop
+	joining       bool       // whether the service ends by joining awaiting
+	dividing      *RouteStop // stop just arrived at, if a division is due there
+	turning       bool       // whether the service reverses at the stop just arrived at
+	// dwellJitter, if set, perturbs each dwell the service needs at a call (see
+	// SetDwellJitter).
+	dwellJitter func(float64) float64
 }
 
 // GetFirstStop returns the first target stop node ID and its index in svc.Route.
-// If the service starts at the first route stop, the second stop is returned instead.
+// Leading stops at the service's initial position are already served, unless it starts
+// partway along an edge, and stops the service does not call at are passed through, so
+// the first called stop elsewhere is returned instead.
 func GetFirstStop(svc Service) (graph.NodeID, int, error) {
 	if len(svc.Route) == 0 {
 		return "", 0, fmt.Errorf("service %q has no route stops", svc.ServiceID)
 	}
-	if svc.InitialPosition == svc.Route[0].NodeID {
-		if len(svc.Route) < 2 {
-			return "", 0, fmt.Errorf("service %q: initial position is the only stop", svc.ServiceID)
+	for i, stop := range svc.Route {
+		if stop.Calls() && (stop.NodeID != svc.InitialPosition || svc.StartPosition != nil) {
+			return stop.NodeID, i, nil
 		}
-		return svc.Route[1].NodeID, 1, nil
 	}
-	return svc.Route[0].NodeID, 0, nil
+	return "", 0, fmt.Errorf("service %q: initial position is the only stop called at", svc.ServiceID)
+}
+
+// Waypoints returns the nodes the service visits in order: its initial position, then
+// every stop it calls at from its first stop to the last, each after its Via nodes. A
+// looping route wraps round until the first stop is reached again; a shuttle route runs
+// back to the first stop and out again as far as its first stop. Either must call at
+// least two different nodes.
+func (svc Service) Waypoints() ([]graph.NodeID, error) {
+	_, first, err := GetFirstStop(svc)
+	if err != nil {
+		return nil, err
+	}
+	if svc.Loop && svc.Shuttle {
+		return nil, fmt.Errorf("service %q: route cannot both loop and shuttle", svc.ServiceID)
+	}
+	var stops []RouteStop // called stops in route order
+	for i, stop := range svc.Route {
+		if i == first {
+			first = len(stops)
+		}
+		if stop.Calls() {
+			stops = append(stops, stop)
+		}
+	}
+	if (svc.Loop || svc.Shuttle) && !slices.ContainsFunc(stops, func(s RouteStop) bool { return s.NodeID != stops[first].NodeID }) {
+		return nil, fmt.Errorf("service %q: repeating route must call at least two different nodes", svc.ServiceID)
+	}
+	waypoints := []graph.NodeID{svc.InitialPosition}
+	visit := func(stops ...RouteStop) {
+		for _, stop := range stops {
+			waypoints = append(append(waypoints, stop.Via...), stop.NodeID)
+		}
+	}
+	visit(stops[first:]...)
+	switch {
+	case svc.Loop:
+		visit(stops[:first+1]...)
+	case svc.Shuttle:
+		for i := len(stops) - 2; i >= 0; i-- {
+			visit(stops[i])
+		}
+		visit(stops[1 : first+1]...)
+	}
+	return waypoints, nil
+}
+
+// LongestSetBack returns the furthest the service sets back at any stop on its route
+// (see RouteStop.SetBack).
+func (svc Service) LongestSetBack() float64 {
+	longest := 0.0
+	for _, stop := range svc.Route {
+		longest = math.Max(longest, stop.SetBack)
+	}
+	return longest
+}
+
+// Portions returns the services divided off this one along its route, each starting at
+// the stop where it divides.
+func (svc Service) Portions() []Service {
+	var portions []Service
+	for _, stop := range svc.Route {
+		if d := stop.Divide; d != nil {
+			portions = append(portions, Service{
+				ServiceID:       d.ServiceID,
+				InitialPosition: stop.NodeID,
+				Route:           d.Route,
+				Priority:        svc.Priority,
+			})
+		}
+	}
+	return portions
+}
+
+// startsAt reports whether the service starts the run standing at node n.
+func (svc Service) startsAt(n graph.NodeID) bool {
+	return svc.StartPosition == nil && svc.InitialPosition == n
+}
+
+// UnitList returns the units the service's vehicle is formed from: its Units, or else
+// its Vehicle as a single unit.
+func (svc Service) UnitList() []UnitRef {
+	if len(svc.Units) > 0 {
+		return svc.Units
+	}
+	return []UnitRef{{Unit: svc.Vehicle, Count: 1}}
 }
 
 // NewSimService creates a SimService from a static Service definition and a pre-computed
-// initial graph position.
+// initial graph position, which the service's StartPosition, if set, overrides.
 func NewSimService(svc Service, initialPos graph.Position) (*SimService, error) {
 	nextStop, nextStopIdx, err := GetFirstStop(svc)
 	if err != nil {
 		return nil, err
 	}
-	return &SimService{
+	switch {
+	case len(svc.Units) > 0 && svc.Vehicle.Kinem != nil:
+		return nil, fmt.Errorf("give either a vehicle or units, not both")
+	case len(svc.Units) > 0:
+		if svc.Vehicle, err = Consist(svc.Units); err != nil {
+			return nil, err
+		}
+	case svc.Vehicle.Kinem == nil && svc.Vehicle.Ref != "":
+		return nil, fmt.Errorf("vehicle %q is not in the vehicle library", svc.Vehicle.Ref)
+	case svc.Vehicle.Kinem == nil:
+		return nil, fmt.Errorf("no vehicle")
+	}
+	if svc.MaxSpeed < 0 {
+		return nil, fmt.Errorf("max_speed %v must not be negative", svc.MaxSpeed)
+	}
+	for _, stop := range svc.Route {
+		if stop.SetBack < 0 {
+			return nil, fmt.Errorf("stop %q: set_back %v must not be negative", stop.NodeID, stop.SetBack)
+		}
+	}
+	if svc.StartPosition != nil {
+		initialPos = *svc.StartPosition
+	}
+	s := &SimService{
 		Service:         svc,
 		CurrentPosition: initialPos,
 		State:           StateStationary,
 		Velocity:        0,
 		RemainingDwell:  0,
 		NextStop:        nextStop,
+		Passengers:      svc.InitialPassengers,
 		nextStopIndex:   nextStopIdx,
-	}, nil
+	}
+	if err := s.startMoving(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// startMoving puts s into its InitialState at its InitialVelocity, if either is set.
+func (s *SimService) startMoving() error {
+	v, state := s.InitialVelocity, s.InitialState
+	if state == "" {
+		if v == 0 {
+			return nil
+		}
+		state = StateCruising
+	}
+	switch {
+	case v < 0:
+		return fmt.Errorf("initial_velocity %v must not be negative", v)
+	case v > s.TopSpeed():
+		return fmt.Errorf("initial_velocity %v exceeds the service's top speed %v", v, s.TopSpeed())
+	}
+	switch state {
+	case StateStationary:
+		if v > 0 {
+			return fmt.Errorf("initial_state %q needs a zero initial_velocity", state)
+		}
+		return nil
+	case StateAccelerating:
+	case StateCruising, StateCoasting, StateDecelerating:
+		if v == 0 {
+			return fmt.Errorf("initial_state %q needs a positive initial_velocity", state)
+		}
+	default:
+		return fmt.Errorf("initial_state %q is not a moving state", state)
+	}
+	s.State, s.Velocity = state, v
+	return nil
+}
+
+// Kinematics returns the service's motion model bound to its kinematics state and
+// adjusted for its current passenger load.
+func (s *SimService) Kinematics() kinematics.MotionModel {
+	m := kinematics.WithState(s.Vehicle.Kinem, &s.KinemState)
+	return kinematics.WithLoad(m, s.Vehicle.MassEmpty, s.Mass()-s.Vehicle.MassEmpty)
+}
+
+// Mass returns the service's current total mass (kg), including passengers.
+func (s *SimService) Mass() float64 {
+	return s.Vehicle.Mass(s.Passengers)
+}
+
+// TopSpeed returns the fastest the service may run anywhere: its vehicle's VMax, or its
+// MaxSpeed if that is lower.
+func (s *SimService) TopSpeed() float64 {
+	if s.MaxSpeed > 0 {
+		return math.Min(s.Vehicle.Kinem.VMax(), s.MaxSpeed)
+	}
+	return s.Vehicle.Kinem.VMax()
 }
 
 // BrakingDistance returns the minimum stopping distance from the service's current velocity.
 func (s *SimService) BrakingDistance() float64 {
-	return s.Vehicle.Kinem.BrakingDistance(s.Velocity)
+	return s.Kinematics().BrakingDistance(s.Velocity)
+}
+
+// ReactionDistance returns the distance the service covers at its current velocity
+// while its driver reacts.
+func (s *SimService) ReactionDistance() float64 {
+	return s.Velocity * s.Vehicle.ReactionTime
 }
 
-// AdvanceDwell decrements the remaining dwell time by dt seconds.
+// Reacting reports whether the driver has yet to start braking, braking being needed
+// this timestep of dt seconds. The driver first reacts for the vehicle's ReactionTime,
+// rounded up to whole timesteps; a service already braking needs no reaction, and once
+// braking is no longer needed the next need starts a new one.
+func (s *SimService) Reacting(braking bool, dt float64) bool {
+	if !braking {
+		s.reacted = 0
+		return false
+	}
+	if s.State == StateDecelerating || s.reacted >= s.Vehicle.ReactionTime {
+		return false
+	}
+	s.reacted += dt
+	return true
+}
+
+// AddEnergy accumulates an energy delta (J) from a movement: positive deltas count as
+// traction energy, negative deltas as regenerated energy.
+func (s *SimService) AddEnergy(delta float64) {
+	if delta > 0 {
+		s.TractionEnergy += delta
+	} else {
+		s.RegenEnergy -= delta
+	}
+}
+
+// DepartureDue returns the simulation time from which a stationary service may move off:
+// after its DepartureDelay and any hold and, if it starts at its first route stop, that
+// stop's scheduled departure.
+func (s *SimService) DepartureDue() float64 {
+	due := max(s.DepartureDelay, s.heldUntil)
+	if origin := s.Route[0]; s.startsAt(origin.NodeID) && origin.ScheduledDeparture != nil {
+		due = max(due, *origin.ScheduledDeparture)
+	}
+	return due
+}
+
+// Depart sets a stationary service moving if it is due by simulation time now (see
+// DepartureDue) and not held until released, taking it to leave as soon as it was due.
+// It reports whether the service departed.
+func (s *SimService) Depart(now float64) bool {
+	due := s.DepartureDue()
+	if now < due || s.holding {
+		return false
+	}
+	if origin := s.Route[0]; s.startsAt(origin.NodeID) {
+		if origin.ScheduledDeparture != nil {
+			s.setDelay(due - *origin.ScheduledDeparture)
+		}
+		s.Arrivals = append(s.Arrivals, StopEvent{
+			NodeID:             origin.NodeID,
+			Departure:          &due,
+			ScheduledDeparture: origin.ScheduledDeparture,
+		})
+	}
+	s.State = StateAccelerating
+	return true
+}
+
+// AdvanceDwell serves dt seconds of dwell, ending it at simulation time now once the
+// minimum dwell has been served, any scheduled departure has come and any partner due to
+// couple at the stop has done so.
 // If the service is not yet dwelling it is transitioned into the dwelling state first.
-func (s *SimService) AdvanceDwell(dt float64) {
+func (s *SimService) AdvanceDwell(now, dt float64) {
 	if s.State != StateDwelling {
-		s.startDwell()
+		s.startDwell(now)
+	}
+	s.minDwellLeft -= dt
+	s.RemainingDwell = s.dwellLeft(now)
+	if s.RemainingDwell <= 0 && s.awaiting == "" && !s.holding {
+		s.endDwell(now)
+	}
+}
+
+// dwellLeft returns the seconds from now until the service may depart: the longest of the
+// minimum dwell still to serve, the wait for its scheduled departure and any hold.
+func (s *SimService) dwellLeft(now float64) float64 {
+	left := max(s.minDwellLeft, s.heldUntil-now)
+	if s.departureDue != nil {
+		left = max(left, *s.departureDue-now)
+	}
+	return left
+}
+
+// Hold keeps the service from moving off before simulation time until, whether it is
+// waiting to start or dwelling. A moving service is brought to a stand by the engine.
+func (s *SimService) Hold(until float64) { s.heldUntil = max(s.heldUntil, until) }
+
+// HoldUntilReleased holds the service, as Hold does, until Release is called.
+func (s *SimService) HoldUntilReleased() { s.holding = true }
+
+// Release ends every hold on the service.
+func (s *SimService) Release() {
+	s.holding = false
+	s.heldUntil = 0
+}
+
+// HeldAt reports whether the service is held at simulation time now.
+func (s *SimService) HeldAt(now float64) bool { return s.holding || now < s.heldUntil }
+
+// ArriveAtStop transitions the service into the dwelling state upon reaching a stop at
+// simulation time at, within the timestep ending at now, or into the finished state if
+// the stop ends a non-looping route. The time from at to now counts towards the dwell.
+func (s *SimService) ArriveAtStop(at, now float64) {
+	s.ArrivalTime = &at
+	stop := s.Route[s.nextStopIndex]
+	event := StopEvent{NodeID: stop.NodeID, Arrival: &at}
+	if s.lap == 0 {
+		event.ScheduledArrival, event.ScheduledDeparture = stop.ScheduledArrival, stop.ScheduledDeparture
+		if stop.ScheduledArrival != nil {
+			s.setDelay(at - *stop.ScheduledArrival)
+		}
+	}
+	s.Arrivals = append(s.Arrivals, event)
+	if s.finalStop() {
+		s.finish()
+		return
+	}
+	s.startDwell(now)
+	s.minDwellLeft -= now - at
+	s.RemainingDwell = s.dwellLeft(now)
+}
+
+// finalStop reports whether the stop being arrived at ends the route: the route neither
+// loops nor shuttles, it calls at no later stop at a different node, and the service is
+// not to join another there.
+func (s *SimService) finalStop() bool {
+	if s.Loop || s.Shuttle || (s.lap == 0 && s.Route[s.nextStopIndex].Join != "") {
+		return false
+	}
+	at := s.Route[s.nextStopIndex].NodeID
+	for _, stop := range s.Route[s.nextStopIndex+1:] {
+		if stop.Calls() && stop.NodeID != at {
+			return false
+		}
+	}
+	return true
+}
+
+// Finished reports whether the service has finished its route.
+func (s *SimService) Finished() bool { return s.State == StateFinished }
+
+// AwaitingPartner returns the service s is waiting at its stop to couple with, and
+// whether s ends by joining it; "" if none.
+func (s *SimService) AwaitingPartner() (ServiceID, bool) { return s.awaiting, s.joining }
+
+// NextPartner returns the service s is to couple with at its next stop; "" if none.
+func (s *SimService) NextPartner() ServiceID {
+	if s.Finished() || s.lap > 0 {
+		return ""
+	}
+	stop := s.Route[s.nextStopIndex]
+	return cmp.Or(stop.Couple, stop.Join)
+}
+
+// Absorb couples other to s, forming one train of the given units: s continues with
+// other's passengers on board, and other finishes.
+func (s *SimService) Absorb(other *SimService, units []UnitRef) error {
+	vehicle, err := Consist(units)
+	if err != nil {
+		return err
+	}
+	s.Vehicle, s.Units = vehicle, units
+	s.Passengers += other.Passengers
+	s.KinemState = kinematics.State{}
+	s.awaiting, s.joining = "", false
+	other.Passengers = 0
+	other.finish()
+	return nil
+}
+
+// SetDwellJitter makes the service pass the time it needs at each later call with a
+// non-zero dwell through jitter, as for dwell times drawn at random about their nominal
+// values; nil sets dwells back to their nominal values.
+func (s *SimService) SetDwellJitter(jitter func(nominal float64) float64) {
+	s.dwellJitter = jitter
+}
+
+// Turning reports whether s is to reverse at the stop it has just arrived at (see
+// RouteStop.Reverse) and has yet to.
+func (s *SimService) Turning() bool { return s.turning }
+
+// TurnedRound records that s has reversed at the stop it has just arrived at.
+func (s *SimService) TurnedRound() { s.turning = false }
+
+// Divide carries out any division due at the stop s has just arrived at at simulation
+// time now. It returns the detached rear portion, placed nowhere yet, or nil if no
+// division is due. Passengers are shared in proportion to length.
+func (s *SimService) Divide(now float64) (*SimService, error) {
+	if s.dividing == nil {
+		return nil, nil
+	}
+	stop, d := *s.dividing, s.dividing.Divide
+	s.dividing = nil
+	var flat []Vehicle
+	for _, ref := range s.UnitList() {
+		for range cmp.Or(ref.Count, 1) {
+			flat = append(flat, ref.Unit)
+		}
+	}
+	if d.Units <= 0 || d.Units >= len(flat) {
+		return nil, fmt.Errorf("dividing off %d of %d units", d.Units, len(flat))
+	}
+	cut := len(flat) - d.Units
+	frontUnits, rearUnits := unitRefs(flat[:cut]), unitRefs(flat[cut:])
+	front, err := Consist(frontUnits)
+	if err != nil {
+		return nil, err
+	}
+	rearVehicle, err := Consist(rearUnits)
+	if err != nil {
+		return nil, err
+	}
+	rearPassengers := int(math.Round(float64(s.Passengers) * rearVehicle.Length / (front.Length + rearVehicle.Length)))
+	rear := &SimService{
+		Service: Service{
+			ServiceID:       d.ServiceID,
+			InitialPosition: stop.NodeID,
+			Route:           d.Route,
+			Vehicle:         rearVehicle,
+			Units:           rearUnits,
+			DepartureDelay:  now + stop.MinDwell,
+			Priority:        s.Priority,
+		},
+		State:      StateStationary,
+		Odometer:   s.Odometer,
+		Passengers: rearPassengers,
 	}
-	s.RemainingDwell -= dt
-	if s.RemainingDwell <= 0 {
-		s.endDwell()
+	s.Vehicle, s.Units = front, frontUnits
+	s.Passengers -= rearPassengers
+	s.KinemState = kinematics.State{}
+	if len(d.Route) == 0 {
+		rear.finish()
+		return rear, nil
+	}
+	if rear.NextStop, rear.nextStopIndex, err = GetFirstStop(rear.Service); err != nil {
+		return nil, err
 	}
+	return rear, nil
 }
 
-// ArriveAtStop transitions the service into the dwelling state upon reaching a stop.
-func (s *SimService) ArriveAtStop() {
-	s.startDwell()
+// unitRefs groups consecutive identical units into UnitRefs.
+func unitRefs(units []Vehicle) []UnitRef {
+	var refs []UnitRef
+	for _, u := range units {
+		if n := len(refs); n > 0 && refs[n-1].Unit == u {
+			refs[n-1].Count++
+			continue
+		}
+		refs = append(refs, UnitRef{Unit: u, Count: 1})
+	}
+	return refs
 }
 
-func (s *SimService) startDwell() {
+func (s *SimService) finish() {
+	s.State = StateFinished
+	s.Velocity = 0
+	s.KinemState = kinematics.State{}
+	s.RemainingDwell = 0
+	s.NextStop = ""
+}
+
+func (s *SimService) startDwell(now float64) {
+	stop := s.Route[s.nextStopIndex]
 	s.State = StateDwelling
 	s.Velocity = 0
-	s.RemainingDwell = s.Route[s.nextStopIndex].TDwell
+	s.KinemState = kinematics.State{}
+	s.minDwellLeft = s.dwellTime(stop, now)
+	s.departureDue = nil
+	s.dividing = nil
+	if s.lap == 0 {
+		s.departureDue = stop.ScheduledDeparture
+		s.awaiting, s.joining = cmp.Or(stop.Couple, stop.Join), stop.Join != ""
+		if stop.Divide != nil {
+			s.dividing = &stop
+		}
+	}
+	s.RemainingDwell = s.dwellLeft(now)
+	s.turning = stop.Reverse
+	s.SettingBack = stop.SetBack
 	s.advanceNextStop()
 }
 
-func (s *SimService) endDwell() {
+// dwellTime exchanges the passengers at stop and returns how long the call, starting at
+// now, takes: as timed by the stop's dwell model, or else the service's, or with neither
+// the stop's MinDwell lengthened to the time the passenger exchange takes; then jittered
+// if the service has a dwell jitter.
+func (s *SimService) dwellTime(stop RouteStop, now float64) float64 {
+	ctx := dwell.Context{
+		Time:         now,
+		MinDwell:     stop.MinDwell,
+		Boarders:     stop.Boarders,
+		Alighters:    min(stop.Alighters, s.Passengers),
+		DoorFlowRate: stop.DoorFlowRate,
+	}
+	if s.lap == 0 {
+		ctx.ScheduledArrival, ctx.ScheduledDeparture = stop.ScheduledArrival, stop.ScheduledDeparture
+	}
+	exchange := s.exchangePassengers(stop)
+	ctx.OnBoard = s.Passengers
+	t := max(stop.MinDwell, exchange)
+	if m := cmp.Or(stop.DwellModel, s.DwellModel); m != nil {
+		t = m.DwellTime(ctx)
+	}
+	if s.dwellJitter != nil && t > 0 {
+		t = s.dwellJitter(t)
+	}
+	return t
+}
+
+// exchangePassengers lets the stop's alighters off, as many as are on board, and its
+// boarders on. It returns the seconds the exchange takes at the stop's door flow rate, or
+// zero if the stop has none.
+func (s *SimService) exchangePassengers(stop RouteStop) float64 {
+	alighting := min(stop.Alighters, s.Passengers)
+	s.Passengers += stop.Boarders - alighting
+	if stop.DoorFlowRate <= 0 {
+		return 0
+	}
+	return float64(alighting+stop.Boarders) / stop.DoorFlowRate
+}
+
+func (s *SimService) endDwell(now float64) {
 	s.State = StateAccelerating
 	s.Velocity = 0
 	s.RemainingDwell = 0
+	s.minDwellLeft = 0
+	if s.departureDue != nil {
+		s.setDelay(now - *s.departureDue)
+		s.departureDue = nil
+	}
+	if n := len(s.Arrivals); n > 0 && s.Arrivals[n-1].Departure == nil {
+		s.Arrivals[n-1].Departure = &now
+	}
 }
 
+func (s *SimService) setDelay(d float64) { s.Delay = &d }
+
+// advanceNextStop moves on to the next stop, wrapping round a looping route or turning
+// back at either end of a shuttle route. Stops the service does not call at are passed
+// over, as are stops at the node just served (such as a circular route's closing stop
+// repeating its first): the service is already there.
 func (s *SimService) advanceNextStop() {
-	s.nextStopIndex = (s.nextStopIndex + 1) % len(s.Route)
+	at := s.Route[s.nextStopIndex].NodeID
+	for stop := s.Route[s.nextStopIndex]; stop.NodeID == at || !stop.Calls(); stop = s.Route[s.nextStopIndex] {
+		s.nextStopIndex = s.stopAfter(s.nextStopIndex)
+	}
 	s.NextStop = s.Route[s.nextStopIndex].NodeID
+	s.viaPassed = 0
+}
+
+// PendingVia returns the Via nodes of the next stop the service has yet to pass, in
+// order.
+func (s *SimService) PendingVia() []graph.NodeID {
+	if s.nextStopIndex >= len(s.Route) {
+		return nil
+	}
+	via := s.Route[s.nextStopIndex].Via
+	return via[min(s.viaPassed, len(via)):]
+}
+
+// PassNode notes the service's front passing node n on its way to its next stop.
+func (s *SimService) PassNode(n graph.NodeID) {
+	if via := s.PendingVia(); len(via) > 0 && via[0] == n {
+		s.viaPassed++
+	}
+}
+
+// Track returns the edges the service is to take, over any parallel to them, on its way
+// to its next stop.
+func (s *SimService) Track() []graph.EdgeID {
+	if s.nextStopIndex >= len(s.Route) {
+		return nil
+	}
+	return s.Route[s.nextStopIndex].Track
 }
 
-// ServiceLog is a point-in-time snapshot of a SimService's state.
+// stopAfter returns the index of the stop after stop i in the direction of travel,
+// reversing the service's direction at the end of a shuttle route.
+func (s *SimService) stopAfter(i int) int {
+	step := 1
+	if s.Reversed {
+		step = -1
+	}
+	next := i + step
+	switch {
+	case next >= 0 && next < len(s.Route):
+		return next
+	case s.Shuttle:
+		s.Reversed = !s.Reversed
+		s.lap++
+		return i - step
+	default:
+		s.lap++
+		return 0
+	}
+}
+
+// ServiceLog is a point-in-time snapshot of a SimService's state. Its Velocity is
+// negative while the service is setting back.
 type ServiceLog struct {
 	ServiceID       ServiceID      `json:"service_id"`
 	CurrentPosition graph.Position `json:"current_position"`
@@ -193,16 +1058,97 @@ type ServiceLog struct {
 	Velocity        float64        `json:"velocity"`
 	RemainingDwell  float64        `json:"remaining_dwell"`
 	NextStop        graph.NodeID   `json:"next_stop"`
+	TractionEnergy  float64        `json:"traction_energy"` // cumulative, J
+	RegenEnergy     float64        `json:"regen_energy"`    // cumulative, J
+	Odometer        float64        `json:"odometer"`        // cumulative distance travelled, metres
+	Passengers      int            `json:"passengers"`
+	Delay           *float64       `json:"delay,omitempty"` // seconds late at the last timetabled stop
+	Reversed        bool           `json:"reversed,omitempty"`
+	SettingBack     float64        `json:"setting_back,omitempty"` // metres still to set back
+	ArrivalTime     *float64       `json:"arrival_time,omitempty"` // seconds, exact
+	// Coordinate is the absolute position of the service's front, if the engine was asked
+	// to log it.
+	Coordinate *graph.Coordinate `json:"coordinate,omitempty"`
 }
 
 // GetLog returns a point-in-time snapshot of the service state.
 func (s *SimService) GetLog() ServiceLog {
-	return ServiceLog{
+	l := ServiceLog{
 		ServiceID:       s.ServiceID,
 		CurrentPosition: s.CurrentPosition,
 		State:           s.State,
 		Velocity:        s.Velocity,
 		RemainingDwell:  s.RemainingDwell,
 		NextStop:        s.NextStop,
+		TractionEnergy:  s.TractionEnergy,
+		RegenEnergy:     s.RegenEnergy,
+		Odometer:        s.Odometer,
+		Passengers:      s.Passengers,
+		Delay:           s.Delay,
+		Reversed:        s.Reversed,
+		SettingBack:     s.SettingBack,
+		ArrivalTime:     s.ArrivalTime,
+	}
+	if s.SettingBack > 0 && s.Velocity > 0 {
+		l.Velocity = -s.Velocity
 	}
+	return l
+}
+
+// Snapshot is the complete state of a SimService, including the progress through its
+// route that the engine otherwise keeps private, in a form that survives a round trip
+// through JSON.
+type Snapshot struct {
+	SimService
+	NextStopIndex int        `json:"next_stop_index"`
+	ViaPassed     int        `json:"via_passed,omitempty"`
+	Lap           int        `json:"lap,omitempty"`
+	MinDwellLeft  float64    `json:"min_dwell_left,omitempty"`
+	DepartureDue  *float64   `json:"departure_due,omitempty"`
+	HeldUntil     float64    `json:"held_until,omitempty"`
+	Holding       bool       `json:"holding,omitempty"`
+	Reacted       float64    `json:"reacted,omitempty"`
+	Awaiting      ServiceID  `json:"awaiting,omitempty"`
+	Joining       bool       `json:"joining,omitempty"`
+	Dividing      *RouteStop `json:"dividing,omitempty"`
+}
+
+// Snapshot returns the service's complete current state.
+func (s *SimService) Snapshot() Snapshot {
+	snap := Snapshot{
+		SimService:    *s,
+		NextStopIndex: s.nextStopIndex,
+		ViaPassed:     s.viaPassed,
+		Lap:           s.lap,
+		MinDwellLeft:  s.minDwellLeft,
+		DepartureDue:  s.departureDue,
+		HeldUntil:     s.heldUntil,
+		Holding:       s.holding,
+		Reacted:       s.reacted,
+		Awaiting:      s.awaiting,
+		Joining:       s.joining,
+		Dividing:      s.dividing,
+	}
+	snap.Trail = slices.Clone(s.Trail)
+	snap.Arrivals = slices.Clone(s.Arrivals)
+	return snap
+}
+
+// Restore returns a SimService in the state captured by snap.
+func Restore(snap Snapshot) *SimService {
+	s := snap.SimService
+	s.Trail = slices.Clone(snap.Trail)
+	s.Arrivals = slices.Clone(snap.Arrivals)
+	s.nextStopIndex = snap.NextStopIndex
+	s.viaPassed = snap.ViaPassed
+	s.lap = snap.Lap
+	s.minDwellLeft = snap.MinDwellLeft
+	s.departureDue = snap.DepartureDue
+	s.heldUntil = snap.HeldUntil
+	s.holding = snap.Holding
+	s.reacted = snap.Reacted
+	s.awaiting = snap.Awaiting
+	s.joining = snap.Joining
+	s.dividing = snap.Dividing
+	return &s
 }